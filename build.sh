@@ -2,3 +2,4 @@
 go mod download
 go build -o ./bin/migrate ./cmd/migrate/
 go build -o ./bin/api ./cmd/api/
+go build -o ./bin/rotate-keys ./cmd/rotate-keys/
@@ -41,8 +41,53 @@ var (
 	// Solana
 	solanaRPCURL          = env.MustString("SOLANA_RPC_URL")
 	walletSecretSalt      = env.MustString("WALLET_SECRET_SALT")
+	walletSecretSaltPrev  = env.GetString("WALLET_SECRET_SALT_PREVIOUS", "")     // comma separated salts used before WALLET_SECRET_SALT, read-only
+	walletRequireAD       = env.GetBool("WALLET_REQUIRE_ASSOCIATED_DATA", false) // reject the mnemonics not bound to the wallet, see cmd/rotate-keys
+	walletKDFTime         = env.GetInt("WALLET_KDF_TIME", 3)
+	walletKDFMemory       = env.GetInt("WALLET_KDF_MEMORY", 64*1024) // KiB
+	walletKDFThreads      = env.GetInt("WALLET_KDF_THREADS", 2)
+	walletPINFreeAttempts = env.GetInt("WALLET_PIN_FREE_ATTEMPTS", 3)
+	walletPINMaxAttempts  = env.GetInt("WALLET_PIN_MAX_ATTEMPTS", 10)
+	walletPINBaseDelay    = env.GetDuration("WALLET_PIN_BASE_DELAY", 30*time.Second)
+	walletPINMaxDelay     = env.GetDuration("WALLET_PIN_MAX_DELAY", time.Hour)
+	walletKEK             = env.GetString("WALLET_KEK", "")      // comma separated "<key id>:<base64 key>" pairs, the first one is current
+	walletKEKFile         = env.GetString("WALLET_KEK_FILE", "") // JSON file with the key encryption keys, takes precedence over WALLET_KEK
 	tokenMetadataCacheTTL = env.GetDuration("TOKEN_METADATA_CACHE_TTL", time.Hour)
 
+	// Keystore imports, each one may take up to 256 MiB for the scrypt key derivation
+	walletMaxKeystoreImports = env.GetInt("WALLET_MAX_KEYSTORE_IMPORTS", 4)
+
+	// Wallet deletion
+	walletDeletionGracePeriod = env.GetDuration("WALLET_DELETION_GRACE_PERIOD", 7*24*time.Hour) // deleted wallets can be restored within this period
+	walletPurgeInterval       = env.GetDuration("WALLET_PURGE_INTERVAL", time.Hour)
+
+	// Transaction simulation
+	walletSimulateBeforeSend = env.GetBool("WALLET_SIMULATE_BEFORE_SEND", true) // callers can override it per request
+
+	// Priority fees of the transactions built by the server
+	walletPriorityFeePercentile = env.GetInt("WALLET_PRIORITY_FEE_PERCENTILE", 75)       // percentile of the recent prioritization fees used in the auto mode
+	walletMaxComputeUnitPrice   = env.GetInt("WALLET_MAX_COMPUTE_UNIT_PRICE", 5_000_000) // micro-lamports per compute unit
+
+	// Sent transactions confirmation watcher
+	walletTxWatchInterval  = env.GetDuration("WALLET_TX_WATCH_INTERVAL", 5*time.Second)
+	walletTxWatchBatchSize = env.GetInt("WALLET_TX_WATCH_BATCH_SIZE", 100) // max 256
+
+	// Wallet signing policy defaults, the amounts are in base units (lamports for SOL)
+	walletPolicyAllowedPrograms    = env.GetStrings("WALLET_POLICY_ALLOWED_PROGRAMS", ",", nil)             // empty allows any program, required if any limit is set
+	walletPolicyDeniedDestinations = env.GetStrings("WALLET_POLICY_DENIED_DESTINATIONS", ",", nil)          // wallet or token account addresses
+	walletPolicyMaxSOLPerTx        = env.GetInt("WALLET_POLICY_MAX_SOL_PER_TX", 0)                          // 0 means no limit
+	walletPolicyDailySOLLimit      = env.GetInt("WALLET_POLICY_DAILY_SOL_LIMIT", 0)                         // 0 means no limit
+	walletPolicyMaxTokenPerTx      = env.GetIntsMap[int]("WALLET_POLICY_MAX_TOKEN_PER_TX", ",", ":", nil)   // "<mint>:<amount>" pairs
+	walletPolicyDailyTokenLimits   = env.GetIntsMap[int]("WALLET_POLICY_DAILY_TOKEN_LIMITS", ",", ":", nil) // "<mint>:<amount>" pairs
+
+	// Webhooks
+	webhookDispatchInterval = env.GetDuration("WEBHOOK_DISPATCH_INTERVAL", 5*time.Second)
+	webhookBatchSize        = env.GetInt("WEBHOOK_BATCH_SIZE", 50)
+	webhookTimeout          = env.GetDuration("WEBHOOK_TIMEOUT", 10*time.Second)
+	webhookRetryBaseDelay   = env.GetDuration("WEBHOOK_RETRY_BASE_DELAY", 30*time.Second)
+	webhookRetryMaxDelay    = env.GetDuration("WEBHOOK_RETRY_MAX_DELAY", 6*time.Hour)
+	webhookMaxAttempts      = env.GetInt("WEBHOOK_MAX_ATTEMPTS", 10) // then the delivery goes to the dead-letter state
+
 	// OAuth2
 	oauth2IntrospectURL = env.MustString("OAUTH2_INTROSPECT_URL")
 )
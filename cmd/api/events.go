@@ -0,0 +1,17 @@
+package main
+
+import (
+	"github.com/dmitrymomot/solana-wallets/internal/events"
+	"github.com/sirupsen/logrus"
+)
+
+// logEvent returns the events listener which writes every event to the debug log
+func logEvent(log *logrus.Entry) events.Listener {
+	return func(name events.EventName, payload interface{}) error {
+		log.WithFields(logrus.Fields{
+			"event":   name,
+			"payload": payload,
+		}).Debug("Event emitted")
+		return nil
+	}
+}
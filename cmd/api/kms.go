@@ -0,0 +1,19 @@
+package main
+
+import (
+	"github.com/dmitrymomot/solana-wallets/internal/kms"
+	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+)
+
+// initKeyProvider returns the key encryption key provider configured with
+// the WALLET_KEK_FILE or WALLET_KEK environment variables.
+// Returns nil if neither is set, so the envelope encryption is disabled.
+func initKeyProvider() (solanawallet.KeyProvider, error) {
+	switch {
+	case walletKEKFile != "":
+		return kms.NewLocalProviderFromFile(walletKEKFile)
+	case walletKEK != "":
+		return kms.NewLocalProviderFromString(walletKEK)
+	}
+	return nil, nil
+}
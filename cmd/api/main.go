@@ -2,16 +2,20 @@ package main
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/dmitrymomot/oauth2-server/lib/client"
 	"github.com/dmitrymomot/oauth2-server/lib/middleware"
+	"github.com/dmitrymomot/solana-wallets/internal/events"
 	"github.com/dmitrymomot/solana-wallets/internal/kitlog"
 	"github.com/dmitrymomot/solana-wallets/internal/solanacache"
 	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
 	"github.com/dmitrymomot/solana-wallets/svc/balance"
 	"github.com/dmitrymomot/solana-wallets/svc/wallet"
 	wallet_repository "github.com/dmitrymomot/solana-wallets/svc/wallet/repository"
+	"github.com/dmitrymomot/solana-wallets/svc/webhook"
+	webhook_repository "github.com/dmitrymomot/solana-wallets/svc/webhook/repository"
 	solanaClient "github.com/dmitrymomot/solana/client"
 	"github.com/go-redis/cache/v8"
 	"github.com/go-redis/redis/v8"
@@ -54,41 +58,126 @@ func main() {
 	// Init Solana client
 	solClient := solanaClient.New(solanaClient.SetSolanaEndpoint(solanaRPCURL))
 
+	// Init Solana client wrapper which caches token metadata and mint info
+	redisOpt, err := redis.ParseURL(redisConnURL)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to parse redis connection url")
+	}
+	cacheClient := cache.New(&cache.Options{
+		Redis:      redis.NewClient(redisOpt),
+		LocalCache: cache.NewTinyLFU(1000, time.Minute),
+	})
+	solClientWithCache := solanacache.NewSolanaClientCacheWrapper(
+		solClient,
+		solanacache.WithCacheTTL(tokenMetadataCacheTTL),
+		solanacache.WithCacheClient(cacheClient),
+	)
+
+	// Init domain events emitter
+	eventsLogger := logger.WithField("component", "events")
+	emitter := events.NewEmitter(eventsLogger)
+	emitter.ListenEvents(logEvent(eventsLogger), append(events.WalletEvents, events.BalanceEvents...)...)
+
 	// Init wallet service
 	{
 		repo, err := wallet_repository.Prepare(ctx, db)
 		if err != nil {
 			logger.WithError(err).Fatal("Failed to prepare wallet repository")
 		}
+		walletOpts := []solanawallet.Option{
+			solanawallet.WithKDFParams(solanawallet.KDFParams{
+				Time:    uint32(walletKDFTime),
+				Memory:  uint32(walletKDFMemory),
+				Threads: uint8(walletKDFThreads),
+			}),
+		}
+		if walletSecretSaltPrev != "" {
+			walletOpts = append(walletOpts, solanawallet.WithPreviousSalts(strings.Split(walletSecretSaltPrev, ",")...))
+		}
+		if walletRequireAD {
+			walletOpts = append(walletOpts, solanawallet.WithRequireAssociatedData())
+		}
+		keyProvider, err := initKeyProvider()
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to init key encryption key provider")
+		}
+		if keyProvider != nil {
+			walletOpts = append(walletOpts, solanawallet.WithKeyProvider(keyProvider))
+		}
+		signingPolicy, err := initDefaultSigningPolicy()
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to init default wallet signing policy")
+		}
+
+		walletLogger := kitlog.NewLogger(logger.WithField("component", "wallet-service"))
+		walletSvc := wallet.NewService(
+			repo,
+			solanawallet.NewClient(walletSecretSalt, walletOpts...),
+			solClientWithCache,
+			emitter,
+			wallet.WithPINLockoutPolicy(wallet.PINLockoutPolicy{
+				FreeAttempts: walletPINFreeAttempts,
+				BaseDelay:    walletPINBaseDelay,
+				MaxDelay:     walletPINMaxDelay,
+				MaxAttempts:  walletPINMaxAttempts,
+			}),
+			wallet.WithDeletionGracePeriod(walletDeletionGracePeriod),
+			wallet.WithDefaultSigningPolicy(signingPolicy),
+			wallet.WithSimulateBeforeSend(walletSimulateBeforeSend),
+			wallet.WithTransactionWatchBatchSize(walletTxWatchBatchSize),
+			wallet.WithPriorityFeeEstimate(walletPriorityFeePercentile, uint64(walletMaxComputeUnitPrice)),
+			wallet.WithMaxConcurrentKeystoreImports(walletMaxKeystoreImports),
+			wallet.WithDB(db),
+			wallet.WithLogger(walletLogger),
+		)
+
 		r.Mount("/wallet", wallet.MakeHTTPHandler(
-			wallet.MakeEndpoints(wallet.NewService(
-				repo,
-				solanawallet.NewClient(walletSecretSalt),
-				solClient,
-			), oauth2Mdw),
-			kitlog.NewLogger(logger.WithField("component", "wallet-service")),
+			wallet.MakeEndpoints(walletSvc, oauth2Mdw),
+			walletLogger,
 		))
+
+		// Run deleted wallets purge job
+		eg.Go(runWalletPurge(ctx, walletPurgeInterval, walletSvc, logger.WithField("component", "wallet-purge")))
+
+		// Run sent transactions confirmation watcher
+		eg.Go(runTransactionWatcher(ctx, walletTxWatchInterval, walletSvc, logger.WithField("component", "transaction-watcher")))
 	}
 
-	// Init balance service
+	// Init webhook service
 	{
-		redisOpt, err := redis.ParseURL(redisConnURL)
+		repo, err := webhook_repository.Prepare(ctx, db)
 		if err != nil {
-			logger.WithError(err).Fatal("Failed to parse redis connection url")
+			logger.WithError(err).Fatal("Failed to prepare webhook repository")
 		}
-		cacheClient := cache.New(&cache.Options{
-			Redis:      redis.NewClient(redisOpt),
-			LocalCache: cache.NewTinyLFU(1000, time.Minute),
-		})
-		solClientWithCache := solanacache.NewSolanaClientCacheWrapper(
-			solClient,
-			solanacache.WithCacheTTL(tokenMetadataCacheTTL),
-			solanacache.WithCacheClient(cacheClient),
+
+		webhookSvc := webhook.NewService(
+			repo,
+			webhook.WithRetryPolicy(webhook.RetryPolicy{
+				BaseDelay:   webhookRetryBaseDelay,
+				MaxDelay:    webhookRetryMaxDelay,
+				MaxAttempts: webhookMaxAttempts,
+			}),
+			webhook.WithBatchSize(webhookBatchSize),
+			webhook.WithTimeout(webhookTimeout),
 		)
 
+		// Put wallet events to the webhooks outbox within the wallet changes transactions
+		emitter.ListenEventsTx(webhookSvc.EnqueueEvent, events.WalletEvents...)
+
+		r.Mount("/webhooks", webhook.MakeHTTPHandler(
+			webhook.MakeEndpoints(webhookSvc, oauth2Mdw),
+			kitlog.NewLogger(logger.WithField("component", "webhook-service")),
+		))
+
+		// Run webhooks dispatcher
+		eg.Go(runWebhookDispatcher(ctx, webhookDispatchInterval, webhookSvc, logger.WithField("component", "webhook-dispatcher")))
+	}
+
+	// Init balance service
+	{
 		r.Mount("/balance", balance.MakeHTTPHandler(
 			balance.MakeEndpoints(
-				balance.NewService(solClientWithCache),
+				balance.NewService(solClientWithCache, emitter),
 				oauth2Mdw,
 			),
 			kitlog.NewLogger(logger.WithField("component", "balance-service")),
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Run background job which calls fn right away and then on every interval tick until the context is done.
+// A failed run is logged and must not shut the server down, the next one will retry.
+func runPeriodic(ctx context.Context, interval time.Duration, name string, log *logrus.Entry, fn func(ctx context.Context, log *logrus.Entry) error) func() error {
+	return func() error {
+		log = log.WithField("interval", interval.String())
+		log.Infof("Starting %s", name)
+		defer func() { log.Infof("Stopped %s", name) }()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := fn(ctx, log); err != nil {
+				log.WithError(err).Errorf("Failed to run %s", name)
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/dmitrymomot/solana-wallets/internal/signingpolicy"
+)
+
+// initDefaultSigningPolicy returns the default wallet signing policy
+// configured with the WALLET_POLICY_* environment variables.
+func initDefaultSigningPolicy() (signingpolicy.Policy, error) {
+	p := signingpolicy.Policy{
+		AllowedPrograms:    walletPolicyAllowedPrograms,
+		DeniedDestinations: walletPolicyDeniedDestinations,
+		MaxTokenPerTx:      toLimits(walletPolicyMaxTokenPerTx),
+		DailyTokenLimits:   toLimits(walletPolicyDailyTokenLimits),
+	}
+	if walletPolicyMaxSOLPerTx > 0 {
+		v := uint64(walletPolicyMaxSOLPerTx)
+		p.MaxSOLPerTx = &v
+	}
+	if walletPolicyDailySOLLimit > 0 {
+		v := uint64(walletPolicyDailySOLLimit)
+		p.DailySOLLimit = &v
+	}
+
+	if err := p.Validate(); err != nil {
+		return signingpolicy.Policy{}, err
+	}
+	if err := p.ValidateEffective(); err != nil {
+		return signingpolicy.Policy{}, err
+	}
+
+	return p, nil
+}
+
+// toLimits converts the "<mint>:<amount>" config map to the policy limits, skipping non-positive amounts
+func toLimits(m map[string]int) map[string]uint64 {
+	if len(m) == 0 {
+		return nil
+	}
+	limits := make(map[string]uint64, len(m))
+	for mint, amount := range m {
+		if amount > 0 {
+			limits[mint] = uint64(amount)
+		}
+	}
+	return limits
+}
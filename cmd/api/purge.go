@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type walletPurger interface {
+	PurgeDeletedWallets(ctx context.Context) (int64, error)
+}
+
+// Run background job which permanently deletes the wallets whose recovery window is over
+func runWalletPurge(ctx context.Context, interval time.Duration, svc walletPurger, log *logrus.Entry) func() error {
+	return runPeriodic(ctx, interval, "deleted wallets purge job", log, func(ctx context.Context, log *logrus.Entry) error {
+		purged, err := svc.PurgeDeletedWallets(ctx)
+		if err != nil {
+			return err
+		}
+		if purged > 0 {
+			log.WithField("purged", purged).Info("Purged deleted wallets")
+		}
+		return nil
+	})
+}
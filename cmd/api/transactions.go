@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type transactionWatcher interface {
+	UpdateTransactionStatuses(ctx context.Context) (int, error)
+}
+
+// Run background job which tracks the confirmation statuses of the sent wallet transactions
+func runTransactionWatcher(ctx context.Context, interval time.Duration, svc transactionWatcher, log *logrus.Entry) func() error {
+	return runPeriodic(ctx, interval, "transaction confirmation watcher", log, func(ctx context.Context, log *logrus.Entry) error {
+		updated, err := svc.UpdateTransactionStatuses(ctx)
+		if err != nil {
+			return err
+		}
+		if updated > 0 {
+			log.WithField("updated", updated).Debug("Updated transaction statuses")
+		}
+		return nil
+	})
+}
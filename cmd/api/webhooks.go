@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type webhookDispatcher interface {
+	DispatchDueDeliveries(ctx context.Context) (int, error)
+}
+
+// Run background job which sends the due webhook deliveries from the outbox
+func runWebhookDispatcher(ctx context.Context, interval time.Duration, svc webhookDispatcher, log *logrus.Entry) func() error {
+	return runPeriodic(ctx, interval, "webhook dispatcher", log, func(ctx context.Context, log *logrus.Entry) error {
+		sent, err := svc.DispatchDueDeliveries(ctx)
+		if err != nil {
+			return err
+		}
+		if sent > 0 {
+			log.WithField("sent", sent).Debug("Webhooks dispatched")
+		}
+		return nil
+	})
+}
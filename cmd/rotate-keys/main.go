@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	_ "github.com/lib/pq" // init pg driver
+	"github.com/sirupsen/logrus"
+
+	"github.com/dmitrymomot/go-env"
+	"github.com/dmitrymomot/solana-wallets/internal/keyrotation"
+	"github.com/dmitrymomot/solana-wallets/internal/kms"
+	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+	wallet_repository "github.com/dmitrymomot/solana-wallets/svc/wallet/repository"
+)
+
+var (
+	appName = env.GetString("APP_NAME", "rotate-keys")
+
+	// DB
+	dbConnString = env.MustString("DATABASE_URL")
+
+	// Wallet secrets.
+	// The key encryption keys must contain both the new (current) key and the previous ones,
+	// so the data keys wrapped with the previous keys can be unwrapped.
+	walletSecretSalt     = env.MustString("WALLET_SECRET_SALT")
+	walletSecretSaltPrev = env.GetString("WALLET_SECRET_SALT_PREVIOUS", "")
+	walletKEK            = env.GetString("WALLET_KEK", "")
+	walletKEKFile        = env.GetString("WALLET_KEK_FILE", "")
+
+	// Rotation
+	batchSize = env.GetInt("ROTATION_BATCH_SIZE", 100)
+	dryRun    = env.GetBool("ROTATION_DRY_RUN", false)
+
+	// Build tag is set up while deployment
+	buildTag        = "undefined"
+	buildTagRuntime = env.GetString("COMMIT_HASH", buildTag)
+)
+
+func main() {
+	// Init logger
+	logrus.SetReportCaller(false)
+	logger := logrus.WithFields(logrus.Fields{
+		"app":       appName,
+		"build_tag": buildTagRuntime,
+	})
+	logger.Logger.SetLevel(logrus.InfoLevel)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	db, err := sql.Open("postgres", dbConnString)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to init db connection")
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		logger.WithError(err).Fatal("failed to ping db")
+	}
+
+	var keys *kms.LocalProvider
+	switch {
+	case walletKEKFile != "":
+		keys, err = kms.NewLocalProviderFromFile(walletKEKFile)
+	case walletKEK != "":
+		keys, err = kms.NewLocalProviderFromString(walletKEK)
+	default:
+		logger.Fatal("WALLET_KEK or WALLET_KEK_FILE must be set")
+	}
+	if err != nil {
+		logger.WithError(err).Fatal("failed to init key encryption key provider")
+	}
+
+	walletOpts := []solanawallet.Option{solanawallet.WithKeyProvider(keys)}
+	if walletSecretSaltPrev != "" {
+		walletOpts = append(walletOpts, solanawallet.WithPreviousSalts(strings.Split(walletSecretSaltPrev, ",")...))
+	}
+
+	r := keyrotation.NewRotator(
+		wallet_repository.New(db),
+		solanawallet.NewClient(walletSecretSalt, walletOpts...),
+		keys.CurrentKeyID(),
+		keyrotation.WithBatchSize(batchSize),
+		keyrotation.WithDryRun(dryRun),
+		keyrotation.WithLogger(logger.WithField("key_id", keys.CurrentKeyID())),
+	)
+
+	stats, err := r.Run(ctx)
+	log := logger.WithFields(stats.Fields())
+	if err != nil {
+		log.WithError(err).Fatal("key rotation interrupted, run the command again to resume")
+	}
+
+	if stats.Unbound > 0 {
+		log.Warn("some wallets are not bound to their record with associated data yet; " +
+			"keep WALLET_REQUIRE_ASSOCIATED_DATA disabled until they are re-encrypted")
+	}
+	if stats.Pending > 0 {
+		log.Warn("key rotation finished, some wallets are not protected with a wrapped data key yet; " +
+			"they are re-encrypted the next time their PIN is entered; if WALLET_SECRET_SALT is changed, " +
+			"keep the old salt in WALLET_SECRET_SALT_PREVIOUS until then")
+		return
+	}
+
+	log.Info("key rotation finished")
+}
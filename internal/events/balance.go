@@ -0,0 +1,23 @@
+package events
+
+import "time"
+
+// Balance service events
+const (
+	BalanceChecked EventName = "balance.checked"
+)
+
+// BalanceEvents is the list of all balance service events
+var BalanceEvents = []EventName{
+	BalanceChecked,
+}
+
+// BalanceCheckedPayload is the payload of the BalanceChecked event.
+// Mint is "SOL" for the native balance.
+type BalanceCheckedPayload struct {
+	WalletAddress string    `json:"wallet_address"`
+	Mint          string    `json:"mint"`
+	Amount        uint64    `json:"amount"` // in lamports or the smallest token units
+	Decimals      uint8     `json:"decimals"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
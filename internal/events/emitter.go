@@ -1,6 +1,10 @@
 package events
 
-import "sync"
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
 
 type (
 	// EventName is a string alias for event names.
@@ -9,6 +13,11 @@ type (
 	// Listener is a function that is called when an event is fired.
 	Listener func(EventName, interface{}) error
 
+	// TxListener is a function that is called within the database transaction
+	// of the change the event is about. An error rolls the change back.
+	// The transaction is nil if the event isn't bound to a change.
+	TxListener func(context.Context, *sql.Tx, EventName, interface{}) error
+
 	// Emitter is an interface that allows to fire events.
 	Emitter interface {
 		// Emit fires an event with the given name and payload.
@@ -17,6 +26,11 @@ type (
 		On(EventName, ...Listener)
 		// OnMany registers a listener for the given event names.
 		ListenEvents(Listener, ...EventName)
+		// EmitTx calls the transactional listeners of the event synchronously
+		// within the given transaction, returns the first listener error.
+		EmitTx(context.Context, *sql.Tx, EventName, interface{}) error
+		// ListenEventsTx registers a transactional listener for the given event names.
+		ListenEventsTx(TxListener, ...EventName)
 	}
 
 	// Logger is an interface that allows to log events.
@@ -28,16 +42,18 @@ type (
 
 	emitter struct {
 		sync.RWMutex
-		listeners map[EventName][]Listener
-		log       Logger
+		listeners   map[EventName][]Listener
+		txListeners map[EventName][]TxListener
+		log         Logger
 	}
 )
 
 // NewEmitter creates a new Emitter.
 func NewEmitter(log Logger) Emitter {
 	return &emitter{
-		listeners: make(map[EventName][]Listener),
-		log:       log,
+		listeners:   make(map[EventName][]Listener),
+		txListeners: make(map[EventName][]TxListener),
+		log:         log,
 	}
 }
 
@@ -76,3 +92,31 @@ func (e *emitter) ListenEvents(listener Listener, names ...EventName) {
 		e.listeners[name] = append(e.listeners[name], listener)
 	}
 }
+
+// EmitTx calls the transactional listeners of the event synchronously
+// within the given transaction, returns the first listener error.
+func (e *emitter) EmitTx(ctx context.Context, tx *sql.Tx, name EventName, payload interface{}) error {
+	e.RLock()
+	defer e.RUnlock()
+
+	for _, listener := range e.txListeners[name] {
+		if listener == nil {
+			continue
+		}
+		if err := listener(ctx, tx, name, payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListenEventsTx registers a transactional listener for the given event names.
+func (e *emitter) ListenEventsTx(listener TxListener, names ...EventName) {
+	e.Lock()
+	defer e.Unlock()
+
+	for _, name := range names {
+		e.txListeners[name] = append(e.txListeners[name], listener)
+	}
+}
@@ -0,0 +1,107 @@
+package events
+
+import "time"
+
+// Wallet service events
+const (
+	WalletCreated     EventName = "wallet.created"
+	WalletDeleted     EventName = "wallet.deleted"
+	WalletRestored    EventName = "wallet.restored"
+	PinChanged        EventName = "wallet.pin_changed"
+	ExportPerformed   EventName = "wallet.exported"
+	MessageSigned     EventName = "wallet.message_signed"
+	TransactionSigned EventName = "wallet.transaction_signed"
+	TransactionSent   EventName = "wallet.transaction_sent"
+)
+
+// WalletEvents is the list of all wallet service events
+var WalletEvents = []EventName{
+	WalletCreated,
+	WalletDeleted,
+	WalletRestored,
+	PinChanged,
+	ExportPerformed,
+	MessageSigned,
+	TransactionSigned,
+	TransactionSent,
+}
+
+// Wallet creation sources
+const (
+	WalletSourceMnemonic   = "mnemonic"
+	WalletSourcePrivateKey = "private_key"
+	WalletSourceKeystore   = "keystore"
+)
+
+type (
+	// WalletCreatedPayload is the payload of the WalletCreated event
+	WalletCreatedPayload struct {
+		UserID     string    `json:"user_id"`
+		WalletID   string    `json:"wallet_id"`
+		PublicKey  string    `json:"public_key"`
+		IsDefault  bool      `json:"is_default"`
+		Source     string    `json:"source"`
+		OccurredAt time.Time `json:"occurred_at"`
+	}
+
+	// WalletDeletedPayload is the payload of the WalletDeleted event.
+	// The wallet can be restored until PurgeAt.
+	WalletDeletedPayload struct {
+		UserID     string    `json:"user_id"`
+		WalletID   string    `json:"wallet_id"`
+		PublicKey  string    `json:"public_key"`
+		PurgeAt    time.Time `json:"purge_at"`
+		OccurredAt time.Time `json:"occurred_at"`
+	}
+
+	// WalletRestoredPayload is the payload of the WalletRestored event
+	WalletRestoredPayload struct {
+		UserID     string    `json:"user_id"`
+		WalletID   string    `json:"wallet_id"`
+		PublicKey  string    `json:"public_key"`
+		OccurredAt time.Time `json:"occurred_at"`
+	}
+
+	// PinChangedPayload is the payload of the PinChanged event
+	PinChangedPayload struct {
+		UserID     string    `json:"user_id"`
+		WalletID   string    `json:"wallet_id"`
+		OccurredAt time.Time `json:"occurred_at"`
+	}
+
+	// ExportPerformedPayload is the payload of the ExportPerformed event
+	ExportPerformedPayload struct {
+		UserID     string    `json:"user_id"`
+		WalletID   string    `json:"wallet_id"`
+		Format     string    `json:"format"`
+		OccurredAt time.Time `json:"occurred_at"`
+	}
+
+	// MessageSignedPayload is the payload of the MessageSigned event
+	MessageSignedPayload struct {
+		UserID       string    `json:"user_id"`
+		WalletID     string    `json:"wallet_id"`
+		AccountIndex int       `json:"account_index"`
+		PublicKey    string    `json:"public_key"`
+		OccurredAt   time.Time `json:"occurred_at"`
+	}
+
+	// TransactionSignedPayload is the payload of the TransactionSigned event
+	TransactionSignedPayload struct {
+		UserID       string    `json:"user_id"`
+		WalletID     string    `json:"wallet_id"`
+		AccountIndex int       `json:"account_index"`
+		PublicKey    string    `json:"public_key"`
+		OccurredAt   time.Time `json:"occurred_at"`
+	}
+
+	// TransactionSentPayload is the payload of the TransactionSent event
+	TransactionSentPayload struct {
+		UserID       string    `json:"user_id"`
+		WalletID     string    `json:"wallet_id"`
+		AccountIndex int       `json:"account_index"`
+		PublicKey    string    `json:"public_key"`
+		Signature    string    `json:"signature"`
+		OccurredAt   time.Time `json:"occurred_at"`
+	}
+)
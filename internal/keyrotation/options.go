@@ -0,0 +1,31 @@
+package keyrotation
+
+import "github.com/sirupsen/logrus"
+
+// Option is a rotator option
+type Option func(*Rotator)
+
+// WithBatchSize sets the number of wallets processed per batch, 100 by default.
+// Panics if the size isn't positive.
+func WithBatchSize(size int) Option {
+	if size <= 0 {
+		panic("batch size must be positive")
+	}
+	return func(r *Rotator) {
+		r.batchSize = size
+	}
+}
+
+// WithDryRun only counts the wallets to rotate without storing the re-wrapped mnemonics
+func WithDryRun(dryRun bool) Option {
+	return func(r *Rotator) {
+		r.dryRun = dryRun
+	}
+}
+
+// WithLogger sets the progress logger
+func WithLogger(log *logrus.Entry) Option {
+	return func(r *Rotator) {
+		r.log = log
+	}
+}
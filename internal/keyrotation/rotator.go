@@ -0,0 +1,160 @@
+// Package keyrotation re-wraps the wallet data keys with the current key encryption key.
+package keyrotation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+	wallet_repository "github.com/dmitrymomot/solana-wallets/svc/wallet/repository"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type (
+	// Rotator re-wraps the wallet data keys with the current key encryption key.
+	// Wallets are processed in batches ordered by id; already rotated wallets
+	// are filtered out by their key version, so an interrupted run can be just restarted.
+	Rotator struct {
+		repo      Repository
+		wallet    Wallet
+		keyID     string
+		batchSize int
+		dryRun    bool
+		log       *logrus.Entry
+	}
+
+	// Repository is the wallets storage
+	Repository interface {
+		CountWalletsForKeyRotation(ctx context.Context, keyVersion sql.NullString) (int64, error)
+		GetWalletsForKeyRotation(ctx context.Context, arg wallet_repository.GetWalletsForKeyRotationParams) ([]wallet_repository.Wallet, error)
+		RotateWalletMnemonic(ctx context.Context, arg wallet_repository.RotateWalletMnemonicParams) (int64, error)
+	}
+
+	// Wallet re-wraps the encrypted mnemonics
+	Wallet interface {
+		RewrapMnemonic(ctx context.Context, encrypted string) (string, error)
+		KeyVersion(encrypted string) string
+		HasAssociatedData(encrypted string) bool
+	}
+
+	// Stats is the rotation progress
+	Stats struct {
+		Total     int64     // wallets to rotate at the start
+		Processed int64     // wallets processed so far
+		Rotated   int64     // wallets re-wrapped with the current key
+		Pending   int64     // wallets encrypted without the data key, require the PIN to be upgraded
+		Unbound   int64     // pending wallets not bound to their record with associated data
+		Changed   int64     // wallets changed concurrently, rotated by the API on the next access
+		LastID    uuid.UUID // id of the last processed wallet
+	}
+)
+
+// NewRotator creates a rotator for the current key encryption key id
+func NewRotator(repo Repository, wallet Wallet, keyID string, opts ...Option) *Rotator {
+	r := &Rotator{
+		repo:      repo,
+		wallet:    wallet,
+		keyID:     keyID,
+		batchSize: 100,
+		log:       logrus.NewEntry(logrus.StandardLogger()),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run rotates all the wallets not wrapped with the current key encryption key
+func (r *Rotator) Run(ctx context.Context) (Stats, error) {
+	keyVersion := sql.NullString{String: r.keyID, Valid: true}
+
+	var stats Stats
+	total, err := r.repo.CountWalletsForKeyRotation(ctx, keyVersion)
+	if err != nil {
+		return stats, fmt.Errorf("failed to count wallets: %w", err)
+	}
+	stats.Total = total
+	r.log.WithFields(stats.Fields()).Info("key rotation started")
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		wallets, err := r.repo.GetWalletsForKeyRotation(ctx, wallet_repository.GetWalletsForKeyRotationParams{
+			ID:         stats.LastID,
+			KeyVersion: keyVersion,
+			Limit:      int32(r.batchSize),
+		})
+		if err != nil {
+			return stats, fmt.Errorf("failed to get wallets batch: %w", err)
+		}
+		if len(wallets) == 0 {
+			return stats, nil
+		}
+
+		for _, w := range wallets {
+			if err := r.rotate(ctx, w, &stats); err != nil {
+				return stats, fmt.Errorf("failed to rotate wallet %s: %w", w.ID, err)
+			}
+			stats.Processed++
+			stats.LastID = w.ID
+		}
+
+		r.log.WithFields(stats.Fields()).Info("batch processed")
+	}
+}
+
+// rotate re-wraps the data key of a single wallet
+func (r *Rotator) rotate(ctx context.Context, w wallet_repository.Wallet, stats *Stats) error {
+	rewrapped, err := r.wallet.RewrapMnemonic(ctx, w.Mnemonic)
+	if err != nil {
+		if errors.Is(err, solanawallet.ErrNotEnvelopeEncrypted) {
+			stats.Pending++
+			if !r.wallet.HasAssociatedData(w.Mnemonic) {
+				stats.Unbound++
+			}
+			return nil
+		}
+		return err
+	}
+
+	if r.dryRun {
+		stats.Rotated++
+		return nil
+	}
+
+	// the mnemonic is compared to skip the wallets updated concurrently, e.g. with a new PIN
+	n, err := r.repo.RotateWalletMnemonic(ctx, wallet_repository.RotateWalletMnemonicParams{
+		NewMnemonic: rewrapped,
+		KeyVersion:  sql.NullString{String: r.wallet.KeyVersion(rewrapped), Valid: true},
+		ID:          w.ID,
+		OldMnemonic: w.Mnemonic,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store rewrapped mnemonic: %w", err)
+	}
+	if n == 0 {
+		stats.Changed++
+		return nil
+	}
+
+	stats.Rotated++
+	return nil
+}
+
+// Fields returns the stats as log fields
+func (s Stats) Fields() logrus.Fields {
+	return logrus.Fields{
+		"total":     s.Total,
+		"processed": s.Processed,
+		"rotated":   s.Rotated,
+		"pending":   s.Pending,
+		"unbound":   s.Unbound,
+		"changed":   s.Changed,
+		"last_id":   s.LastID.String(),
+	}
+}
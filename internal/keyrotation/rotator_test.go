@@ -0,0 +1,141 @@
+package keyrotation_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/internal/keyrotation"
+	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+	wallet_repository "github.com/dmitrymomot/solana-wallets/svc/wallet/repository"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// fakeRotatorRepository keeps the wallets ordered by id in memory
+	fakeRotatorRepository struct {
+		wallets []wallet_repository.Wallet
+		changed map[uuid.UUID]bool // wallets updated concurrently
+		batches int
+	}
+
+	// fakeRotatorWallet re-wraps the "v3:" mnemonics, the other ones are not envelope encrypted
+	fakeRotatorWallet struct{}
+)
+
+func (r *fakeRotatorRepository) CountWalletsForKeyRotation(ctx context.Context, keyVersion sql.NullString) (int64, error) {
+	var n int64
+	for _, w := range r.wallets {
+		if w.KeyVersion != keyVersion {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeRotatorRepository) GetWalletsForKeyRotation(ctx context.Context, arg wallet_repository.GetWalletsForKeyRotationParams) ([]wallet_repository.Wallet, error) {
+	r.batches++
+	var wallets []wallet_repository.Wallet
+	for _, w := range r.wallets {
+		if bytes.Compare(w.ID[:], arg.ID[:]) > 0 && w.KeyVersion != arg.KeyVersion && len(wallets) < int(arg.Limit) {
+			wallets = append(wallets, w)
+		}
+	}
+	return wallets, nil
+}
+
+func (r *fakeRotatorRepository) RotateWalletMnemonic(ctx context.Context, arg wallet_repository.RotateWalletMnemonicParams) (int64, error) {
+	for i, w := range r.wallets {
+		if w.ID != arg.ID || w.Mnemonic != arg.OldMnemonic || r.changed[w.ID] {
+			continue
+		}
+		r.wallets[i].Mnemonic = arg.NewMnemonic
+		r.wallets[i].KeyVersion = arg.KeyVersion
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (fakeRotatorWallet) RewrapMnemonic(ctx context.Context, encrypted string) (string, error) {
+	if !strings.HasPrefix(encrypted, "v3:") {
+		return "", solanawallet.ErrNotEnvelopeEncrypted
+	}
+	return "v3:new:" + strings.TrimPrefix(encrypted, "v3:old:"), nil
+}
+
+func (fakeRotatorWallet) KeyVersion(encrypted string) string {
+	return strings.Split(encrypted, ":")[1]
+}
+
+func (fakeRotatorWallet) HasAssociatedData(encrypted string) bool {
+	return encrypted != "legacy"
+}
+
+func TestRotator(t *testing.T) {
+	newRepo := func() *fakeRotatorRepository {
+		repo := &fakeRotatorRepository{changed: make(map[uuid.UUID]bool)}
+		mnemonics := []string{"v3:old:a", "v3:new:b", "legacy", "v3:old:c", "v3:old:d", "v2:e"}
+		for i, m := range mnemonics {
+			w := wallet_repository.Wallet{ID: uuid.UUID{15: byte(i + 1)}, Mnemonic: m}
+			if strings.HasPrefix(m, "v3:") {
+				w.KeyVersion = sql.NullString{String: strings.Split(m, ":")[1], Valid: true}
+			}
+			repo.wallets = append(repo.wallets, w)
+		}
+		return repo
+	}
+	newRotator := func(repo *fakeRotatorRepository, dryRun bool) *keyrotation.Rotator {
+		return keyrotation.NewRotator(repo, fakeRotatorWallet{}, "new",
+			keyrotation.WithBatchSize(2),
+			keyrotation.WithDryRun(dryRun),
+			keyrotation.WithLogger(logrus.NewEntry(logrus.New())),
+		)
+	}
+
+	t.Run("rotate in batches", func(t *testing.T) {
+		repo := newRepo()
+		repo.changed[repo.wallets[3].ID] = true
+
+		stats, err := newRotator(repo, false).Run(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, keyrotation.Stats{
+			Total:     5,
+			Processed: 5,
+			Rotated:   2,
+			Pending:   2,
+			Unbound:   1,
+			Changed:   1,
+			LastID:    repo.wallets[5].ID,
+		}, stats)
+		require.Equal(t, 4, repo.batches)
+
+		require.Equal(t, "v3:new:a", repo.wallets[0].Mnemonic)
+		require.Equal(t, "legacy", repo.wallets[2].Mnemonic)
+		require.Equal(t, "v3:old:c", repo.wallets[3].Mnemonic)
+		require.Equal(t, "v3:new:d", repo.wallets[4].Mnemonic)
+		require.Equal(t, sql.NullString{String: "new", Valid: true}, repo.wallets[4].KeyVersion)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		repo := newRepo()
+
+		stats, err := newRotator(repo, true).Run(context.Background())
+		require.NoError(t, err)
+		require.EqualValues(t, 3, stats.Rotated)
+		require.EqualValues(t, 2, stats.Pending)
+		require.EqualValues(t, 1, stats.Unbound)
+		require.Equal(t, newRepo().wallets, repo.wallets)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := newRotator(newRepo(), false).Run(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
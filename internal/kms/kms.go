@@ -0,0 +1,41 @@
+// Package kms provides key encryption key (KEK) providers used to wrap
+// and unwrap per-record data encryption keys (envelope encryption).
+package kms
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// Predefined package errors
+var (
+	ErrKeyNotFound     = errors.New("key encryption key not found")
+	ErrInvalidKey      = errors.New("invalid key encryption key")
+	ErrInvalidKeyID    = errors.New("invalid key encryption key id")
+	ErrUnwrapFailed    = errors.New("failed to unwrap data key")
+	ErrNoKeysAvailable = errors.New("no key encryption keys available")
+)
+
+// KeyProvider wraps and unwraps data encryption keys with a key encryption key.
+// Implementations must be safe for concurrent use.
+type KeyProvider interface {
+	// CurrentKeyID returns the id of the key encryption key used to wrap new data keys.
+	CurrentKeyID() string
+	// WrapKey encrypts the data key with the current key encryption key.
+	// Returns the id of the key encryption key used and the wrapped data key.
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey decrypts the data key wrapped with the key encryption key with the given id.
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// keyIDRegexp restricts key ids to the characters safe to be stored in the ciphertext envelope
+var keyIDRegexp = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`)
+
+// ValidateKeyID returns ErrInvalidKeyID if the key id contains unsupported characters
+func ValidateKeyID(keyID string) error {
+	if !keyIDRegexp.MatchString(keyID) {
+		return ErrInvalidKeyID
+	}
+	return nil
+}
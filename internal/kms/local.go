@@ -0,0 +1,173 @@
+package kms
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// LocalKeySize is the required size of the local key encryption keys (AES-256)
+const LocalKeySize = 32
+
+// LocalProvider is the KeyProvider implementation holding key encryption keys in memory.
+// Keys are loaded from an environment variable or a file.
+// It supports several keys at once, so data keys wrapped with a previous key
+// can be unwrapped during the key rotation.
+type LocalProvider struct {
+	current string
+	keys    map[string][]byte
+}
+
+// localKeysFile is the format of the local keys file
+type localKeysFile struct {
+	CurrentKeyID string            `json:"current_key_id"`
+	Keys         map[string]string `json:"keys"` // key id => base64 encoded key
+}
+
+// NewLocalProvider creates a new local key provider.
+// The current key is used to wrap new data keys, all the keys are used to unwrap them.
+func NewLocalProvider(current string, keys map[string][]byte) (*LocalProvider, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeysAvailable
+	}
+
+	p := &LocalProvider{current: current, keys: make(map[string][]byte, len(keys))}
+	for id, key := range keys {
+		if err := ValidateKeyID(id); err != nil {
+			return nil, fmt.Errorf("%w: %q", err, id)
+		}
+		if len(key) != LocalKeySize {
+			return nil, fmt.Errorf("%w: key %q must be %d bytes long", ErrInvalidKey, id, LocalKeySize)
+		}
+		p.keys[id] = append([]byte(nil), key...)
+	}
+
+	if _, ok := p.keys[current]; !ok {
+		return nil, fmt.Errorf("%w: current key %q", ErrKeyNotFound, current)
+	}
+
+	return p, nil
+}
+
+// NewLocalProviderFromString creates a new local key provider from the string
+// of comma separated "<key id>:<base64 encoded key>" pairs, e.g. the environment variable value.
+// The first key is the current one.
+func NewLocalProviderFromString(s string) (*LocalProvider, error) {
+	var current string
+	keys := make(map[string][]byte)
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: expected <key id>:<base64 key>", ErrInvalidKey)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to decode key %q: %s", ErrInvalidKey, id, err)
+		}
+		if _, exists := keys[id]; exists {
+			return nil, fmt.Errorf("%w: duplicated key id %q", ErrInvalidKeyID, id)
+		}
+
+		if current == "" {
+			current = id
+		}
+		keys[id] = key
+	}
+
+	return NewLocalProvider(current, keys)
+}
+
+// NewLocalProviderFromFile creates a new local key provider from the JSON file:
+//
+//	{"current_key_id": "2023-04", "keys": {"2023-04": "<base64 key>", "2022-10": "<base64 key>"}}
+func NewLocalProviderFromFile(path string) (*LocalProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys file: %w", err)
+	}
+
+	var f localKeysFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse keys file: %w", err)
+	}
+
+	keys := make(map[string][]byte, len(f.Keys))
+	for id, encoded := range f.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to decode key %q: %s", ErrInvalidKey, id, err)
+		}
+		keys[id] = key
+	}
+
+	return NewLocalProvider(f.CurrentKeyID, keys)
+}
+
+// CurrentKeyID returns the id of the key encryption key used to wrap new data keys.
+func (p *LocalProvider) CurrentKeyID() string {
+	return p.current
+}
+
+// WrapKey encrypts the data key with the current key encryption key using AES-256-GCM.
+func (p *LocalProvider) WrapKey(_ context.Context, dataKey []byte) (string, []byte, error) {
+	aead, err := newAEAD(p.keys[p.current])
+	if err != nil {
+		return "", nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// the key id is authenticated to prevent swapping wrapped keys between KEKs
+	return p.current, aead.Seal(nonce, nonce, dataKey, []byte(p.current)), nil
+}
+
+// UnwrapKey decrypts the data key wrapped with the key encryption key with the given id.
+func (p *LocalProvider) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, keyID)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := aead.NonceSize()
+	if len(wrapped) < nonceSize {
+		return nil, ErrUnwrapFailed
+	}
+
+	dataKey, err := aead.Open(nil, wrapped[:nonceSize], wrapped[nonceSize:], []byte(keyID))
+	if err != nil {
+		return nil, ErrUnwrapFailed
+	}
+
+	return dataKey, nil
+}
+
+// newAEAD returns AES-256-GCM cipher for the given key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+	return cipher.NewGCM(block)
+}
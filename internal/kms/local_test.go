@@ -0,0 +1,87 @@
+package kms_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/internal/kms"
+	"github.com/stretchr/testify/require"
+)
+
+func newKey(t *testing.T) []byte {
+	key := make([]byte, kms.LocalKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+func TestLocalProvider(t *testing.T) {
+	ctx := context.Background()
+	dataKey := newKey(t)
+	k1, k2 := newKey(t), newKey(t)
+
+	p, err := kms.NewLocalProviderFromString(fmt.Sprintf(
+		"k2:%s,k1:%s",
+		base64.StdEncoding.EncodeToString(k2),
+		base64.StdEncoding.EncodeToString(k1),
+	))
+	require.NoError(t, err)
+	require.Equal(t, "k2", p.CurrentKeyID())
+
+	keyID, wrapped, err := p.WrapKey(ctx, dataKey)
+	require.NoError(t, err)
+	require.Equal(t, "k2", keyID)
+	require.NotEqual(t, dataKey, wrapped)
+
+	t.Run("unwrap", func(t *testing.T) {
+		unwrapped, err := p.UnwrapKey(ctx, keyID, wrapped)
+		require.NoError(t, err)
+		require.Equal(t, dataKey, unwrapped)
+	})
+
+	t.Run("unwrap with another key id", func(t *testing.T) {
+		_, err := p.UnwrapKey(ctx, "k1", wrapped)
+		require.ErrorIs(t, err, kms.ErrUnwrapFailed)
+	})
+
+	t.Run("unwrap with unknown key id", func(t *testing.T) {
+		_, err := p.UnwrapKey(ctx, "k3", wrapped)
+		require.ErrorIs(t, err, kms.ErrKeyNotFound)
+	})
+
+	t.Run("load from file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys.json")
+		require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(
+			`{"current_key_id": "k1", "keys": {"k1": %q, "k2": %q}}`,
+			base64.StdEncoding.EncodeToString(k1),
+			base64.StdEncoding.EncodeToString(k2),
+		)), 0o600))
+
+		fp, err := kms.NewLocalProviderFromFile(path)
+		require.NoError(t, err)
+		require.Equal(t, "k1", fp.CurrentKeyID())
+
+		unwrapped, err := fp.UnwrapKey(ctx, keyID, wrapped)
+		require.NoError(t, err)
+		require.Equal(t, dataKey, unwrapped)
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		_, err := kms.NewLocalProvider("k1", nil)
+		require.ErrorIs(t, err, kms.ErrNoKeysAvailable)
+
+		_, err = kms.NewLocalProvider("k1", map[string][]byte{"k1": []byte("short")})
+		require.ErrorIs(t, err, kms.ErrInvalidKey)
+
+		_, err = kms.NewLocalProvider("k$1", map[string][]byte{"k$1": k1})
+		require.ErrorIs(t, err, kms.ErrInvalidKeyID)
+
+		_, err = kms.NewLocalProvider("k2", map[string][]byte{"k1": k1})
+		require.ErrorIs(t, err, kms.ErrKeyNotFound)
+	})
+}
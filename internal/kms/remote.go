@@ -0,0 +1,62 @@
+package kms
+
+import (
+	"context"
+	"fmt"
+)
+
+// KeyService is the minimal interface of an external key management system,
+// e.g. HashiCorp Vault transit secrets engine, a cloud KMS or a PKCS#11 HSM session.
+// The key material never leaves the service, only the encrypt and decrypt operations are exposed.
+// The ciphertext is expected to be self-describing with regard to the key version,
+// as Vault transit does, so the key can be rotated on the service side.
+type KeyService interface {
+	// Encrypt encrypts the plaintext with the named key.
+	Encrypt(ctx context.Context, keyName string, plaintext, aad []byte) ([]byte, error)
+	// Decrypt decrypts the ciphertext produced by Encrypt with the same named key.
+	Decrypt(ctx context.Context, keyName string, ciphertext, aad []byte) ([]byte, error)
+}
+
+// RemoteProvider is the KeyProvider implementation backed by an external key service.
+type RemoteProvider struct {
+	svc     KeyService
+	keyName string
+}
+
+// NewRemoteProvider creates a new key provider which wraps data keys
+// with the named key of the external key service.
+func NewRemoteProvider(svc KeyService, keyName string) (*RemoteProvider, error) {
+	if svc == nil {
+		return nil, fmt.Errorf("key service is required")
+	}
+	if err := ValidateKeyID(keyName); err != nil {
+		return nil, fmt.Errorf("%w: %q", err, keyName)
+	}
+
+	return &RemoteProvider{svc: svc, keyName: keyName}, nil
+}
+
+// CurrentKeyID returns the name of the key used to wrap new data keys.
+func (p *RemoteProvider) CurrentKeyID() string {
+	return p.keyName
+}
+
+// WrapKey encrypts the data key with the named key of the external key service.
+func (p *RemoteProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	wrapped, err := p.svc.Encrypt(ctx, p.keyName, dataKey, []byte(p.keyName))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	return p.keyName, wrapped, nil
+}
+
+// UnwrapKey decrypts the data key with the named key of the external key service.
+func (p *RemoteProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	dataKey, err := p.svc.Decrypt(ctx, keyID, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnwrapFailed, err)
+	}
+
+	return dataKey, nil
+}
@@ -0,0 +1,261 @@
+package signingpolicy
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+	"github.com/portto/solana-go-sdk/common"
+)
+
+// Outflows returns the amounts sent from the signer by the transaction, by mint.
+// Transfers are counted if the signer is the authority, SOL transfers back to the signer are skipped.
+// Only the decoded transfers are counted, see CheckedInstruction.
+// Token transfers whose mint isn't verified by the program (see solanatx.Transfer.MintChecked)
+// are returned separately as well, their amounts can't be reliably attributed;
+// those with the mint looked up are still counted by it.
+func Outflows(tx solanatx.Transaction, signer string) (outflows map[string]uint64, unknown []solanatx.Transfer) {
+	outflows = make(map[string]uint64)
+	for _, ins := range tx.Instructions {
+		t := ins.Transfer
+		switch {
+		case t == nil:
+		case t.IsNative && t.Authority == signer && t.Destination != signer:
+			outflows[NativeMint] = addAmounts(outflows[NativeMint], t.Amount.Amount)
+		case !t.IsNative && t.Authority == signer:
+			if !t.MintChecked() {
+				unknown = append(unknown, *t)
+			}
+			if t.Mint != "" {
+				outflows[t.Mint] = addAmounts(outflows[t.Mint], t.Amount.Amount)
+			}
+		}
+	}
+
+	return outflows, unknown
+}
+
+// Check checks the transaction signed by the signer against the policy.
+// spent is the amount already sent from the wallet during the last 24 hours, by mint.
+// Returns *Violation describing the first broken rule, or nil.
+func Check(p Policy, tx solanatx.Transaction, signer string, spent map[string]uint64) error {
+	if err := p.ValidateEffective(); err != nil {
+		// fail closed: the limits can't be checked against the unknown programs
+		return &Violation{
+			Rule:    RuleAllowedPrograms,
+			Message: "allowed programs are required when amount limits are set",
+		}
+	}
+
+	if len(p.AllowedPrograms) > 0 {
+		for _, ins := range tx.Instructions {
+			if !contains(p.AllowedPrograms, ins.ProgramID) {
+				return &Violation{
+					Rule:      RuleAllowedPrograms,
+					Message:   fmt.Sprintf("program %s is not allowed", programName(ins)),
+					ProgramID: ins.ProgramID,
+				}
+			}
+		}
+	}
+
+	if len(p.DeniedDestinations) > 0 {
+		for _, ins := range tx.Instructions {
+			if ins.Transfer == nil {
+				continue
+			}
+			if denied, ok := deniedDestination(p.DeniedDestinations, *ins.Transfer); ok {
+				return &Violation{
+					Rule:        RuleDeniedDestinations,
+					Message:     fmt.Sprintf("destination %s is denied", denied),
+					Destination: ins.Transfer.Destination,
+				}
+			}
+		}
+	}
+
+	if p.HasAmountLimits() {
+		// fail closed: the value moved by the instructions which aren't decoded as transfers can't be counted
+		for _, ins := range tx.Instructions {
+			if !CheckedInstruction(ins, signer) {
+				return &Violation{
+					Rule:      RuleUncheckedTransfer,
+					Message:   fmt.Sprintf("instruction %s of %s may move funds which can't be checked against the amount limits", instructionType(ins), programName(ins)),
+					ProgramID: ins.ProgramID,
+				}
+			}
+		}
+	}
+
+	outflows, unknown := Outflows(tx, signer)
+	if len(unknown) > 0 && p.HasTokenLimits() {
+		// fail closed: the token limits can't be checked without the mint verified on-chain
+		return &Violation{
+			Rule:        RuleUnknownToken,
+			Message:     fmt.Sprintf("the mint of the token sent to %s isn't checked by the instruction, use transfer_checked under the token limits", unknown[0].Destination),
+			Destination: unknown[0].Destination,
+			Amount:      unknown[0].Amount.Amount,
+		}
+	}
+
+	if sol := outflows[NativeMint]; sol > 0 {
+		if p.MaxSOLPerTx != nil && *p.MaxSOLPerTx > 0 && sol > *p.MaxSOLPerTx {
+			return &Violation{
+				Rule:    RuleMaxSOLPerTx,
+				Message: fmt.Sprintf("transaction sends %d lamports, the limit is %d", sol, *p.MaxSOLPerTx),
+				Mint:    NativeMint,
+				Limit:   *p.MaxSOLPerTx,
+				Amount:  sol,
+			}
+		}
+		if total := addAmounts(spent[NativeMint], sol); p.DailySOLLimit != nil && *p.DailySOLLimit > 0 && total > *p.DailySOLLimit {
+			return &Violation{
+				Rule:    RuleDailySOLLimit,
+				Message: fmt.Sprintf("daily outflow would reach %d lamports, the limit is %d", total, *p.DailySOLLimit),
+				Mint:    NativeMint,
+				Limit:   *p.DailySOLLimit,
+				Amount:  total,
+			}
+		}
+	}
+
+	mints := make([]string, 0, len(outflows))
+	for mint := range outflows {
+		if mint != NativeMint {
+			mints = append(mints, mint)
+		}
+	}
+	sort.Strings(mints) // deterministic order of the reported violations
+
+	for _, mint := range mints {
+		amount := outflows[mint]
+		if limit := p.MaxTokenPerTx[mint]; limit > 0 && amount > limit {
+			return &Violation{
+				Rule:    RuleMaxTokenPerTx,
+				Message: fmt.Sprintf("transaction sends %d base units of %s, the limit is %d", amount, mint, limit),
+				Mint:    mint,
+				Limit:   limit,
+				Amount:  amount,
+			}
+		}
+		if limit, total := p.DailyTokenLimits[mint], addAmounts(spent[mint], amount); limit > 0 && total > limit {
+			return &Violation{
+				Rule:    RuleDailyTokenLimit,
+				Message: fmt.Sprintf("daily outflow of %s would reach %d base units, the limit is %d", mint, total, limit),
+				Mint:    mint,
+				Limit:   limit,
+				Amount:  total,
+			}
+		}
+	}
+
+	return nil
+}
+
+// CheckedInstruction reports whether the value moved by the instruction is counted by Outflows.
+// Instructions of the System, Token and Token-2022 programs are checked if they are decoded transfers
+// or can't move funds from the signer; e.g. approve, set_authority and assign could hand the funds over
+// without a transfer. Closing a token account is checked only if the rent goes back to the signer.
+// Instructions of the other programs are left to the allowed programs list.
+func CheckedInstruction(ins solanatx.Instruction, signer string) bool {
+	programID := common.PublicKeyFromString(ins.ProgramID)
+	switch {
+	case ins.Transfer != nil:
+		return true
+	case programID == common.SystemProgramID:
+		return contains(safeSystemInstructions, ins.Type)
+	case solanatx.IsTokenProgram(programID):
+		if ins.Type == "close_account" {
+			// accounts: account, destination, owner
+			return len(ins.Accounts) > 1 && ins.Accounts[1] == signer
+		}
+		return contains(safeTokenInstructions, ins.Type)
+	}
+	return true
+}
+
+// System program instructions which can't move funds from the signer
+var safeSystemInstructions = []string{
+	"advance_nonce_account",
+	"initialize_nonce_account",
+	"upgrade_nonce_account",
+}
+
+// Token program instructions which can't move funds from the signer
+var safeTokenInstructions = []string{
+	"initialize_mint",
+	"initialize_account",
+	"initialize_multisig",
+	"revoke",
+	"freeze_account",
+	"thaw_account",
+	"initialize_account2",
+	"sync_native",
+	"initialize_account3",
+	"initialize_multisig2",
+	"initialize_mint2",
+}
+
+func programName(ins solanatx.Instruction) string {
+	if ins.ProgramName != "" {
+		return ins.ProgramName
+	}
+	return ins.ProgramID
+}
+
+func instructionType(ins solanatx.Instruction) string {
+	if ins.Type != "" {
+		return ins.Type
+	}
+	return "unknown"
+}
+
+// deniedDestination returns the denied address matching the transfer destination.
+// For token transfers the destination is also matched against the associated token accounts
+// of the denied addresses, for both SPL Token and Token-2022 programs.
+func deniedDestination(denied []string, t solanatx.Transfer) (string, bool) {
+	if contains(denied, t.Destination) {
+		return t.Destination, true
+	}
+	if t.IsNative || t.Mint == "" {
+		return "", false
+	}
+
+	mint := common.PublicKeyFromString(t.Mint)
+	for _, owner := range denied {
+		for _, programID := range []common.PublicKey{common.TokenProgramID, solanatx.Token2022ProgramID} {
+			ata, _, err := common.FindProgramAddress(
+				[][]byte{
+					common.PublicKeyFromString(owner).Bytes(),
+					programID.Bytes(),
+					mint.Bytes(),
+				},
+				common.SPLAssociatedTokenAccountProgramID,
+			)
+			if err == nil && ata.ToBase58() == t.Destination {
+				return owner, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// addAmounts returns the sum of the amounts capped at the max uint64 value,
+// so a huge amount can't wrap around and pass the limits
+func addAmounts(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
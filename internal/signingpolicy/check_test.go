@@ -0,0 +1,401 @@
+package signingpolicy_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/internal/signingpolicy"
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+	"github.com/dmitrymomot/solana/types"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/token"
+	sdktypes "github.com/portto/solana-go-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	signerKey := sdktypes.NewAccount().PublicKey
+	signer := signerKey.ToBase58()
+	recipient := sdktypes.NewAccount().PublicKey
+	mint := sdktypes.NewAccount().PublicKey
+	recipientAta, _, err := common.FindAssociatedTokenAddress(recipient, mint)
+	require.NoError(t, err)
+
+	tx := solanatx.Transaction{
+		Instructions: []solanatx.Instruction{
+			{
+				ProgramID: common.SystemProgramID.ToBase58(),
+				Transfer: &solanatx.Transfer{
+					Source:      signer,
+					Destination: recipient.ToBase58(),
+					Authority:   signer,
+					IsNative:    true,
+					Amount:      types.NewDefaultTokenAmount(2_000),
+				},
+			},
+			decodeInstruction(t, signerKey, token.TransferChecked(token.TransferCheckedParam{
+				From:     sdktypes.NewAccount().PublicKey,
+				To:       recipientAta,
+				Mint:     mint,
+				Auth:     signerKey,
+				Amount:   500,
+				Decimals: 6,
+			})),
+		},
+	}
+
+	programs := []string{common.SystemProgramID.ToBase58(), common.TokenProgramID.ToBase58()}
+
+	t.Run("outflows", func(t *testing.T) {
+		outflows, unknown := signingpolicy.Outflows(tx, signer)
+		require.Empty(t, unknown)
+		require.Equal(t, map[string]uint64{
+			signingpolicy.NativeMint: 2_000,
+			mint.ToBase58():          500,
+		}, outflows)
+	})
+
+	tests := []struct {
+		name   string
+		policy signingpolicy.Policy
+		spent  map[string]uint64
+		rule   string
+	}{
+		{
+			name:   "empty policy",
+			policy: signingpolicy.Policy{},
+		},
+		{
+			name: "allowed programs",
+			policy: signingpolicy.Policy{
+				AllowedPrograms: []string{common.SystemProgramID.ToBase58()},
+			},
+			rule: signingpolicy.RuleAllowedPrograms,
+		},
+		{
+			name:   "max sol per tx",
+			policy: signingpolicy.Policy{AllowedPrograms: programs, MaxSOLPerTx: pointer(1_000)},
+			rule:   signingpolicy.RuleMaxSOLPerTx,
+		},
+		{
+			name:   "max sol per tx not reached",
+			policy: signingpolicy.Policy{AllowedPrograms: programs, MaxSOLPerTx: pointer(2_000)},
+		},
+		{
+			name:   "daily sol limit",
+			policy: signingpolicy.Policy{AllowedPrograms: programs, DailySOLLimit: pointer(2_500)},
+			spent:  map[string]uint64{signingpolicy.NativeMint: 1_000},
+			rule:   signingpolicy.RuleDailySOLLimit,
+		},
+		{
+			name:   "max token per tx",
+			policy: signingpolicy.Policy{AllowedPrograms: programs, MaxTokenPerTx: map[string]uint64{mint.ToBase58(): 499}},
+			rule:   signingpolicy.RuleMaxTokenPerTx,
+		},
+		{
+			name:   "daily token limit",
+			policy: signingpolicy.Policy{AllowedPrograms: programs, DailyTokenLimits: map[string]uint64{mint.ToBase58(): 1_000}},
+			spent:  map[string]uint64{mint.ToBase58(): 501},
+			rule:   signingpolicy.RuleDailyTokenLimit,
+		},
+		{
+			name:   "amount limits require allowed programs",
+			policy: signingpolicy.Policy{MaxSOLPerTx: pointer(2_000)},
+			rule:   signingpolicy.RuleAllowedPrograms,
+		},
+		{
+			name:   "denied wallet address matches its token account",
+			policy: signingpolicy.Policy{DeniedDestinations: []string{recipient.ToBase58()}},
+			rule:   signingpolicy.RuleDeniedDestinations,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := signingpolicy.Check(tt.policy, tx, signer, tt.spent)
+			if tt.rule == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, signingpolicy.ErrViolation)
+			v, ok := err.(*signingpolicy.Violation)
+			require.True(t, ok)
+			require.Equal(t, tt.rule, v.Rule)
+		})
+	}
+
+	t.Run("unknown token fails closed", func(t *testing.T) {
+		unresolved := solanatx.Transaction{Instructions: []solanatx.Instruction{{
+			ProgramID: common.TokenProgramID.ToBase58(),
+			Transfer: &solanatx.Transfer{
+				Destination: recipientAta.ToBase58(),
+				Authority:   signer,
+				Amount:      types.TokenAmount{Amount: 1},
+			},
+		}}}
+
+		require.NoError(t, signingpolicy.Check(signingpolicy.Policy{}, unresolved, signer, nil))
+
+		err := signingpolicy.Check(signingpolicy.Policy{
+			AllowedPrograms: programs,
+			MaxTokenPerTx:   map[string]uint64{mint.ToBase58(): 10},
+		}, unresolved, signer, nil)
+		require.ErrorIs(t, err, signingpolicy.ErrViolation)
+		require.Equal(t, signingpolicy.RuleUnknownToken, err.(*signingpolicy.Violation).Rule)
+	})
+
+	t.Run("looked up mint fails closed", func(t *testing.T) {
+		// plain transfer with the mint looked up from the source account, which may change before the transaction lands
+		plain := decodeInstruction(t, signerKey, token.Transfer(token.TransferParam{
+			From:   sdktypes.NewAccount().PublicKey,
+			To:     recipientAta,
+			Auth:   signerKey,
+			Amount: 1,
+		}))
+		plain.Transfer.Mint = mint.ToBase58()
+		lookedUp := solanatx.Transaction{Instructions: []solanatx.Instruction{plain}}
+
+		outflows, unknown := signingpolicy.Outflows(lookedUp, signer)
+		require.Len(t, unknown, 1)
+		require.Equal(t, map[string]uint64{mint.ToBase58(): 1}, outflows)
+
+		require.NoError(t, signingpolicy.Check(signingpolicy.Policy{AllowedPrograms: programs, MaxSOLPerTx: pointer(1)}, lookedUp, signer, nil))
+
+		err := signingpolicy.Check(signingpolicy.Policy{
+			AllowedPrograms:  programs,
+			DailyTokenLimits: map[string]uint64{mint.ToBase58(): 10},
+		}, lookedUp, signer, nil)
+		require.ErrorIs(t, err, signingpolicy.ErrViolation)
+		require.Equal(t, signingpolicy.RuleUnknownToken, err.(*signingpolicy.Violation).Rule)
+	})
+}
+
+// decodeInstruction decodes the instruction the same way the signed transactions are decoded
+func decodeInstruction(t *testing.T, feePayer common.PublicKey, ins sdktypes.Instruction) solanatx.Instruction {
+	tx, err := solanatx.DecodeMessage(sdktypes.NewMessage(sdktypes.NewMessageParam{
+		FeePayer:        feePayer,
+		RecentBlockhash: "9rAtxuhtKn8qagc3UtZFyhLrw5zgh6etCLnm3zGTSuC8",
+		Instructions:    []sdktypes.Instruction{ins},
+	}))
+	require.NoError(t, err)
+	require.Len(t, tx.Instructions, 1)
+	return tx.Instructions[0]
+}
+
+func TestOutflowsOverflow(t *testing.T) {
+	signer := sdktypes.NewAccount().PublicKey.ToBase58()
+	transfer := solanatx.Instruction{
+		ProgramID: common.SystemProgramID.ToBase58(),
+		Transfer: &solanatx.Transfer{
+			Source:      signer,
+			Destination: sdktypes.NewAccount().PublicKey.ToBase58(),
+			Authority:   signer,
+			IsNative:    true,
+			Amount:      types.NewDefaultTokenAmount(math.MaxUint64/2 + 1),
+		},
+	}
+	tx := solanatx.Transaction{Instructions: []solanatx.Instruction{transfer, transfer}}
+
+	outflows, _ := signingpolicy.Outflows(tx, signer)
+	require.Equal(t, uint64(math.MaxUint64), outflows[signingpolicy.NativeMint])
+
+	err := signingpolicy.Check(signingpolicy.Policy{
+		AllowedPrograms: []string{common.SystemProgramID.ToBase58()},
+		DailySOLLimit:   pointer(1_000),
+	}, tx, signer, map[string]uint64{signingpolicy.NativeMint: 1})
+	require.ErrorIs(t, err, signingpolicy.ErrViolation)
+	require.Equal(t, signingpolicy.RuleDailySOLLimit, err.(*signingpolicy.Violation).Rule)
+}
+
+func TestCheckUncheckedInstructions(t *testing.T) {
+	signer := sdktypes.NewAccount().PublicKey.ToBase58()
+	other := sdktypes.NewAccount().PublicKey.ToBase58()
+	policy := signingpolicy.Policy{
+		AllowedPrograms: []string{common.SystemProgramID.ToBase58(), common.TokenProgramID.ToBase58()},
+		DailySOLLimit:   pointer(1_000),
+	}
+
+	tests := []struct {
+		name string
+		ins  solanatx.Instruction
+		rule string
+	}{
+		{
+			name: "token approve",
+			ins:  solanatx.Instruction{ProgramID: common.TokenProgramID.ToBase58(), Type: "approve", Accounts: []string{other, other, signer}},
+			rule: signingpolicy.RuleUncheckedTransfer,
+		},
+		{
+			name: "token set authority",
+			ins:  solanatx.Instruction{ProgramID: common.TokenProgramID.ToBase58(), Type: "set_authority", Accounts: []string{other, signer}},
+			rule: signingpolicy.RuleUncheckedTransfer,
+		},
+		{
+			name: "token close account to another address",
+			ins:  solanatx.Instruction{ProgramID: common.TokenProgramID.ToBase58(), Type: "close_account", Accounts: []string{other, other, signer}},
+			rule: signingpolicy.RuleUncheckedTransfer,
+		},
+		{
+			name: "token close account to the signer",
+			ins:  solanatx.Instruction{ProgramID: common.TokenProgramID.ToBase58(), Type: "close_account", Accounts: []string{other, signer, signer}},
+		},
+		{
+			name: "undecoded token instruction",
+			ins:  solanatx.Instruction{ProgramID: common.TokenProgramID.ToBase58(), Accounts: []string{other, signer}},
+			rule: signingpolicy.RuleUncheckedTransfer,
+		},
+		{
+			name: "system assign",
+			ins:  solanatx.Instruction{ProgramID: common.SystemProgramID.ToBase58(), Type: "assign", Accounts: []string{signer}},
+			rule: signingpolicy.RuleUncheckedTransfer,
+		},
+		{
+			name: "system advance nonce account",
+			ins:  solanatx.Instruction{ProgramID: common.SystemProgramID.ToBase58(), Type: "advance_nonce_account", Accounts: []string{other, other, signer}},
+		},
+		{
+			name: "create account over the limit",
+			ins: solanatx.Instruction{ProgramID: common.SystemProgramID.ToBase58(), Type: "create_account", Transfer: &solanatx.Transfer{
+				Source: signer, Destination: other, Authority: signer, IsNative: true, Amount: types.NewDefaultTokenAmount(1_001),
+			}},
+			rule: signingpolicy.RuleDailySOLLimit,
+		},
+		{
+			name: "nonce withdrawal to the signer",
+			ins: solanatx.Instruction{ProgramID: common.SystemProgramID.ToBase58(), Type: "withdraw_nonce_account", Transfer: &solanatx.Transfer{
+				Source: other, Destination: signer, Authority: signer, IsNative: true, Amount: types.NewDefaultTokenAmount(1_001),
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := solanatx.Transaction{Instructions: []solanatx.Instruction{tt.ins}}
+
+			// without amount limits the instructions are limited by the allowed programs only
+			require.NoError(t, signingpolicy.Check(signingpolicy.Policy{AllowedPrograms: policy.AllowedPrograms}, tx, signer, nil))
+
+			err := signingpolicy.Check(policy, tx, signer, nil)
+			if tt.rule == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, signingpolicy.ErrViolation)
+			require.Equal(t, tt.rule, err.(*signingpolicy.Violation).Rule)
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	defaults := signingpolicy.Policy{
+		AllowedPrograms:    []string{"a"},
+		MaxSOLPerTx:        pointer(100),
+		DailySOLLimit:      pointer(1_000),
+		MaxTokenPerTx:      map[string]uint64{"mint1": 1, "mint2": 2},
+		DeniedDestinations: []string{"x"},
+	}
+
+	t.Run("nil fields inherit the defaults", func(t *testing.T) {
+		require.Equal(t, defaults, signingpolicy.Merge(defaults, signingpolicy.Policy{}))
+	})
+
+	t.Run("wallet values can only tighten the defaults", func(t *testing.T) {
+		p := signingpolicy.Merge(defaults, signingpolicy.Policy{
+			AllowedPrograms:    []string{"b", "a"},
+			MaxSOLPerTx:        pointer(50),
+			DailySOLLimit:      pointer(2_000),
+			MaxTokenPerTx:      map[string]uint64{"mint1": 10, "mint2": 0, "mint3": 3},
+			DeniedDestinations: []string{"y", "x"},
+		})
+		require.Equal(t, []string{"a"}, p.AllowedPrograms)
+		require.Equal(t, uint64(50), *p.MaxSOLPerTx)
+		require.Equal(t, uint64(1_000), *p.DailySOLLimit)
+		require.Equal(t, map[string]uint64{"mint1": 1, "mint2": 2, "mint3": 3}, p.MaxTokenPerTx)
+		require.Equal(t, []string{"x", "y"}, p.DeniedDestinations)
+	})
+
+	t.Run("empty and zero values can't clear the defaults", func(t *testing.T) {
+		p := signingpolicy.Merge(defaults, signingpolicy.Policy{
+			AllowedPrograms: []string{},
+			MaxSOLPerTx:     pointer(0),
+			DailySOLLimit:   pointer(0),
+		})
+		require.Equal(t, defaults, p)
+
+		p = signingpolicy.Merge(defaults, signingpolicy.Policy{AllowedPrograms: []string{"b"}})
+		require.Equal(t, defaults.AllowedPrograms, p.AllowedPrograms)
+	})
+
+	t.Run("wallet values apply without defaults", func(t *testing.T) {
+		p := signingpolicy.Merge(signingpolicy.Policy{}, signingpolicy.Policy{
+			AllowedPrograms: []string{"b"},
+			MaxSOLPerTx:     pointer(50),
+		})
+		require.Equal(t, []string{"b"}, p.AllowedPrograms)
+		require.Equal(t, uint64(50), *p.MaxSOLPerTx)
+		require.Nil(t, p.DailySOLLimit)
+	})
+}
+
+func TestValidateOverride(t *testing.T) {
+	defaults := signingpolicy.Policy{
+		AllowedPrograms: []string{"a"},
+		MaxSOLPerTx:     pointer(100),
+		MaxTokenPerTx:   map[string]uint64{"mint1": 1},
+	}
+
+	require.NoError(t, signingpolicy.Policy{}.ValidateOverride(defaults))
+	require.NoError(t, signingpolicy.Policy{
+		AllowedPrograms:  []string{"a"},
+		MaxSOLPerTx:      pointer(100),
+		DailySOLLimit:    pointer(0),
+		MaxTokenPerTx:    map[string]uint64{"mint1": 1, "mint2": 0},
+		DailyTokenLimits: map[string]uint64{"mint1": 5},
+	}.ValidateOverride(defaults))
+	require.NoError(t, signingpolicy.Policy{AllowedPrograms: []string{}}.ValidateOverride(signingpolicy.Policy{}))
+
+	for name, p := range map[string]signingpolicy.Policy{
+		"cleared allowed programs": {AllowedPrograms: []string{}},
+		"program not allowed":      {AllowedPrograms: []string{"a", "b"}},
+		"cleared sol limit":        {MaxSOLPerTx: pointer(0)},
+		"raised sol limit":         {MaxSOLPerTx: pointer(101)},
+		"cleared token limit":      {MaxTokenPerTx: map[string]uint64{"mint1": 0}},
+		"raised token limit":       {MaxTokenPerTx: map[string]uint64{"mint1": 2}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, p.ValidateOverride(defaults), signingpolicy.ErrInvalidPolicy)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, signingpolicy.Policy{
+		AllowedPrograms:    []string{common.SystemProgramID.ToBase58()},
+		MaxTokenPerTx:      map[string]uint64{common.TokenProgramID.ToBase58(): 1},
+		DeniedDestinations: []string{common.MemoProgramID.ToBase58()},
+	}.Validate())
+
+	err := signingpolicy.Policy{DailyTokenLimits: map[string]uint64{"not a mint": 1}}.Validate()
+	require.ErrorIs(t, err, signingpolicy.ErrInvalidPolicy)
+
+	err = signingpolicy.Policy{DeniedDestinations: []string{"0OIl"}}.Validate()
+	require.ErrorIs(t, err, signingpolicy.ErrInvalidPolicy)
+
+	t.Run("effective policy", func(t *testing.T) {
+		require.NoError(t, signingpolicy.Policy{}.ValidateEffective())
+		require.NoError(t, signingpolicy.Policy{MaxSOLPerTx: pointer(0)}.ValidateEffective())
+		require.NoError(t, signingpolicy.Policy{
+			AllowedPrograms: []string{common.SystemProgramID.ToBase58()},
+			DailySOLLimit:   pointer(1),
+		}.ValidateEffective())
+
+		err := signingpolicy.Policy{DailyTokenLimits: map[string]uint64{"mint": 1}}.ValidateEffective()
+		require.ErrorIs(t, err, signingpolicy.ErrInvalidPolicy)
+	})
+}
+
+func pointer(v uint64) *uint64 {
+	return &v
+}
@@ -0,0 +1,46 @@
+package signingpolicy
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Predefined package errors
+var (
+	ErrViolation     = errors.New("signing policy violation")
+	ErrInvalidPolicy = errors.New("invalid signing policy")
+)
+
+// Policy rules
+const (
+	RuleAllowedPrograms    = "allowed_programs"
+	RuleMaxSOLPerTx        = "max_sol_per_tx"
+	RuleMaxTokenPerTx      = "max_token_per_tx"
+	RuleDailySOLLimit      = "daily_sol_limit"
+	RuleDailyTokenLimit    = "daily_token_limits"
+	RuleDeniedDestinations = "denied_destinations"
+	RuleUnknownToken       = "unknown_token"
+	RuleUncheckedTransfer  = "unchecked_transfer"
+)
+
+// Violation describes the policy rule broken by the transaction.
+// It unwraps to ErrViolation.
+type Violation struct {
+	Rule        string `json:"rule"`
+	Message     string `json:"message"`
+	ProgramID   string `json:"program_id,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	Mint        string `json:"mint,omitempty"`
+	Limit       uint64 `json:"limit,omitempty"`
+	Amount      uint64 `json:"amount,omitempty"` // the amount which would be reached with this transaction
+}
+
+// Error implements the error interface
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s", ErrViolation.Error(), v.Message)
+}
+
+// Unwrap returns ErrViolation
+func (v *Violation) Unwrap() error {
+	return ErrViolation
+}
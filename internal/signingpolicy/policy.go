@@ -0,0 +1,246 @@
+package signingpolicy
+
+import (
+	"fmt"
+
+	"github.com/mr-tron/base58"
+	"github.com/portto/solana-go-sdk/common"
+)
+
+// NativeMint is the pseudo mint address of SOL in the token limits and outflows
+const NativeMint = "SOL"
+
+// Policy defines the guardrails checked before a transaction is signed.
+// All amounts are in base units: lamports for SOL, the smallest token units for SPL tokens.
+//
+// Policies are layered: the wallet policy is applied on top of the default one,
+// nil fields of the wallet policy fall back to the default values.
+// The default policy is a floor, a wallet policy can only tighten it.
+type Policy struct {
+	// Programs the transaction may call; nil inherits the default list, empty list allows any program.
+	// The wallet list is intersected with the default one.
+	AllowedPrograms []string `json:"allowed_programs"`
+	// Max SOL amount sent from the wallet in a single transaction; nil inherits the default, 0 means no limit.
+	// The lower of the wallet and default limits applies.
+	MaxSOLPerTx *uint64 `json:"max_sol_per_tx,omitempty"`
+	// Max token amounts sent from the wallet in a single transaction by mint; 0 means no limit
+	MaxTokenPerTx map[string]uint64 `json:"max_token_per_tx,omitempty"`
+	// Max SOL amount sent from the wallet during the last 24 hours; nil inherits the default, 0 means no limit.
+	// The lower of the wallet and default limits applies.
+	DailySOLLimit *uint64 `json:"daily_sol_limit,omitempty"`
+	// Max token amounts sent from the wallet during the last 24 hours by mint; 0 means no limit
+	DailyTokenLimits map[string]uint64 `json:"daily_token_limits,omitempty"`
+	// Addresses which must never receive funds, either wallet or token account addresses.
+	// The lists are merged, so a wallet policy can't drop the default entries.
+	DeniedDestinations []string `json:"denied_destinations,omitempty"`
+}
+
+// Merge returns the policy p applied on top of the defaults.
+// The defaults are a floor: the allowed programs are intersected,
+// the lower of the non-zero limits applies and the denied destinations are merged.
+func Merge(defaults, p Policy) Policy {
+	return Policy{
+		AllowedPrograms:    intersectLists(defaults.AllowedPrograms, p.AllowedPrograms),
+		MaxSOLPerTx:        minLimit(defaults.MaxSOLPerTx, p.MaxSOLPerTx),
+		MaxTokenPerTx:      mergeLimits(defaults.MaxTokenPerTx, p.MaxTokenPerTx),
+		DailySOLLimit:      minLimit(defaults.DailySOLLimit, p.DailySOLLimit),
+		DailyTokenLimits:   mergeLimits(defaults.DailyTokenLimits, p.DailyTokenLimits),
+		DeniedDestinations: mergeLists(defaults.DeniedDestinations, p.DeniedDestinations),
+	}
+}
+
+// Validate checks that all program, mint and destination addresses are valid public keys
+func (p Policy) Validate() error {
+	for _, addr := range p.AllowedPrograms {
+		if !isPublicKey(addr) {
+			return fmt.Errorf("%w: invalid program id %q", ErrInvalidPolicy, addr)
+		}
+	}
+	for _, limits := range []map[string]uint64{p.MaxTokenPerTx, p.DailyTokenLimits} {
+		for mint := range limits {
+			if !isPublicKey(mint) {
+				return fmt.Errorf("%w: invalid mint %q", ErrInvalidPolicy, mint)
+			}
+		}
+	}
+	for _, addr := range p.DeniedDestinations {
+		if !isPublicKey(addr) {
+			return fmt.Errorf("%w: invalid destination %q", ErrInvalidPolicy, addr)
+		}
+	}
+
+	return nil
+}
+
+// ValidateOverride checks that the wallet policy p doesn't try to loosen the defaults,
+// such values would be silently ignored by Merge
+func (p Policy) ValidateOverride(defaults Policy) error {
+	if p.AllowedPrograms != nil && len(defaults.AllowedPrograms) > 0 {
+		if len(p.AllowedPrograms) == 0 {
+			return fmt.Errorf("%w: allowed programs can't be cleared, the default list is set", ErrInvalidPolicy)
+		}
+		for _, addr := range p.AllowedPrograms {
+			if !contains(defaults.AllowedPrograms, addr) {
+				return fmt.Errorf("%w: program %q isn't allowed by the default policy", ErrInvalidPolicy, addr)
+			}
+		}
+	}
+	if err := validateLimitOverride("max_sol_per_tx", defaults.MaxSOLPerTx, p.MaxSOLPerTx); err != nil {
+		return err
+	}
+	if err := validateLimitOverride("daily_sol_limit", defaults.DailySOLLimit, p.DailySOLLimit); err != nil {
+		return err
+	}
+	for _, limits := range []struct {
+		name               string
+		defaults, override map[string]uint64
+	}{
+		{"max_token_per_tx", defaults.MaxTokenPerTx, p.MaxTokenPerTx},
+		{"daily_token_limits", defaults.DailyTokenLimits, p.DailyTokenLimits},
+	} {
+		for mint, limit := range limits.override {
+			def, ok := limits.defaults[mint]
+			if !ok {
+				continue
+			}
+			if err := validateLimitOverride(limits.name+"."+mint, &def, &limit); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateEffective checks the policy the transactions are checked against,
+// i.e. the default policy or the wallet policy merged with it.
+// Amount limits can't be enforced on the instructions of unknown programs,
+// so they require the allowed programs list.
+func (p Policy) ValidateEffective() error {
+	if p.HasAmountLimits() && len(p.AllowedPrograms) == 0 {
+		return fmt.Errorf("%w: allowed programs are required when amount limits are set", ErrInvalidPolicy)
+	}
+	return nil
+}
+
+// HasAmountLimits reports whether any SOL or token amount is limited by the policy
+func (p Policy) HasAmountLimits() bool {
+	if p.MaxSOLPerTx != nil && *p.MaxSOLPerTx > 0 {
+		return true
+	}
+	if p.DailySOLLimit != nil && *p.DailySOLLimit > 0 {
+		return true
+	}
+	return p.HasTokenLimits()
+}
+
+// HasTokenLimits reports whether any token amount is limited by the policy
+func (p Policy) HasTokenLimits() bool {
+	for _, limit := range p.MaxTokenPerTx {
+		if limit > 0 {
+			return true
+		}
+	}
+	for _, limit := range p.DailyTokenLimits {
+		if limit > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeLimits returns the lower of the non-zero default and override limits by mint
+func mergeLimits(defaults, overrides map[string]uint64) map[string]uint64 {
+	if len(defaults) == 0 && len(overrides) == 0 {
+		return nil
+	}
+
+	result := make(map[string]uint64, len(defaults)+len(overrides))
+	for mint, limit := range defaults {
+		result[mint] = limit
+	}
+	for mint, limit := range overrides {
+		if current, ok := result[mint]; !ok || current == 0 || (limit > 0 && limit < current) {
+			result[mint] = limit
+		}
+	}
+
+	return result
+}
+
+// minLimit returns the lower of the non-zero limits, 0 or nil override can't clear the default limit
+func minLimit(def, override *uint64) *uint64 {
+	if override == nil || (*override == 0 && def != nil) {
+		return def
+	}
+	if def == nil || *def == 0 || *override < *def {
+		return override
+	}
+	return def
+}
+
+// validateLimitOverride checks that the override doesn't clear or raise the default limit
+func validateLimitOverride(name string, def, override *uint64) error {
+	if def == nil || *def == 0 || override == nil {
+		return nil
+	}
+	if *override == 0 {
+		return fmt.Errorf("%w: %s can't be cleared, the default limit is %d", ErrInvalidPolicy, name, *def)
+	}
+	if *override > *def {
+		return fmt.Errorf("%w: %s can't exceed the default limit %d", ErrInvalidPolicy, name, *def)
+	}
+	return nil
+}
+
+// intersectLists returns the values of the override list allowed by the defaults, preserving the order.
+// Empty list means any value, so the empty side yields the other list.
+// If the lists have nothing in common, the defaults are kept.
+func intersectLists(defaults, override []string) []string {
+	if override == nil {
+		return defaults
+	}
+	if len(defaults) == 0 {
+		return override
+	}
+
+	result := make([]string, 0, len(override))
+	for _, v := range override {
+		if contains(defaults, v) && !contains(result, v) {
+			result = append(result, v)
+		}
+	}
+	if len(result) == 0 {
+		return defaults
+	}
+
+	return result
+}
+
+// mergeLists returns the union of the given lists, preserving the order
+func mergeLists(a, b []string) []string {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]struct{}, len(a)+len(b))
+	result := make([]string, 0, len(a)+len(b))
+	for _, list := range [][]string{a, b} {
+		for _, v := range list {
+			if _, ok := seen[v]; ok {
+				continue
+			}
+			seen[v] = struct{}{}
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// isPublicKey reports whether s is a base58 encoded 32 bytes key.
+// Program ids and token accounts are off the ed25519 curve, so the curve point isn't checked.
+func isPublicKey(s string) bool {
+	b, err := base58.Decode(s)
+	return err == nil && len(b) == common.PublicKeyLength
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
 	"github.com/dmitrymomot/solana/client"
 	"github.com/dmitrymomot/solana/token_metadata"
 	"github.com/go-redis/cache/v8"
+	sdkclient "github.com/portto/solana-go-sdk/client"
 )
 
 type (
@@ -65,3 +67,61 @@ func (c *SolanaClientCacheWrapper) GetTokenMetadata(ctx context.Context, base58M
 
 	return metadata, nil
 }
+
+// GetMintDecimals returns the decimals of the SPL Token or Token-2022 mint.
+// Accounts not owned by the token programs and token accounts are rejected.
+// Decimals of a mint never change, so the result is cached.
+func (c *SolanaClientCacheWrapper) GetMintDecimals(ctx context.Context, base58MintAddr string) (uint8, error) {
+	key := "mint_decimals:" + base58MintAddr
+
+	var decimals uint8
+	if c.cache.Exists(ctx, key) {
+		if err := c.cache.Get(ctx, key, &decimals); err == nil {
+			return decimals, nil
+		}
+	}
+
+	accInfo, err := c.Client.Solana().GetAccountInfo(ctx, base58MintAddr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get mint account info: %w", err)
+	}
+	decimals, ok := solanatx.ParseMintDecimals(castAccountInfo(accInfo))
+	if !ok {
+		return 0, fmt.Errorf("account %s is not a token mint", base58MintAddr)
+	}
+
+	// cache the result, ignore error, bc it is not critical
+	c.cache.Set(&cache.Item{
+		Ctx:   ctx,
+		Key:   key,
+		Value: decimals,
+		TTL:   c.ttl,
+	})
+
+	return decimals, nil
+}
+
+// GetTokenAccountMint returns the mint address of the SPL Token or Token-2022 account.
+// Accounts not owned by the token programs and mints are rejected.
+// The result is not cached: a token account may be closed and re-initialized with another mint.
+func (c *SolanaClientCacheWrapper) GetTokenAccountMint(ctx context.Context, base58Addr string) (string, error) {
+	accInfo, err := c.Client.Solana().GetAccountInfo(ctx, base58Addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to get token account info: %w", err)
+	}
+	acc, ok := solanatx.ParseTokenAccount(castAccountInfo(accInfo))
+	if !ok {
+		return "", fmt.Errorf("account %s is not a token account", base58Addr)
+	}
+
+	return acc.Mint, nil
+}
+
+// castAccountInfo converts the RPC account info to the account state
+func castAccountInfo(info sdkclient.AccountInfo) *solanatx.AccountState {
+	return &solanatx.AccountState{
+		Lamports: info.Lamports,
+		Owner:    info.Owner.ToBase58(),
+		Data:     info.Data,
+	}
+}
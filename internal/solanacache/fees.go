@@ -0,0 +1,34 @@
+package solanacache
+
+import (
+	"context"
+	"fmt"
+)
+
+type getRecentPrioritizationFeesResponse struct {
+	Error  *rpcError `json:"error"`
+	Result []struct {
+		Slot              uint64 `json:"slot"`
+		PrioritizationFee uint64 `json:"prioritizationFee"`
+	} `json:"result"`
+}
+
+// GetRecentPrioritizationFees returns the prioritization fees of the recent slots
+// paid by the transactions locking the given writable accounts, in micro-lamports per compute unit.
+// The method is missing in the solana-go-sdk, so the RPC node is called directly.
+func (c *SolanaClientCacheWrapper) GetRecentPrioritizationFees(ctx context.Context, addresses []string) ([]uint64, error) {
+	var resp getRecentPrioritizationFeesResponse
+	if err := c.call(ctx, &resp, "getRecentPrioritizationFees", addresses); err != nil {
+		return nil, fmt.Errorf("failed to get recent prioritization fees: %w", err)
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("failed to get recent prioritization fees: rpc error %d: %s", resp.Error.Code, resp.Error.Message)
+	}
+
+	fees := make([]uint64, 0, len(resp.Result))
+	for _, f := range resp.Result {
+		fees = append(fees, f.PrioritizationFee)
+	}
+
+	return fees, nil
+}
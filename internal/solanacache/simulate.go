@@ -0,0 +1,150 @@
+package solanacache
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+)
+
+// Commitment level of the simulation and the account states it's compared with
+const simulationCommitment = "confirmed"
+
+type (
+	rpcError struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+
+	rpcAccount struct {
+		Lamports uint64   `json:"lamports"`
+		Owner    string   `json:"owner"`
+		Data     []string `json:"data"` // ["<base64 data>", "base64"]
+	}
+
+	simulateTransactionResponse struct {
+		Error  *rpcError `json:"error"`
+		Result struct {
+			Value struct {
+				Err           interface{}   `json:"err"`
+				Logs          []string      `json:"logs"`
+				UnitsConsumed uint64        `json:"unitsConsumed"`
+				Accounts      []*rpcAccount `json:"accounts"`
+			} `json:"value"`
+		} `json:"result"`
+	}
+
+	getMultipleAccountsResponse struct {
+		Error  *rpcError `json:"error"`
+		Result struct {
+			Value []*rpcAccount `json:"value"`
+		} `json:"result"`
+	}
+)
+
+// SimulateTransaction simulates the base64 encoded transaction without the signatures verification.
+// Returns the simulation result without the balance changes and the post-transaction states of the given accounts.
+// The solana-go-sdk simulation result has no consumed compute units, so the RPC node is called directly.
+func (c *SolanaClientCacheWrapper) SimulateTransaction(ctx context.Context, base64Tx string, addresses []string) (solanatx.Simulation, []*solanatx.AccountState, error) {
+	cfg := map[string]interface{}{
+		"encoding":   "base64",
+		"commitment": simulationCommitment,
+		"sigVerify":  false,
+	}
+	if len(addresses) > 0 {
+		cfg["accounts"] = map[string]interface{}{
+			"encoding":  "base64",
+			"addresses": addresses,
+		}
+	}
+
+	var resp simulateTransactionResponse
+	if err := c.call(ctx, &resp, "simulateTransaction", base64Tx, cfg); err != nil {
+		return solanatx.Simulation{}, nil, fmt.Errorf("failed to simulate transaction: %w", err)
+	}
+	if resp.Error != nil {
+		return solanatx.Simulation{}, nil, fmt.Errorf("failed to simulate transaction: rpc error %d: %s", resp.Error.Code, resp.Error.Message)
+	}
+
+	accounts, err := castAccountStates(resp.Result.Value.Accounts)
+	if err != nil {
+		return solanatx.Simulation{}, nil, fmt.Errorf("failed to simulate transaction: %w", err)
+	}
+
+	return solanatx.Simulation{
+		Success:       resp.Result.Value.Err == nil,
+		Err:           resp.Result.Value.Err,
+		Logs:          resp.Result.Value.Logs,
+		UnitsConsumed: resp.Result.Value.UnitsConsumed,
+	}, accounts, nil
+}
+
+// GetAccountStates returns the current states of the given accounts, nil for the missing ones
+func (c *SolanaClientCacheWrapper) GetAccountStates(ctx context.Context, addresses []string) ([]*solanatx.AccountState, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+
+	cfg := map[string]interface{}{
+		"encoding":   "base64",
+		"commitment": simulationCommitment,
+	}
+
+	var resp getMultipleAccountsResponse
+	if err := c.call(ctx, &resp, "getMultipleAccounts", addresses, cfg); err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("failed to get accounts: rpc error %d: %s", resp.Error.Code, resp.Error.Message)
+	}
+
+	states, err := castAccountStates(resp.Result.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+
+	return states, nil
+}
+
+// call calls the RPC method and decodes the response body into the result
+func (c *SolanaClientCacheWrapper) call(ctx context.Context, result interface{}, params ...interface{}) error {
+	body, err := c.Client.Solana().RpcClient.Call(ctx, params...)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to decode rpc response: %w", err)
+	}
+
+	return nil
+}
+
+// castAccountStates converts the RPC accounts to the account states
+func castAccountStates(accounts []*rpcAccount) ([]*solanatx.AccountState, error) {
+	result := make([]*solanatx.AccountState, 0, len(accounts))
+	for _, acc := range accounts {
+		if acc == nil {
+			result = append(result, nil)
+			continue
+		}
+
+		state := &solanatx.AccountState{
+			Lamports: acc.Lamports,
+			Owner:    acc.Owner,
+		}
+		if len(acc.Data) > 0 {
+			data, err := base64.StdEncoding.DecodeString(acc.Data[0])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode account data: %w", err)
+			}
+			state.Data = data
+		}
+
+		result = append(result, state)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package solanacache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/portto/solana-go-sdk/client"
+	"github.com/portto/solana-go-sdk/rpc"
+)
+
+// GetSignatureStatuses returns the statuses of the given transaction signatures, nil for the unknown ones.
+// The transaction history is searched, so the statuses of the transactions
+// which are out of the recent status cache are returned as well.
+func (c *SolanaClientCacheWrapper) GetSignatureStatuses(ctx context.Context, signatures []string) ([]*rpc.SignatureStatus, error) {
+	statuses, err := c.Client.Solana().GetSignatureStatusesWithConfig(ctx, signatures, rpc.GetSignatureStatusesConfig{
+		SearchTransactionHistory: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signature statuses: %w", err)
+	}
+
+	return statuses, nil
+}
+
+// IsBlockhashValid reports whether the blockhash is still valid,
+// i.e. a transaction with this recent blockhash can still be included in a block.
+// The processed commitment is used, so the blockhashes of the recent blocks, which are not finalized yet, are valid.
+func (c *SolanaClientCacheWrapper) IsBlockhashValid(ctx context.Context, blockhash string) (bool, error) {
+	valid, err := c.Client.Solana().IsBlockhashValidWithConfig(ctx, blockhash, client.IsBlockhashValidConfig{
+		Commitment: rpc.CommitmentProcessed,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check blockhash: %w", err)
+	}
+
+	return valid, nil
+}
@@ -0,0 +1,141 @@
+package solanatx
+
+import (
+	"encoding/binary"
+
+	"github.com/portto/solana-go-sdk/common"
+)
+
+// Token and mint account layouts, the same for SPL Token and Token-2022 accounts.
+// Token-2022 accounts with extensions have the account type byte right after the base token account layout.
+const (
+	TokenAccountSize = 165
+
+	tokenAccountOwnerOffset  = 32
+	tokenAccountAmountOffset = 64
+	tokenAccountTypeOffset   = TokenAccountSize
+	tokenAccountTypeAccount  = 2
+
+	mintSize           = 82
+	mintDecimalsOffset = 44
+)
+
+// Nonce account layout: version u32, state u32, authority, nonce, lamports per signature u64
+const (
+	NonceAccountSize = 80
+
+	nonceStateOffset      = 4
+	nonceStateInitialized = 1
+	nonceAuthorityOffset  = 8
+	nonceValueOffset      = 40
+	nonceFeeOffset        = 72
+)
+
+// Address lookup table layout: state u32, the metadata padded to the fixed size, then the addresses.
+// The metadata size doesn't depend on whether the table has the authority or is frozen.
+const (
+	lookupTableStateInitialized = 1
+	lookupTableMetaSize         = 56
+)
+
+type (
+	// AccountState is the account state before or after the transaction.
+	// Nil state means the account does not exist.
+	AccountState struct {
+		Lamports uint64
+		Owner    string // owner program id
+		Data     []byte
+	}
+
+	// NonceAccount is the decoded initialized durable nonce account
+	NonceAccount struct {
+		Authority            string
+		Nonce                string // the blockhash to use in the durable transaction
+		LamportsPerSignature uint64
+	}
+
+	// TokenAccount is the decoded SPL Token or Token-2022 account
+	TokenAccount struct {
+		Program string // token program id
+		Mint    string
+		Owner   string
+		Amount  uint64
+	}
+)
+
+// ParseTokenAccount decodes the SPL Token or Token-2022 account state.
+// Returns false if the account does not exist or is not a token account.
+func ParseTokenAccount(state *AccountState) (TokenAccount, bool) {
+	if state == nil || len(state.Data) < TokenAccountSize {
+		return TokenAccount{}, false
+	}
+	if !IsTokenProgram(common.PublicKeyFromString(state.Owner)) {
+		return TokenAccount{}, false
+	}
+	if len(state.Data) > tokenAccountTypeOffset && state.Data[tokenAccountTypeOffset] != tokenAccountTypeAccount {
+		return TokenAccount{}, false // Token-2022 mint with extensions
+	}
+
+	return TokenAccount{
+		Program: state.Owner,
+		Mint:    common.PublicKeyFromBytes(state.Data[:tokenAccountOwnerOffset]).ToBase58(),
+		Owner:   common.PublicKeyFromBytes(state.Data[tokenAccountOwnerOffset:tokenAccountAmountOffset]).ToBase58(),
+		Amount:  binary.LittleEndian.Uint64(state.Data[tokenAccountAmountOffset : tokenAccountAmountOffset+8]),
+	}, true
+}
+
+// ParseMintDecimals returns the decimals of the SPL Token or Token-2022 mint account state.
+// Returns false if the account does not exist or is not a mint.
+func ParseMintDecimals(state *AccountState) (uint8, bool) {
+	if state == nil || len(state.Data) < mintSize {
+		return 0, false
+	}
+	if !IsTokenProgram(common.PublicKeyFromString(state.Owner)) {
+		return 0, false
+	}
+	if len(state.Data) == TokenAccountSize ||
+		(len(state.Data) > tokenAccountTypeOffset && state.Data[tokenAccountTypeOffset] == tokenAccountTypeAccount) {
+		return 0, false // token account
+	}
+
+	return state.Data[mintDecimalsOffset], true
+}
+
+// ParseNonceAccount decodes the durable nonce account state.
+// Returns false if the account does not exist, is not a nonce account or is not initialized.
+func ParseNonceAccount(state *AccountState) (NonceAccount, bool) {
+	if state == nil || len(state.Data) < NonceAccountSize || state.Owner != common.SystemProgramID.ToBase58() {
+		return NonceAccount{}, false
+	}
+	if binary.LittleEndian.Uint32(state.Data[nonceStateOffset:nonceAuthorityOffset]) != nonceStateInitialized {
+		return NonceAccount{}, false
+	}
+
+	return NonceAccount{
+		Authority:            common.PublicKeyFromBytes(state.Data[nonceAuthorityOffset:nonceValueOffset]).ToBase58(),
+		Nonce:                common.PublicKeyFromBytes(state.Data[nonceValueOffset:nonceFeeOffset]).ToBase58(),
+		LamportsPerSignature: binary.LittleEndian.Uint64(state.Data[nonceFeeOffset:NonceAccountSize]),
+	}, true
+}
+
+// ParseLookupTable decodes the address lookup table account, returns the stored addresses.
+// Returns false if the account does not exist or is not an initialized lookup table.
+func ParseLookupTable(state *AccountState) ([]string, bool) {
+	if state == nil || len(state.Data) < lookupTableMetaSize || state.Owner != common.AddressLookupTableProgramID.ToBase58() {
+		return nil, false
+	}
+	if binary.LittleEndian.Uint32(state.Data[:4]) != lookupTableStateInitialized {
+		return nil, false
+	}
+
+	data := state.Data[lookupTableMetaSize:]
+	if len(data)%common.PublicKeyLength != 0 {
+		return nil, false
+	}
+
+	result := make([]string, 0, len(data)/common.PublicKeyLength)
+	for i := 0; i < len(data); i += common.PublicKeyLength {
+		result = append(result, common.PublicKeyFromBytes(data[i:i+common.PublicKeyLength]).ToBase58())
+	}
+	return result, true
+}
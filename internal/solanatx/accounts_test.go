@@ -0,0 +1,166 @@
+package solanatx_test
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/system"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTokenAccount(t *testing.T) {
+	mint := types.NewAccount().PublicKey
+	owner := types.NewAccount().PublicKey
+
+	acc, ok := solanatx.ParseTokenAccount(tokenAccount(mint, owner, 1_000))
+	require.True(t, ok)
+	require.Equal(t, solanatx.TokenAccount{
+		Program: common.TokenProgramID.ToBase58(),
+		Mint:    mint.ToBase58(),
+		Owner:   owner.ToBase58(),
+		Amount:  1_000,
+	}, acc)
+
+	// Token-2022 account with the immutable owner extension
+	state := tokenAccount(mint, owner, 5)
+	state.Owner = solanatx.Token2022ProgramID.ToBase58()
+	state.Data = append(state.Data, 2, 7, 0, 0, 0)
+	acc, ok = solanatx.ParseTokenAccount(state)
+	require.True(t, ok)
+	require.Equal(t, uint64(5), acc.Amount)
+
+	_, ok = solanatx.ParseTokenAccount(nil)
+	require.False(t, ok)
+	_, ok = solanatx.ParseTokenAccount(&solanatx.AccountState{Owner: common.SystemProgramID.ToBase58()})
+	require.False(t, ok)
+	_, ok = solanatx.ParseTokenAccount(mintAccount(common.TokenProgramID, 6))
+	require.False(t, ok)
+}
+
+func TestParseMintDecimals(t *testing.T) {
+	decimals, ok := solanatx.ParseMintDecimals(mintAccount(common.TokenProgramID, 6))
+	require.True(t, ok)
+	require.Equal(t, uint8(6), decimals)
+
+	decimals, ok = solanatx.ParseMintDecimals(mintAccount(solanatx.Token2022ProgramID, 9))
+	require.True(t, ok)
+	require.Equal(t, uint8(9), decimals)
+
+	_, ok = solanatx.ParseMintDecimals(nil)
+	require.False(t, ok)
+	_, ok = solanatx.ParseMintDecimals(tokenAccount(types.NewAccount().PublicKey, types.NewAccount().PublicKey, 1))
+	require.False(t, ok)
+}
+
+func mintAccount(program common.PublicKey, decimals uint8) *solanatx.AccountState {
+	data := make([]byte, 82)
+	data[44] = decimals
+	data[45] = 1 // initialized
+
+	return &solanatx.AccountState{
+		Lamports: 1_461_600,
+		Owner:    program.ToBase58(),
+		Data:     data,
+	}
+}
+
+func TestParseNonceAccount(t *testing.T) {
+	authority := types.NewAccount().PublicKey
+	nonce := types.NewAccount().PublicKey
+
+	data := make([]byte, solanatx.NonceAccountSize)
+	binary.LittleEndian.PutUint32(data[0:4], 1) // version
+	binary.LittleEndian.PutUint32(data[4:8], 1) // initialized
+	copy(data[8:40], authority.Bytes())
+	copy(data[40:72], nonce.Bytes())
+	binary.LittleEndian.PutUint64(data[72:80], 5_000)
+	state := &solanatx.AccountState{Lamports: 1_447_680, Owner: common.SystemProgramID.ToBase58(), Data: data}
+
+	acc, ok := solanatx.ParseNonceAccount(state)
+	require.True(t, ok)
+	require.Equal(t, solanatx.NonceAccount{
+		Authority:            authority.ToBase58(),
+		Nonce:                nonce.ToBase58(),
+		LamportsPerSignature: 5_000,
+	}, acc)
+
+	uninitialized := &solanatx.AccountState{Owner: common.SystemProgramID.ToBase58(), Data: make([]byte, solanatx.NonceAccountSize)}
+	_, ok = solanatx.ParseNonceAccount(uninitialized)
+	require.False(t, ok)
+	_, ok = solanatx.ParseNonceAccount(nil)
+	require.False(t, ok)
+	_, ok = solanatx.ParseNonceAccount(&solanatx.AccountState{Owner: common.TokenProgramID.ToBase58(), Data: data})
+	require.False(t, ok)
+}
+
+func TestDurableNonceAccount(t *testing.T) {
+	feePayer := types.NewAccount()
+	nonceAccount := types.NewAccount().PublicKey
+	transfer := system.Transfer(system.TransferParam{From: feePayer.PublicKey, To: types.NewAccount().PublicKey, Amount: 1})
+
+	decode := func(instructions ...types.Instruction) solanatx.Transaction {
+		tx, err := types.NewTransaction(types.NewTransactionParam{
+			Message: types.NewMessage(types.NewMessageParam{
+				FeePayer:        feePayer.PublicKey,
+				RecentBlockhash: "9rAtxuhtKn8qagc3UtZFyhLrw5zgh6etCLnm3zGTSuC8",
+				Instructions:    instructions,
+			}),
+			Signers: []types.Account{feePayer},
+		})
+		require.NoError(t, err)
+		txb, err := tx.Serialize()
+		require.NoError(t, err)
+		decoded, err := solanatx.Decode(base64.StdEncoding.EncodeToString(txb))
+		require.NoError(t, err)
+		return decoded
+	}
+
+	advance := system.AdvanceNonceAccount(system.AdvanceNonceAccountParam{Nonce: nonceAccount, Auth: feePayer.PublicKey})
+
+	addr, ok := decode(advance, transfer).DurableNonceAccount()
+	require.True(t, ok)
+	require.Equal(t, nonceAccount.ToBase58(), addr)
+
+	_, ok = decode(transfer, advance).DurableNonceAccount()
+	require.False(t, ok, "the nonce must be advanced in the first instruction")
+}
+
+func TestParseLookupTable(t *testing.T) {
+	addresses := []common.PublicKey{types.NewAccount().PublicKey, types.NewAccount().PublicKey}
+	lookupTable := func(authority *common.PublicKey) *solanatx.AccountState {
+		data := make([]byte, 56)
+		binary.LittleEndian.PutUint32(data, 1)
+		if authority != nil {
+			data[21] = 1
+			copy(data[22:54], authority[:])
+		}
+		for _, addr := range addresses {
+			data = append(data, addr[:]...)
+		}
+		return &solanatx.AccountState{Owner: common.AddressLookupTableProgramID.ToBase58(), Data: data}
+	}
+	expected := []string{addresses[0].ToBase58(), addresses[1].ToBase58()}
+
+	authority := types.NewAccount().PublicKey
+	table, ok := solanatx.ParseLookupTable(lookupTable(&authority))
+	require.True(t, ok)
+	require.Equal(t, expected, table)
+
+	// frozen table without the authority has the same layout
+	table, ok = solanatx.ParseLookupTable(lookupTable(nil))
+	require.True(t, ok)
+	require.Equal(t, expected, table)
+
+	_, ok = solanatx.ParseLookupTable(nil)
+	require.False(t, ok)
+	_, ok = solanatx.ParseLookupTable(&solanatx.AccountState{Owner: common.AddressLookupTableProgramID.ToBase58(), Data: make([]byte, 56)})
+	require.False(t, ok, "uninitialized table")
+	state := lookupTable(nil)
+	state.Owner = common.SystemProgramID.ToBase58()
+	_, ok = solanatx.ParseLookupTable(state)
+	require.False(t, ok)
+}
@@ -0,0 +1,97 @@
+package solanatx
+
+import (
+	"sort"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/compute_budget"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// Compute budget limits of the cluster
+const (
+	DefaultInstructionComputeUnits = 200_000   // default limit per instruction
+	MaxComputeUnitLimit            = 1_400_000 // max limit per transaction
+)
+
+const microLamportsPerLamport = 1_000_000
+
+// ComputeBudgetInstructions returns the compute budget instructions setting the given unit limit
+// and the unit price in micro-lamports, zero values are skipped
+func ComputeBudgetInstructions(unitLimit uint32, unitPrice uint64) []types.Instruction {
+	var instructions []types.Instruction
+	if unitLimit > 0 {
+		instructions = append(instructions, compute_budget.SetComputeUnitLimit(compute_budget.SetComputeUnitLimitParam{
+			Units: unitLimit,
+		}))
+	}
+	if unitPrice > 0 {
+		instructions = append(instructions, compute_budget.SetComputeUnitPrice(compute_budget.SetComputeUnitPriceParam{
+			MicroLamports: unitPrice,
+		}))
+	}
+	return instructions
+}
+
+// DefaultComputeUnitLimit returns the compute unit limit the cluster applies to the transaction
+// without the explicit limit: the default limit per each instruction, except the compute budget ones
+func DefaultComputeUnitLimit(instructions []types.Instruction) uint32 {
+	var limit uint32
+	for _, ins := range instructions {
+		if ins.ProgramID != common.ComputeBudgetProgramID {
+			limit += DefaultInstructionComputeUnits
+		}
+	}
+	if limit > MaxComputeUnitLimit {
+		limit = MaxComputeUnitLimit
+	}
+	return limit
+}
+
+// PriorityFee returns the prioritization fee in lamports paid for the given unit limit and price,
+// rounded up as the cluster does
+func PriorityFee(unitLimit uint32, unitPrice uint64) uint64 {
+	microLamports := uint64(unitLimit) * unitPrice
+	return (microLamports + microLamportsPerLamport - 1) / microLamportsPerLamport
+}
+
+// EstimateComputeUnitPrice returns the given nearest-rank percentile (0-100) of the recent prioritization fees,
+// zero if there are no fees
+func EstimateComputeUnitPrice(fees []uint64, percentile int) uint64 {
+	if len(fees) == 0 {
+		return 0
+	}
+	if percentile < 0 {
+		percentile = 0
+	}
+	if percentile > 100 {
+		percentile = 100
+	}
+
+	sorted := append([]uint64(nil), fees...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	// nearest-rank percentile
+	idx := (len(sorted)*percentile+99)/100 - 1
+	if idx < 0 {
+		idx = 0
+	}
+
+	return sorted[idx]
+}
+
+// InstructionsWritableAccounts returns the unique writable accounts of the instructions
+func InstructionsWritableAccounts(instructions []types.Instruction) []string {
+	seen := make(map[common.PublicKey]bool)
+	var result []string
+	for _, ins := range instructions {
+		for _, acc := range ins.Accounts {
+			if !acc.IsWritable || seen[acc.PubKey] {
+				continue
+			}
+			seen[acc.PubKey] = true
+			result = append(result, acc.PubKey.ToBase58())
+		}
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package solanatx_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/system"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateComputeUnitPrice(t *testing.T) {
+	fees := []uint64{0, 500, 100, 0, 10_000, 2_000, 0, 1_000, 0, 50}
+
+	tests := []struct {
+		name       string
+		fees       []uint64
+		percentile int
+		want       uint64
+	}{
+		{"no fees", nil, 75, 0},
+		{"min", fees, 0, 0},
+		{"median", fees, 50, 50},
+		{"75th percentile", fees, 75, 1_000},
+		{"max", fees, 100, 10_000},
+		{"percentile above 100", fees, 150, 10_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, solanatx.EstimateComputeUnitPrice(tt.fees, tt.percentile))
+		})
+	}
+
+	require.Equal(t, []uint64{0, 500, 100, 0, 10_000, 2_000, 0, 1_000, 0, 50}, fees, "fees must not be sorted in place")
+}
+
+func TestComputeBudget(t *testing.T) {
+	from := types.NewAccount().PublicKey
+	transfer := system.Transfer(system.TransferParam{From: from, To: types.NewAccount().PublicKey, Amount: 1})
+
+	require.Empty(t, solanatx.ComputeBudgetInstructions(0, 0))
+
+	budget := solanatx.ComputeBudgetInstructions(300_000, 10_000)
+	require.Len(t, budget, 2)
+	for _, ins := range budget {
+		require.Equal(t, common.ComputeBudgetProgramID, ins.ProgramID)
+	}
+
+	instructions := append(budget, transfer, transfer)
+	require.Equal(t, uint32(400_000), solanatx.DefaultComputeUnitLimit(instructions))
+	require.Equal(t, uint32(solanatx.MaxComputeUnitLimit), solanatx.DefaultComputeUnitLimit(make([]types.Instruction, 10)))
+
+	require.Equal(t, uint64(0), solanatx.PriorityFee(200_000, 0))
+	require.Equal(t, uint64(1), solanatx.PriorityFee(200_000, 1))
+	require.Equal(t, uint64(2_000), solanatx.PriorityFee(200_000, 10_000))
+
+	require.Len(t, solanatx.InstructionsWritableAccounts(instructions), 2)
+}
+
+func TestDecodeComputeBudget(t *testing.T) {
+	feePayer := types.NewAccount()
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: types.NewMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: "9rAtxuhtKn8qagc3UtZFyhLrw5zgh6etCLnm3zGTSuC8",
+			Instructions:    solanatx.ComputeBudgetInstructions(300_000, 10_000),
+		}),
+		Signers: []types.Account{feePayer},
+	})
+	require.NoError(t, err)
+	txb, err := tx.Serialize()
+	require.NoError(t, err)
+
+	decoded, err := solanatx.Decode(base64.StdEncoding.EncodeToString(txb))
+	require.NoError(t, err)
+	require.Len(t, decoded.Instructions, 2)
+	require.Equal(t, "set_compute_unit_limit", decoded.Instructions[0].Type)
+	require.Equal(t, "set_compute_unit_price", decoded.Instructions[1].Type)
+}
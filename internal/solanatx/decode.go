@@ -0,0 +1,429 @@
+package solanatx
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/dmitrymomot/solana/types"
+	"github.com/portto/solana-go-sdk/common"
+	sdktypes "github.com/portto/solana-go-sdk/types"
+)
+
+// Native SOL pseudo mint used in the transfer description
+const nativeSymbol = "SOL"
+
+type (
+	// Transaction is a human readable representation of the transaction message
+	Transaction struct {
+		Version             string               `json:"version"`
+		FeePayer            string               `json:"fee_payer"`
+		RecentBlockhash     string               `json:"recent_blockhash"`
+		Signers             []string             `json:"signers"`
+		Instructions        []Instruction        `json:"instructions"`
+		AddressTableLookups []AddressTableLookup `json:"address_table_lookups,omitempty"` // v0 messages only
+	}
+
+	// AddressTableLookup is the address lookup table referenced by the v0 message
+	// and the indexes of the addresses loaded from it
+	AddressTableLookup struct {
+		Account         string `json:"account"`
+		WritableIndexes []int  `json:"writable_indexes"`
+		ReadonlyIndexes []int  `json:"readonly_indexes"`
+	}
+
+	// LookupTables are the addresses stored in the address lookup tables, by the table address
+	LookupTables map[string][]string
+
+	// Instruction is a decoded transaction instruction.
+	// Type and Transfer are set for the known instructions only.
+	Instruction struct {
+		ProgramID   string    `json:"program_id"`
+		ProgramName string    `json:"program_name,omitempty"`
+		Type        string    `json:"type,omitempty"`
+		Accounts    []string  `json:"accounts"`
+		Data        string    `json:"data,omitempty"` // base64 encoded raw instruction data
+		Transfer    *Transfer `json:"transfer,omitempty"`
+		Description string    `json:"description,omitempty"`
+	}
+
+	// Transfer is a decoded SOL or SPL token transfer, SOL moved by account creation and nonce withdrawals included.
+	// Mint is empty for SOL transfers and for token transfers whose mint is not resolved yet.
+	// Amount decimals are unknown (zero) until they are resolved with SetDecimals,
+	// except for SOL and transfer_checked instructions.
+	Transfer struct {
+		Source      string            `json:"source"`
+		Destination string            `json:"destination"`
+		Authority   string            `json:"authority"`
+		IsNative    bool              `json:"is_native"`
+		Mint        string            `json:"mint,omitempty"`
+		Symbol      string            `json:"symbol,omitempty"`
+		Amount      types.TokenAmount `json:"amount"`
+		resolved    bool
+		mintChecked bool
+	}
+)
+
+// Decode decodes the base64 encoded legacy or v0 transaction.
+// Accounts loaded from address lookup tables are returned as references to the lookup table entries,
+// use DecodeWithLookupTables to resolve them.
+func Decode(base64Tx string) (Transaction, error) {
+	return DecodeWithLookupTables(base64Tx, nil)
+}
+
+// DecodeWithLookupTables decodes the base64 encoded legacy or v0 transaction
+// resolving the accounts loaded from the given address lookup tables
+func DecodeWithLookupTables(base64Tx string, tables LookupTables) (Transaction, error) {
+	tx, err := deserialize(base64Tx)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	return DecodeMessageWithLookupTables(tx.Message, tables)
+}
+
+// DecodeMessage decodes the transaction message
+func DecodeMessage(msg sdktypes.Message) (Transaction, error) {
+	return DecodeMessageWithLookupTables(msg, nil)
+}
+
+// DecodeMessageWithLookupTables decodes the transaction message
+// resolving the accounts loaded from the given address lookup tables.
+// The tables missing from the given ones are left unresolved.
+func DecodeMessageWithLookupTables(msg sdktypes.Message, tables LookupTables) (Transaction, error) {
+	if len(msg.Accounts) == 0 || int(msg.Header.NumRequireSignatures) > len(msg.Accounts) {
+		return Transaction{}, fmt.Errorf("%w: malformed message header", ErrInvalidTransaction)
+	}
+
+	keys, err := accountKeys(msg, tables)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	version := string(msg.Version)
+	if version == "" {
+		version = sdktypes.MessageVersionLegacy
+	}
+
+	result := Transaction{
+		Version:         version,
+		FeePayer:        msg.Accounts[0].ToBase58(),
+		RecentBlockhash: msg.RecentBlockHash,
+		Signers:         make([]string, 0, msg.Header.NumRequireSignatures),
+		Instructions:    make([]Instruction, 0, len(msg.Instructions)),
+	}
+	for _, acc := range msg.Accounts[:msg.Header.NumRequireSignatures] {
+		result.Signers = append(result.Signers, acc.ToBase58())
+	}
+	for _, l := range msg.AddressLookupTables {
+		result.AddressTableLookups = append(result.AddressTableLookups, AddressTableLookup{
+			Account:         l.AccountKey.ToBase58(),
+			WritableIndexes: tableIndexes(l.WritableIndexes),
+			ReadonlyIndexes: tableIndexes(l.ReadonlyIndexes),
+		})
+	}
+
+	for i, ci := range msg.Instructions {
+		if ci.ProgramIDIndex < 0 || ci.ProgramIDIndex >= len(msg.Accounts) {
+			return Transaction{}, fmt.Errorf("%w: instruction %d: program id index is out of range", ErrInvalidTransaction, i)
+		}
+		programID := msg.Accounts[ci.ProgramIDIndex]
+
+		accounts := make([]string, 0, len(ci.Accounts))
+		for _, idx := range ci.Accounts {
+			accounts = append(accounts, accountKey(keys, idx))
+		}
+
+		ins := Instruction{
+			ProgramID:   programID.ToBase58(),
+			ProgramName: ProgramName(programID),
+			Accounts:    accounts,
+		}
+		if len(ci.Data) > 0 {
+			ins.Data = base64.StdEncoding.EncodeToString(ci.Data)
+		}
+
+		switch {
+		case programID == common.SystemProgramID:
+			decodeSystemInstruction(&ins, ci.Data)
+		case IsTokenProgram(programID):
+			decodeTokenInstruction(&ins, ci.Data)
+		case programID == common.MetaplexTokenMetaProgramID:
+			decodeTokenMetadataInstruction(&ins, ci.Data)
+		case programID == common.ComputeBudgetProgramID && len(ci.Data) > 0 && int(ci.Data[0]) < len(computeBudgetInstructions):
+			ins.Type = computeBudgetInstructions[ci.Data[0]]
+		}
+
+		result.Instructions = append(result.Instructions, ins)
+	}
+
+	return result, nil
+}
+
+// DurableNonceAccount returns the nonce account of the durable transaction,
+// i.e. the transaction which advances the nonce account in the first instruction
+// and uses the nonce as the recent blockhash
+func (tx Transaction) DurableNonceAccount() (string, bool) {
+	if len(tx.Instructions) == 0 {
+		return "", false
+	}
+
+	ins := tx.Instructions[0]
+	if ins.ProgramID != common.SystemProgramID.ToBase58() || ins.Type != "advance_nonce_account" || len(ins.Accounts) == 0 {
+		return "", false
+	}
+
+	return ins.Accounts[0], true
+}
+
+// SetDecimals sets the token decimals and the optional symbol, and updates the instruction description
+func (ins *Instruction) SetDecimals(decimals uint8, symbol string) {
+	if ins.Transfer == nil {
+		return
+	}
+
+	ins.Transfer.Amount = types.NewTokenAmountFromLamports(ins.Transfer.Amount.Amount, decimals)
+	ins.Transfer.Symbol = symbol
+	ins.Transfer.resolved = true
+	ins.Description = ins.Transfer.String()
+}
+
+// Resolved reports whether the transfer amount decimals are known
+func (t *Transfer) Resolved() bool {
+	return t.resolved
+}
+
+// MintChecked reports whether the token mint is passed to the instruction and verified by the program,
+// like in transfer_checked. The mint of a plain transfer is looked up from the source account,
+// which may be closed and re-initialized with another mint before the transaction lands.
+func (t *Transfer) MintChecked() bool {
+	return t.mintChecked
+}
+
+// String returns the transfer description in the "source → destination → amount" form
+func (t *Transfer) String() string {
+	amount := fmt.Sprintf("%d", t.Amount.Amount)
+	if t.resolved {
+		amount = t.Amount.UIAmountString
+	}
+
+	unit := t.Symbol
+	if unit == "" {
+		switch {
+		case t.IsNative:
+			unit = nativeSymbol
+		case t.Mint == "":
+			unit = "base units of unknown token"
+		case t.resolved:
+			unit = t.Mint
+		default:
+			unit = "base units of " + t.Mint
+		}
+	}
+
+	return fmt.Sprintf("%s → %s → %s %s", t.Source, t.Destination, amount, unit)
+}
+
+// decodeSystemInstruction decodes the system program instruction type and the instructions
+// moving SOL: transfers, account creation funded by the source and nonce account withdrawals
+func decodeSystemInstruction(ins *Instruction, data []byte) {
+	if len(data) < 4 {
+		return
+	}
+	kind := binary.LittleEndian.Uint32(data[:4])
+	if int(kind) < len(systemInstructions) {
+		ins.Type = systemInstructions[kind]
+	}
+
+	var source, destination, authority string
+	amountOffset := 4
+	switch ins.Type {
+	case "transfer", "create_account":
+		// accounts: from, to; data: lamports u64, ...
+		if len(ins.Accounts) < 2 {
+			return
+		}
+		source, destination = ins.Accounts[0], ins.Accounts[1]
+	case "transfer_with_seed":
+		// accounts: from, base, to; data: lamports u64, ...
+		if len(ins.Accounts) < 3 {
+			return
+		}
+		source, destination = ins.Accounts[0], ins.Accounts[2]
+	case "create_account_with_seed":
+		// accounts: from, to, base; data: base pubkey, seed string (u64 length prefixed), lamports u64, ...
+		if len(ins.Accounts) < 2 || len(data) < 44 {
+			return
+		}
+		seedLen := binary.LittleEndian.Uint64(data[36:44])
+		if seedLen > uint64(len(data)) {
+			return
+		}
+		source, destination = ins.Accounts[0], ins.Accounts[1]
+		amountOffset = 44 + int(seedLen)
+	case "withdraw_nonce_account":
+		// accounts: nonce, to, recent blockhashes sysvar, rent sysvar, authority; data: lamports u64
+		if len(ins.Accounts) < 5 {
+			return
+		}
+		source, destination, authority = ins.Accounts[0], ins.Accounts[1], ins.Accounts[4]
+	default:
+		return
+	}
+	if len(data) < amountOffset+8 {
+		return
+	}
+	if authority == "" {
+		authority = source
+	}
+
+	ins.Transfer = &Transfer{
+		Source:      source,
+		Destination: destination,
+		Authority:   authority,
+		IsNative:    true,
+		Amount:      types.NewDefaultTokenAmount(binary.LittleEndian.Uint64(data[amountOffset : amountOffset+8])),
+		resolved:    true,
+	}
+	ins.Description = ins.Transfer.String()
+}
+
+// decodeTokenInstruction decodes the token program instruction type and token transfers
+func decodeTokenInstruction(ins *Instruction, data []byte) {
+	if len(data) < 1 {
+		return
+	}
+	if int(data[0]) < len(tokenInstructions) {
+		ins.Type = tokenInstructions[data[0]]
+	}
+
+	switch ins.Type {
+	case "transfer":
+		// accounts: source, destination, authority; data: amount u64
+		if len(ins.Accounts) < 3 || len(data) < 9 {
+			return
+		}
+		ins.Transfer = &Transfer{
+			Source:      ins.Accounts[0],
+			Destination: ins.Accounts[1],
+			Authority:   ins.Accounts[2],
+			Amount:      types.TokenAmount{Amount: binary.LittleEndian.Uint64(data[1:9])},
+		}
+		ins.Description = ins.Transfer.String()
+	case "transfer_checked":
+		// accounts: source, mint, destination, authority; data: amount u64, decimals u8
+		if len(ins.Accounts) < 4 || len(data) < 10 {
+			return
+		}
+		ins.Transfer = &Transfer{
+			Source:      ins.Accounts[0],
+			Mint:        ins.Accounts[1],
+			Destination: ins.Accounts[2],
+			Authority:   ins.Accounts[3],
+		}
+		ins.Transfer.Amount.Amount = binary.LittleEndian.Uint64(data[1:9])
+		ins.Transfer.mintChecked = true
+		ins.SetDecimals(data[9], "")
+	}
+}
+
+// decodeTokenMetadataInstruction decodes the Token Metadata program transfer instruction used for pNFTs,
+// the other instructions are left undecoded
+func decodeTokenMetadataInstruction(ins *Instruction, data []byte) {
+	// accounts: source, source owner, destination, destination owner, mint, ..., authority (9);
+	// data: discriminator, transfer args version, amount u64, ...
+	if len(data) < 10 || data[0] != tokenMetadataInstructionTransfer || data[1] != tokenMetadataTransferV1 || len(ins.Accounts) < 10 {
+		return
+	}
+
+	ins.Type = "transfer"
+	ins.Transfer = &Transfer{
+		Source:      ins.Accounts[0],
+		Mint:        ins.Accounts[4],
+		Destination: ins.Accounts[2],
+		Authority:   ins.Accounts[9],
+	}
+	ins.Transfer.Amount.Amount = binary.LittleEndian.Uint64(data[2:10])
+	ins.Transfer.mintChecked = true
+	ins.SetDecimals(0, "") // pNFTs have no decimals
+}
+
+// accountKeys returns the base58 encoded account keys of the message in the order they are indexed
+// by the instructions: the static keys, then the writable and the readonly addresses loaded
+// from the lookup tables. Addresses of the tables missing from the given ones are empty.
+func accountKeys(msg sdktypes.Message, tables LookupTables) ([]string, error) {
+	keys := make([]string, 0, len(msg.Accounts))
+	for _, acc := range msg.Accounts {
+		keys = append(keys, acc.ToBase58())
+	}
+
+	var writable, readonly []string
+	for _, l := range msg.AddressLookupTables {
+		w, err := lookupAddresses(l, tables, l.WritableIndexes)
+		if err != nil {
+			return nil, err
+		}
+		r, err := lookupAddresses(l, tables, l.ReadonlyIndexes)
+		if err != nil {
+			return nil, err
+		}
+		writable, readonly = append(writable, w...), append(readonly, r...)
+	}
+
+	return append(append(keys, writable...), readonly...), nil
+}
+
+// lookupAddresses returns the addresses loaded from the lookup table by their indexes,
+// the addresses are empty if the table is missing from the given ones
+func lookupAddresses(l sdktypes.CompiledAddressLookupTable, tables LookupTables, indexes []uint8) ([]string, error) {
+	addresses, resolved := tables[l.AccountKey.ToBase58()]
+
+	result := make([]string, 0, len(indexes))
+	for _, idx := range indexes {
+		switch {
+		case !resolved:
+			result = append(result, "")
+		case int(idx) >= len(addresses):
+			return nil, fmt.Errorf("%w: lookup table %s has no address with index %d", ErrInvalidTransaction, l.AccountKey.ToBase58(), idx)
+		default:
+			result = append(result, addresses[idx])
+		}
+	}
+
+	return result, nil
+}
+
+// accountKey returns the base58 encoded account key by its index in the message.
+// Accounts loaded from the unresolved address lookup tables are returned
+// as a reference to the lookup table entry.
+func accountKey(keys []string, idx int) string {
+	if idx >= 0 && idx < len(keys) && keys[idx] != "" {
+		return keys[idx]
+	}
+
+	return fmt.Sprintf("lookup_table_account:%d", idx)
+}
+
+// tableIndexes converts the lookup table indexes, so they aren't encoded to JSON as bytes
+func tableIndexes(indexes []uint8) []int {
+	result := make([]int, 0, len(indexes))
+	for _, idx := range indexes {
+		result = append(result, int(idx))
+	}
+	return result
+}
+
+// deserialize decodes the base64 encoded legacy or v0 transaction
+func deserialize(base64Tx string) (sdktypes.Transaction, error) {
+	txb, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return sdktypes.Transaction{}, fmt.Errorf("%w: %s", ErrInvalidTransaction, err.Error())
+	}
+
+	tx, err := sdktypes.TransactionDeserialize(txb)
+	if err != nil {
+		return sdktypes.Transaction{}, fmt.Errorf("%w: %s", ErrInvalidTransaction, err.Error())
+	}
+
+	return tx, nil
+}
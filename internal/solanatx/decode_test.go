@@ -0,0 +1,269 @@
+package solanatx_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/memo"
+	"github.com/portto/solana-go-sdk/program/system"
+	"github.com/portto/solana-go-sdk/program/token"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	feePayer := types.NewAccount()
+	destination := types.NewAccount().PublicKey
+	sourceAta := types.NewAccount().PublicKey
+	destinationAta := types.NewAccount().PublicKey
+	mint := types.NewAccount().PublicKey
+	blockhash := "9rAtxuhtKn8qagc3UtZFyhLrw5zgh6etCLnm3zGTSuC8"
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: types.NewMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: blockhash,
+			Instructions: []types.Instruction{
+				system.Transfer(system.TransferParam{
+					From:   feePayer.PublicKey,
+					To:     destination,
+					Amount: 1_500_000_000,
+				}),
+				token.TransferChecked(token.TransferCheckedParam{
+					From:     sourceAta,
+					To:       destinationAta,
+					Mint:     mint,
+					Auth:     feePayer.PublicKey,
+					Amount:   2_500_000,
+					Decimals: 6,
+				}),
+				token.Transfer(token.TransferParam{
+					From:   sourceAta,
+					To:     destinationAta,
+					Auth:   feePayer.PublicKey,
+					Amount: 42,
+				}),
+				memo.BuildMemo(memo.BuildMemoParam{Memo: []byte("hello")}),
+			},
+		}),
+		Signers: []types.Account{feePayer},
+	})
+	require.NoError(t, err)
+
+	txb, err := tx.Serialize()
+	require.NoError(t, err)
+
+	result, err := solanatx.Decode(base64.StdEncoding.EncodeToString(txb))
+	require.NoError(t, err)
+	require.Equal(t, "legacy", result.Version)
+	require.Equal(t, feePayer.PublicKey.ToBase58(), result.FeePayer)
+	require.Equal(t, blockhash, result.RecentBlockhash)
+	require.Equal(t, []string{feePayer.PublicKey.ToBase58()}, result.Signers)
+	require.Len(t, result.Instructions, 4)
+
+	t.Run("sol transfer", func(t *testing.T) {
+		ins := result.Instructions[0]
+		require.Equal(t, "System Program", ins.ProgramName)
+		require.Equal(t, "transfer", ins.Type)
+		require.NotNil(t, ins.Transfer)
+		require.Equal(t, feePayer.PublicKey.ToBase58(), ins.Transfer.Source)
+		require.Equal(t, destination.ToBase58(), ins.Transfer.Destination)
+		require.True(t, ins.Transfer.IsNative)
+		require.Empty(t, ins.Transfer.Mint)
+		require.Equal(t, "1.5", ins.Transfer.Amount.UIAmountString)
+		require.Equal(t, feePayer.PublicKey.ToBase58()+" → "+destination.ToBase58()+" → 1.5 SOL", ins.Description)
+	})
+
+	t.Run("token transfer checked", func(t *testing.T) {
+		ins := result.Instructions[1]
+		require.Equal(t, "Token Program", ins.ProgramName)
+		require.Equal(t, "transfer_checked", ins.Type)
+		require.NotNil(t, ins.Transfer)
+		require.True(t, ins.Transfer.Resolved())
+		require.Equal(t, mint.ToBase58(), ins.Transfer.Mint)
+		require.Equal(t, uint8(6), ins.Transfer.Amount.Decimals)
+		require.Equal(t, "2.5", ins.Transfer.Amount.UIAmountString)
+	})
+
+	t.Run("token transfer", func(t *testing.T) {
+		ins := result.Instructions[2]
+		require.Equal(t, "transfer", ins.Type)
+		require.NotNil(t, ins.Transfer)
+		require.False(t, ins.Transfer.Resolved())
+		require.Equal(t, uint64(42), ins.Transfer.Amount.Amount)
+		require.Equal(t, sourceAta.ToBase58(), ins.Transfer.Source)
+		require.Equal(t, destinationAta.ToBase58(), ins.Transfer.Destination)
+
+		ins.Transfer.Mint = mint.ToBase58()
+		ins.SetDecimals(1, "TKN")
+		require.True(t, ins.Transfer.Resolved())
+		require.Equal(t, "4.2", ins.Transfer.Amount.UIAmountString)
+		require.Equal(t, sourceAta.ToBase58()+" → "+destinationAta.ToBase58()+" → 4.2 TKN", ins.Description)
+	})
+
+	t.Run("unknown instruction", func(t *testing.T) {
+		ins := result.Instructions[3]
+		require.Equal(t, common.MemoProgramID.ToBase58(), ins.ProgramID)
+		require.Equal(t, "Memo Program", ins.ProgramName)
+		require.Empty(t, ins.Type)
+		require.Nil(t, ins.Transfer)
+		require.Equal(t, base64.StdEncoding.EncodeToString([]byte("hello")), ins.Data)
+	})
+}
+
+func TestDecodeSystemFunding(t *testing.T) {
+	wallet := types.NewAccount()
+	newAccount := types.NewAccount()
+	destination := types.NewAccount().PublicKey
+	nonceAccount := common.CreateWithSeed(wallet.PublicKey, "nonce", common.SystemProgramID)
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: types.NewMessage(types.NewMessageParam{
+			FeePayer:        wallet.PublicKey,
+			RecentBlockhash: "9rAtxuhtKn8qagc3UtZFyhLrw5zgh6etCLnm3zGTSuC8",
+			Instructions: []types.Instruction{
+				system.CreateAccount(system.CreateAccountParam{
+					From:     wallet.PublicKey,
+					New:      newAccount.PublicKey,
+					Owner:    common.TokenProgramID,
+					Lamports: 2_039_280,
+					Space:    165,
+				}),
+				system.CreateAccountWithSeed(system.CreateAccountWithSeedParam{
+					From:     wallet.PublicKey,
+					New:      nonceAccount,
+					Base:     wallet.PublicKey,
+					Owner:    common.SystemProgramID,
+					Seed:     "nonce",
+					Lamports: 1_447_680,
+					Space:    solanatx.NonceAccountSize,
+				}),
+				system.WithdrawNonceAccount(system.WithdrawNonceAccountParam{
+					Nonce:  nonceAccount,
+					Auth:   wallet.PublicKey,
+					To:     destination,
+					Amount: 1_000,
+				}),
+			},
+		}),
+		Signers: []types.Account{wallet, newAccount},
+	})
+	require.NoError(t, err)
+
+	txb, err := tx.Serialize()
+	require.NoError(t, err)
+
+	result, err := solanatx.Decode(base64.StdEncoding.EncodeToString(txb))
+	require.NoError(t, err)
+	require.Len(t, result.Instructions, 3)
+
+	tests := []struct {
+		typ         string
+		source      string
+		destination string
+		amount      uint64
+	}{
+		{"create_account", wallet.PublicKey.ToBase58(), newAccount.PublicKey.ToBase58(), 2_039_280},
+		{"create_account_with_seed", wallet.PublicKey.ToBase58(), nonceAccount.ToBase58(), 1_447_680},
+		{"withdraw_nonce_account", nonceAccount.ToBase58(), destination.ToBase58(), 1_000},
+	}
+	for i, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			ins := result.Instructions[i]
+			require.Equal(t, tt.typ, ins.Type)
+			require.NotNil(t, ins.Transfer)
+			require.True(t, ins.Transfer.IsNative)
+			require.Equal(t, tt.source, ins.Transfer.Source)
+			require.Equal(t, tt.destination, ins.Transfer.Destination)
+			require.Equal(t, wallet.PublicKey.ToBase58(), ins.Transfer.Authority)
+			require.Equal(t, tt.amount, ins.Transfer.Amount.Amount)
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	_, err := solanatx.Decode("not a base64 transaction")
+	require.ErrorIs(t, err, solanatx.ErrInvalidTransaction)
+
+	_, err = solanatx.Decode(base64.StdEncoding.EncodeToString([]byte{1, 2, 3}))
+	require.ErrorIs(t, err, solanatx.ErrInvalidTransaction)
+}
+
+func TestDecodeV0(t *testing.T) {
+	feePayer := types.NewAccount()
+	destination := types.NewAccount().PublicKey
+	sourceAta := types.NewAccount().PublicKey
+	destinationAta := types.NewAccount().PublicKey
+	mint := types.NewAccount().PublicKey
+	table := types.NewAccount().PublicKey
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: types.NewMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: "9rAtxuhtKn8qagc3UtZFyhLrw5zgh6etCLnm3zGTSuC8",
+			Instructions: []types.Instruction{
+				system.Transfer(system.TransferParam{
+					From:   feePayer.PublicKey,
+					To:     destination,
+					Amount: 1_000_000_000,
+				}),
+				token.TransferChecked(token.TransferCheckedParam{
+					From:     sourceAta,
+					To:       destinationAta,
+					Mint:     mint,
+					Auth:     feePayer.PublicKey,
+					Amount:   1_000_000,
+					Decimals: 6,
+				}),
+			},
+			AddressLookupTableAccounts: []types.AddressLookupTableAccount{
+				{Key: table, Addresses: []common.PublicKey{mint, destination}},
+			},
+		}),
+		Signers: []types.Account{feePayer},
+	})
+	require.NoError(t, err)
+
+	txb, err := tx.Serialize()
+	require.NoError(t, err)
+	base64Tx := base64.StdEncoding.EncodeToString(txb)
+
+	t.Run("unresolved lookup tables", func(t *testing.T) {
+		result, err := solanatx.Decode(base64Tx)
+		require.NoError(t, err)
+		require.Equal(t, "v0", result.Version)
+		require.Equal(t, []solanatx.AddressTableLookup{{
+			Account:         table.ToBase58(),
+			WritableIndexes: []int{1},
+			ReadonlyIndexes: []int{0},
+		}}, result.AddressTableLookups)
+		require.Contains(t, result.Instructions[0].Transfer.Destination, "lookup_table_account:")
+	})
+
+	t.Run("resolved lookup tables", func(t *testing.T) {
+		result, err := solanatx.DecodeWithLookupTables(base64Tx, solanatx.LookupTables{
+			table.ToBase58(): {mint.ToBase58(), destination.ToBase58()},
+		})
+		require.NoError(t, err)
+		require.Equal(t, destination.ToBase58(), result.Instructions[0].Transfer.Destination)
+		require.Equal(t, feePayer.PublicKey.ToBase58()+" → "+destination.ToBase58()+" → 1 SOL", result.Instructions[0].Description)
+		require.Equal(t, mint.ToBase58(), result.Instructions[1].Transfer.Mint)
+		require.Equal(t, destinationAta.ToBase58(), result.Instructions[1].Transfer.Destination)
+
+		accounts, err := solanatx.WritableAccounts(base64Tx, solanatx.LookupTables{
+			table.ToBase58(): {mint.ToBase58(), destination.ToBase58()},
+		})
+		require.NoError(t, err)
+		require.Contains(t, accounts, destination.ToBase58())
+		require.NotContains(t, accounts, mint.ToBase58())
+	})
+
+	t.Run("lookup table index out of range", func(t *testing.T) {
+		_, err := solanatx.DecodeWithLookupTables(base64Tx, solanatx.LookupTables{
+			table.ToBase58(): {mint.ToBase58()},
+		})
+		require.ErrorIs(t, err, solanatx.ErrInvalidTransaction)
+	})
+}
@@ -0,0 +1,8 @@
+package solanatx
+
+import "errors"
+
+// Predefined package errors
+var (
+	ErrInvalidTransaction = errors.New("invalid transaction")
+)
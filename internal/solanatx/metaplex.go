@@ -0,0 +1,255 @@
+package solanatx
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// Metaplex programs missing in the solana-go-sdk common package
+var (
+	TokenAuthRulesProgramID = common.PublicKeyFromString("auth9SigNpDKz4sJJ1DfCTuZrZNSAgh9sFD3rboVmgg")
+)
+
+// Token Metadata program instruction discriminators used by the wallet
+const (
+	tokenMetadataInstructionTransfer = 49
+	tokenMetadataTransferV1          = 0
+)
+
+// TokenRecordSize is the size of the pNFT token record account
+const TokenRecordSize = 80
+
+// ProgrammableNonFungibleEdition is the token standard of the pNFT prints,
+// missing in the solana-go-sdk token_metadata package
+const ProgrammableNonFungibleEdition token_metadata.TokenStandard = token_metadata.ProgrammableNonFungible + 1
+
+// ErrInvalidMetadata is returned when the token metadata account can't be decoded
+var ErrInvalidMetadata = errors.New("invalid token metadata")
+
+type (
+	// TokenMetadata is the part of the Metaplex token metadata account used to transfer the token.
+	// TokenStandard is nil for the tokens minted before the token standards were introduced.
+	TokenMetadata struct {
+		Mint          common.PublicKey
+		TokenStandard *token_metadata.TokenStandard
+		RuleSet       *common.PublicKey // pNFT authorization rules, if any
+	}
+
+	// ProgrammableTransferParam is the params of the pNFT transfer instruction.
+	// Source and Destination are the associated token accounts of the owners,
+	// the destination token account is created by the program if missing.
+	ProgrammableTransferParam struct {
+		Source           common.PublicKey
+		SourceOwner      common.PublicKey
+		Destination      common.PublicKey
+		DestinationOwner common.PublicKey
+		Mint             common.PublicKey
+		Payer            common.PublicKey
+		RuleSet          *common.PublicKey
+		TokenProgram     common.PublicKey
+		Amount           uint64
+	}
+)
+
+// IsNonFungible reports whether the token is an NFT, a print edition or a pNFT.
+// Tokens without the token standard are treated as NFTs, the mint decimals must be checked by the caller.
+func (m TokenMetadata) IsNonFungible() bool {
+	if m.TokenStandard == nil {
+		return true
+	}
+
+	switch *m.TokenStandard {
+	case token_metadata.NonFungible,
+		token_metadata.NonFungibleEdition,
+		token_metadata.ProgrammableNonFungible,
+		ProgrammableNonFungibleEdition:
+		return true
+	}
+	return false
+}
+
+// IsProgrammable reports whether the token is a pNFT,
+// which must be transferred with the Token Metadata program instead of the token program
+func (m TokenMetadata) IsProgrammable() bool {
+	return m.TokenStandard != nil &&
+		(*m.TokenStandard == token_metadata.ProgrammableNonFungible || *m.TokenStandard == ProgrammableNonFungibleEdition)
+}
+
+// ParseTokenMetadata decodes the Metaplex token metadata account data.
+// Only the fields needed to transfer the token are returned,
+// the rest of the metadata is available with the solana client.
+func ParseTokenMetadata(data []byte) (TokenMetadata, error) {
+	r := &borshReader{data: data}
+
+	r.skip(1)  // key
+	r.skip(32) // update authority
+	var result TokenMetadata
+	copy(result.Mint[:], r.bytes(32))
+
+	// data
+	r.str() // name
+	r.str() // symbol
+	r.str() // uri
+	r.skip(2)
+	if r.option() {
+		r.skip(int(r.u32()) * 34) // creators: address, verified, share
+	}
+
+	r.skip(2) // primary sale happened, is mutable
+	if r.option() {
+		r.skip(1) // edition nonce
+	}
+	if r.option() {
+		standard := token_metadata.TokenStandard(r.u8())
+		result.TokenStandard = &standard
+	}
+	if r.option() {
+		r.skip(33) // collection
+	}
+	if r.option() {
+		r.skip(17) // uses
+	}
+	if r.option() {
+		r.skip(9) // collection details
+	}
+
+	// programmable config, missing in the accounts created before pNFTs were introduced
+	if r.err == nil && r.off < len(r.data) && r.option() {
+		r.skip(1) // version
+		if r.option() {
+			var ruleSet common.PublicKey
+			copy(ruleSet[:], r.bytes(32))
+			result.RuleSet = &ruleSet
+		}
+	}
+
+	if r.err != nil {
+		return TokenMetadata{}, fmt.Errorf("%w: %s", ErrInvalidMetadata, r.err)
+	}
+
+	return result, nil
+}
+
+// TokenRecordAddress derives the pNFT token record account of the token account
+func TokenRecordAddress(mint, tokenAccount common.PublicKey) (common.PublicKey, error) {
+	addr, _, err := common.FindProgramAddress(
+		[][]byte{
+			[]byte("metadata"),
+			common.MetaplexTokenMetaProgramID.Bytes(),
+			mint.Bytes(),
+			[]byte("token_record"),
+			tokenAccount.Bytes(),
+		},
+		common.MetaplexTokenMetaProgramID,
+	)
+	return addr, err
+}
+
+// ProgrammableTransfer builds the Token Metadata program transfer instruction for pNFTs.
+// The source owner is the transfer authority.
+func ProgrammableTransfer(param ProgrammableTransferParam) (types.Instruction, error) {
+	metadata, err := token_metadata.GetTokenMetaPubkey(param.Mint)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to derive metadata account: %w", err)
+	}
+	edition, err := token_metadata.GetMasterEdition(param.Mint)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to derive edition account: %w", err)
+	}
+	ownerTokenRecord, err := TokenRecordAddress(param.Mint, param.Source)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to derive token record: %w", err)
+	}
+	destinationTokenRecord, err := TokenRecordAddress(param.Mint, param.Destination)
+	if err != nil {
+		return types.Instruction{}, fmt.Errorf("failed to derive token record: %w", err)
+	}
+
+	// optional accounts which are not set are replaced with the program id
+	authRulesProgram, authRules := common.MetaplexTokenMetaProgramID, common.MetaplexTokenMetaProgramID
+	if param.RuleSet != nil {
+		authRulesProgram, authRules = TokenAuthRulesProgramID, *param.RuleSet
+	}
+
+	// data: discriminator, TransferArgs::V1 { amount: u64, authorization_data: None }
+	data := make([]byte, 11)
+	data[0] = tokenMetadataInstructionTransfer
+	data[1] = tokenMetadataTransferV1
+	binary.LittleEndian.PutUint64(data[2:10], param.Amount)
+
+	return types.Instruction{
+		ProgramID: common.MetaplexTokenMetaProgramID,
+		Accounts: []types.AccountMeta{
+			{PubKey: param.Source, IsSigner: false, IsWritable: true},
+			{PubKey: param.SourceOwner, IsSigner: false, IsWritable: false},
+			{PubKey: param.Destination, IsSigner: false, IsWritable: true},
+			{PubKey: param.DestinationOwner, IsSigner: false, IsWritable: false},
+			{PubKey: param.Mint, IsSigner: false, IsWritable: false},
+			{PubKey: metadata, IsSigner: false, IsWritable: true},
+			{PubKey: edition, IsSigner: false, IsWritable: false},
+			{PubKey: ownerTokenRecord, IsSigner: false, IsWritable: true},
+			{PubKey: destinationTokenRecord, IsSigner: false, IsWritable: true},
+			{PubKey: param.SourceOwner, IsSigner: true, IsWritable: false},
+			{PubKey: param.Payer, IsSigner: true, IsWritable: true},
+			{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+			{PubKey: common.SysVarInstructionsPubkey, IsSigner: false, IsWritable: false},
+			{PubKey: param.TokenProgram, IsSigner: false, IsWritable: false},
+			{PubKey: common.SPLAssociatedTokenAccountProgramID, IsSigner: false, IsWritable: false},
+			{PubKey: authRulesProgram, IsSigner: false, IsWritable: false},
+			{PubKey: authRules, IsSigner: false, IsWritable: false},
+		},
+		Data: data,
+	}, nil
+}
+
+// borshReader reads the borsh encoded values, the first error stops reading
+type borshReader struct {
+	data []byte
+	off  int
+	err  error
+}
+
+func (r *borshReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.off+n > len(r.data) {
+		r.err = fmt.Errorf("unexpected end of data at offset %d", r.off)
+		return nil
+	}
+	b := r.data[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *borshReader) skip(n int) {
+	r.bytes(n)
+}
+
+func (r *borshReader) u8() uint8 {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *borshReader) u32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *borshReader) str() string {
+	return string(r.bytes(int(r.u32())))
+}
+
+// option reads the option tag and reports whether the value is present
+func (r *borshReader) option() bool {
+	return r.u8() == 1
+}
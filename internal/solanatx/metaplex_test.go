@@ -0,0 +1,122 @@
+package solanatx_test
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTokenMetadata(t *testing.T) {
+	mint := types.NewAccount().PublicKey
+	ruleSet := types.NewAccount().PublicKey
+
+	md, err := solanatx.ParseTokenMetadata(metadataAccountData(mint, nil, nil))
+	require.NoError(t, err)
+	require.Equal(t, mint, md.Mint)
+	require.Nil(t, md.TokenStandard)
+	require.Nil(t, md.RuleSet)
+	require.True(t, md.IsNonFungible())
+	require.False(t, md.IsProgrammable())
+
+	fungible := token_metadata.Fungible
+	md, err = solanatx.ParseTokenMetadata(metadataAccountData(mint, &fungible, nil))
+	require.NoError(t, err)
+	require.False(t, md.IsNonFungible())
+
+	pnft := token_metadata.ProgrammableNonFungible
+	md, err = solanatx.ParseTokenMetadata(metadataAccountData(mint, &pnft, &ruleSet))
+	require.NoError(t, err)
+	require.True(t, md.IsNonFungible())
+	require.True(t, md.IsProgrammable())
+	require.NotNil(t, md.RuleSet)
+	require.Equal(t, ruleSet, *md.RuleSet)
+
+	_, err = solanatx.ParseTokenMetadata([]byte{4, 1, 2})
+	require.ErrorIs(t, err, solanatx.ErrInvalidMetadata)
+}
+
+func TestProgrammableTransfer(t *testing.T) {
+	owner := types.NewAccount()
+	destination := types.NewAccount().PublicKey
+	mint := types.NewAccount().PublicKey
+	sourceAta := types.NewAccount().PublicKey
+	destinationAta := types.NewAccount().PublicKey
+
+	ins, err := solanatx.ProgrammableTransfer(solanatx.ProgrammableTransferParam{
+		Source:           sourceAta,
+		SourceOwner:      owner.PublicKey,
+		Destination:      destinationAta,
+		DestinationOwner: destination,
+		Mint:             mint,
+		Payer:            owner.PublicKey,
+		TokenProgram:     common.TokenProgramID,
+		Amount:           1,
+	})
+	require.NoError(t, err)
+	require.Len(t, ins.Accounts, 17)
+	require.Equal(t, common.MetaplexTokenMetaProgramID, ins.Accounts[16].PubKey) // no rule set
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: types.NewMessage(types.NewMessageParam{
+			FeePayer:        owner.PublicKey,
+			RecentBlockhash: "9rAtxuhtKn8qagc3UtZFyhLrw5zgh6etCLnm3zGTSuC8",
+			Instructions:    []types.Instruction{ins},
+		}),
+		Signers: []types.Account{owner},
+	})
+	require.NoError(t, err)
+	txb, err := tx.Serialize()
+	require.NoError(t, err)
+
+	decoded, err := solanatx.Decode(base64.StdEncoding.EncodeToString(txb))
+	require.NoError(t, err)
+	require.Len(t, decoded.Instructions, 1)
+	require.Equal(t, "transfer", decoded.Instructions[0].Type)
+	require.NotNil(t, decoded.Instructions[0].Transfer)
+	require.Equal(t, sourceAta.ToBase58(), decoded.Instructions[0].Transfer.Source)
+	require.Equal(t, destinationAta.ToBase58(), decoded.Instructions[0].Transfer.Destination)
+	require.Equal(t, owner.PublicKey.ToBase58(), decoded.Instructions[0].Transfer.Authority)
+	require.Equal(t, mint.ToBase58(), decoded.Instructions[0].Transfer.Mint)
+	require.Equal(t, uint64(1), decoded.Instructions[0].Transfer.Amount.Amount)
+	require.True(t, decoded.Instructions[0].Transfer.Resolved())
+}
+
+// metadataAccountData encodes the token metadata account with a single creator,
+// padded to the fixed metadata account size
+func metadataAccountData(mint common.PublicKey, standard *token_metadata.TokenStandard, ruleSet *common.PublicKey) []byte {
+	str := func(s string) []byte {
+		b := binary.LittleEndian.AppendUint32(nil, uint32(len(s)))
+		return append(b, s...)
+	}
+
+	data := []byte{byte(token_metadata.KeyMetadataV1)}
+	data = append(data, types.NewAccount().PublicKey.Bytes()...) // update authority
+	data = append(data, mint.Bytes()...)
+	data = append(data, str("Name")...)
+	data = append(data, str("SYM")...)
+	data = append(data, str("https://example.com/nft.json")...)
+	data = append(data, 0xf4, 0x01)    // seller fee basis points
+	data = append(data, 1, 1, 0, 0, 0) // creators: some, one creator
+	data = append(data, types.NewAccount().PublicKey.Bytes()...)
+	data = append(data, 1, 100)
+	data = append(data, 0, 1) // primary sale happened, is mutable
+	data = append(data, 1, 255)
+	if standard != nil {
+		data = append(data, 1, byte(*standard))
+	} else {
+		data = append(data, 0)
+	}
+	data = append(data, 0, 0, 0) // collection, uses, collection details
+	if ruleSet != nil {
+		data = append(data, 1, 0, 1)
+		data = append(data, ruleSet.Bytes()...)
+	}
+
+	return append(data, make([]byte, 679-len(data))...)
+}
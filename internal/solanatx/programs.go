@@ -0,0 +1,91 @@
+package solanatx
+
+import "github.com/portto/solana-go-sdk/common"
+
+// Well-known program IDs missing in the solana-go-sdk common package
+var (
+	Token2022ProgramID = common.PublicKeyFromString("TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb")
+	MemoV1ProgramID    = common.PublicKeyFromString("Memo1UhkJRfHyvLMcVucJwxXeuD728EqVDDwQDxFMNo")
+)
+
+// programNames maps the well-known program IDs to human readable names
+var programNames = map[common.PublicKey]string{
+	common.SystemProgramID:                    "System Program",
+	common.ConfigProgramID:                    "Config Program",
+	common.StakeProgramID:                     "Stake Program",
+	common.VoteProgramID:                      "Vote Program",
+	common.BPFLoaderProgramID:                 "BPF Loader",
+	common.Secp256k1ProgramID:                 "Secp256k1 Program",
+	common.TokenProgramID:                     "Token Program",
+	Token2022ProgramID:                        "Token-2022 Program",
+	common.MemoProgramID:                      "Memo Program",
+	MemoV1ProgramID:                           "Memo Program v1",
+	common.SPLAssociatedTokenAccountProgramID: "Associated Token Account Program",
+	common.SPLNameServiceProgramID:            "Name Service Program",
+	common.MetaplexTokenMetaProgramID:         "Token Metadata Program",
+	TokenAuthRulesProgramID:                   "Token Auth Rules Program",
+	common.ComputeBudgetProgramID:             "Compute Budget Program",
+	common.AddressLookupTableProgramID:        "Address Lookup Table Program",
+}
+
+// System program instruction names, indexed by the instruction discriminator
+var systemInstructions = []string{
+	"create_account",
+	"assign",
+	"transfer",
+	"create_account_with_seed",
+	"advance_nonce_account",
+	"withdraw_nonce_account",
+	"initialize_nonce_account",
+	"authorize_nonce_account",
+	"allocate",
+	"allocate_with_seed",
+	"assign_with_seed",
+	"transfer_with_seed",
+	"upgrade_nonce_account",
+}
+
+// SPL Token program instruction names, indexed by the instruction discriminator.
+// Token-2022 shares the same layout for these instructions.
+var tokenInstructions = []string{
+	"initialize_mint",
+	"initialize_account",
+	"initialize_multisig",
+	"transfer",
+	"approve",
+	"revoke",
+	"set_authority",
+	"mint_to",
+	"burn",
+	"close_account",
+	"freeze_account",
+	"thaw_account",
+	"transfer_checked",
+	"approve_checked",
+	"mint_to_checked",
+	"burn_checked",
+	"initialize_account2",
+	"sync_native",
+	"initialize_account3",
+	"initialize_multisig2",
+	"initialize_mint2",
+}
+
+// Compute Budget program instruction names, indexed by the instruction discriminator
+var computeBudgetInstructions = []string{
+	"request_units",
+	"request_heap_frame",
+	"set_compute_unit_limit",
+	"set_compute_unit_price",
+}
+
+// ProgramName returns the human readable name of the well-known program,
+// or an empty string if the program is unknown
+func ProgramName(programID common.PublicKey) string {
+	return programNames[programID]
+}
+
+// IsTokenProgram reports whether the program is SPL Token or Token-2022
+func IsTokenProgram(programID common.PublicKey) bool {
+	return programID == common.TokenProgramID || programID == Token2022ProgramID
+}
@@ -0,0 +1,166 @@
+package solanatx
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dmitrymomot/solana/types"
+)
+
+type (
+	// Simulation is the result of the transaction simulation
+	Simulation struct {
+		Success        bool            `json:"success"`
+		Err            interface{}     `json:"err,omitempty"` // transaction error as returned by the RPC node
+		Logs           []string        `json:"logs"`
+		UnitsConsumed  uint64          `json:"units_consumed"`
+		BalanceChanges []BalanceChange `json:"balance_changes"`
+	}
+
+	// BalanceChange is the SOL or token balance change of the wallet caused by the transaction.
+	// Delta is in base units: lamports for SOL, the smallest token units for SPL tokens.
+	BalanceChange struct {
+		Account  string            `json:"account"`
+		IsNative bool              `json:"is_native"`
+		Mint     string            `json:"mint"` // "SOL" for the native balance
+		Pre      types.TokenAmount `json:"pre"`
+		Post     types.TokenAmount `json:"post"`
+		Delta    int64             `json:"delta"`
+	}
+)
+
+// WritableAccounts returns the base58 encoded writable account keys of the base64 encoded transaction.
+// Only these accounts may change their balances. The writable accounts loaded from the given
+// address lookup tables are included, the ones of the tables missing from the given ones are not.
+func WritableAccounts(base64Tx string, tables LookupTables) ([]string, error) {
+	tx, err := deserialize(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tx.Message
+	signed := int(msg.Header.NumRequireSignatures)
+	readonlySigned := int(msg.Header.NumReadonlySignedAccounts)
+	readonlyUnsigned := int(msg.Header.NumReadonlyUnsignedAccounts)
+	if signed > len(msg.Accounts) || readonlySigned > signed || readonlyUnsigned > len(msg.Accounts)-signed {
+		return nil, fmt.Errorf("%w: malformed message header", ErrInvalidTransaction)
+	}
+
+	result := make([]string, 0, len(msg.Accounts))
+	for i, acc := range msg.Accounts {
+		if i < signed && i >= signed-readonlySigned {
+			continue // readonly signer
+		}
+		if i >= len(msg.Accounts)-readonlyUnsigned {
+			continue // readonly non-signer
+		}
+		result = append(result, acc.ToBase58())
+	}
+
+	for _, l := range msg.AddressLookupTables {
+		if _, ok := tables[l.AccountKey.ToBase58()]; !ok {
+			continue
+		}
+		loaded, err := lookupAddresses(l, tables, l.WritableIndexes)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, loaded...)
+	}
+
+	return result, nil
+}
+
+// BalanceChanges returns the SOL and token balance changes of the wallet.
+// The pre and post states are ordered as the addresses.
+// Token balances are in base units until the decimals are set with BalanceChange.SetDecimals.
+// Accounts without changes are skipped.
+func BalanceChanges(wallet string, addresses []string, pre, post []*AccountState) []BalanceChange {
+	result := make([]BalanceChange, 0, len(addresses))
+	for i, addr := range addresses {
+		before, after := stateAt(pre, i), stateAt(post, i)
+
+		if addr == wallet {
+			if change := nativeChange(addr, before, after); change.Delta != 0 {
+				result = append(result, change)
+			}
+			continue
+		}
+
+		mint, preAmount, ok := walletTokenBalance(wallet, before)
+		mint2, postAmount, ok2 := walletTokenBalance(wallet, after)
+		switch {
+		case ok && ok2 && mint != mint2:
+			continue // token accounts can't change the mint, skip the inconsistent state
+		case !ok && !ok2:
+			continue // not a wallet token account
+		case !ok:
+			mint = mint2
+		}
+		if preAmount == postAmount {
+			continue
+		}
+
+		result = append(result, BalanceChange{
+			Account: addr,
+			Mint:    mint,
+			Pre:     types.TokenAmount{Amount: preAmount},
+			Post:    types.TokenAmount{Amount: postAmount},
+			Delta:   int64(postAmount) - int64(preAmount),
+		})
+	}
+
+	// native balance first, then tokens by mint
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].IsNative != result[j].IsNative {
+			return result[i].IsNative
+		}
+		return result[i].Mint < result[j].Mint
+	})
+
+	return result
+}
+
+// SetDecimals sets the token decimals of the pre and post balances
+func (c *BalanceChange) SetDecimals(decimals uint8) {
+	c.Pre = types.NewTokenAmountFromLamports(c.Pre.Amount, decimals)
+	c.Post = types.NewTokenAmountFromLamports(c.Post.Amount, decimals)
+}
+
+// nativeChange returns the SOL balance change of the account
+func nativeChange(addr string, before, after *AccountState) BalanceChange {
+	var preLamports, postLamports uint64
+	if before != nil {
+		preLamports = before.Lamports
+	}
+	if after != nil {
+		postLamports = after.Lamports
+	}
+
+	return BalanceChange{
+		Account:  addr,
+		IsNative: true,
+		Mint:     nativeSymbol,
+		Pre:      types.NewDefaultTokenAmount(preLamports),
+		Post:     types.NewDefaultTokenAmount(postLamports),
+		Delta:    int64(postLamports) - int64(preLamports),
+	}
+}
+
+// walletTokenBalance returns the mint and the amount of the token account owned by the wallet
+func walletTokenBalance(wallet string, state *AccountState) (string, uint64, bool) {
+	acc, ok := ParseTokenAccount(state)
+	if !ok || acc.Owner != wallet {
+		return "", 0, false
+	}
+
+	return acc.Mint, acc.Amount, true
+}
+
+// stateAt returns the account state by index or nil if the index is out of range
+func stateAt(states []*AccountState, i int) *AccountState {
+	if i < len(states) {
+		return states[i]
+	}
+	return nil
+}
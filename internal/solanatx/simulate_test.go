@@ -0,0 +1,96 @@
+package solanatx_test
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/system"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritableAccounts(t *testing.T) {
+	feePayer := types.NewAccount()
+	destination := types.NewAccount().PublicKey
+
+	tx, err := types.NewTransaction(types.NewTransactionParam{
+		Message: types.NewMessage(types.NewMessageParam{
+			FeePayer:        feePayer.PublicKey,
+			RecentBlockhash: "9rAtxuhtKn8qagc3UtZFyhLrw5zgh6etCLnm3zGTSuC8",
+			Instructions: []types.Instruction{
+				system.Transfer(system.TransferParam{
+					From:   feePayer.PublicKey,
+					To:     destination,
+					Amount: 1_000,
+				}),
+			},
+		}),
+		Signers: []types.Account{feePayer},
+	})
+	require.NoError(t, err)
+
+	txb, err := tx.Serialize()
+	require.NoError(t, err)
+
+	accounts, err := solanatx.WritableAccounts(base64.StdEncoding.EncodeToString(txb), nil)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{feePayer.PublicKey.ToBase58(), destination.ToBase58()}, accounts)
+
+	_, err = solanatx.WritableAccounts("not a transaction", nil)
+	require.ErrorIs(t, err, solanatx.ErrInvalidTransaction)
+}
+
+func TestBalanceChanges(t *testing.T) {
+	wallet := types.NewAccount().PublicKey
+	stranger := types.NewAccount().PublicKey
+	mint := types.NewAccount().PublicKey
+	walletAta := types.NewAccount().PublicKey.ToBase58()
+	strangerAta := types.NewAccount().PublicKey.ToBase58()
+	newAta := types.NewAccount().PublicKey.ToBase58()
+
+	addresses := []string{wallet.ToBase58(), walletAta, strangerAta, newAta}
+	pre := []*solanatx.AccountState{
+		{Lamports: 5_000_000_000, Owner: common.SystemProgramID.ToBase58()},
+		tokenAccount(mint, wallet, 1_000),
+		tokenAccount(mint, stranger, 0),
+		nil,
+	}
+	post := []*solanatx.AccountState{
+		{Lamports: 3_999_995_000, Owner: common.SystemProgramID.ToBase58()},
+		tokenAccount(mint, wallet, 400),
+		tokenAccount(mint, stranger, 600),
+		tokenAccount(mint, wallet, 0),
+	}
+
+	changes := solanatx.BalanceChanges(wallet.ToBase58(), addresses, pre, post)
+	require.Len(t, changes, 2)
+
+	require.True(t, changes[0].IsNative)
+	require.Equal(t, "SOL", changes[0].Mint)
+	require.Equal(t, int64(-1_000_005_000), changes[0].Delta)
+	require.Equal(t, uint64(3_999_995_000), changes[0].Post.Amount)
+
+	require.False(t, changes[1].IsNative)
+	require.Equal(t, walletAta, changes[1].Account)
+	require.Equal(t, mint.ToBase58(), changes[1].Mint)
+	require.Equal(t, int64(-600), changes[1].Delta)
+
+	changes[1].SetDecimals(2)
+	require.Equal(t, "4", changes[1].Post.UIAmountString)
+}
+
+func tokenAccount(mint, owner common.PublicKey, amount uint64) *solanatx.AccountState {
+	data := make([]byte, 165)
+	copy(data[:32], mint.Bytes())
+	copy(data[32:64], owner.Bytes())
+	binary.LittleEndian.PutUint64(data[64:72], amount)
+
+	return &solanatx.AccountState{
+		Lamports: 2_039_280,
+		Owner:    common.TokenProgramID.ToBase58(),
+		Data:     data,
+	}
+}
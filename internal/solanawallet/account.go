@@ -1,7 +1,11 @@
 package solanawallet
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"filippo.io/edwards25519"
 	"github.com/mr-tron/base58"
@@ -26,7 +30,61 @@ func AccountFromBase58(s string) (types.Account, error) {
 		return types.Account{}, fmt.Errorf("failed to decode base58 string to solana account: %w", err)
 	}
 
-	return types.AccountFromBytes(b)
+	return AccountFromPrivateKey(b)
+}
+
+// AccountFromKeygenJSON creates an Solana account from the solana-keygen keypair file content,
+// a JSON array of 64 bytes: [12,34,...]
+func AccountFromKeygenJSON(data []byte) (types.Account, error) {
+	var keypair []byte
+	if err := json.Unmarshal(data, &keypair); err != nil {
+		return types.Account{}, fmt.Errorf("failed to decode keypair json: %w", err)
+	}
+
+	return AccountFromPrivateKey(keypair)
+}
+
+// AccountToKeygenJSON encodes the account private key to the solana-keygen keypair file content
+func AccountToKeygenJSON(a types.Account) []byte {
+	ints := make([]int, len(a.PrivateKey))
+	for i, b := range a.PrivateKey {
+		ints[i] = int(b)
+	}
+
+	// marshaling of the int slice never fails
+	data, _ := json.Marshal(ints)
+	return data
+}
+
+// AccountFromPrivateKey creates an Solana account from the 64 bytes ed25519 private key (seed + public key)
+// or the 32 bytes seed. The public key part of the private key is checked against the seed.
+func AccountFromPrivateKey(b []byte) (types.Account, error) {
+	switch len(b) {
+	case ed25519.SeedSize:
+		return types.AccountFromSeed(b)
+	case ed25519.PrivateKeySize:
+		acc, err := types.AccountFromSeed(b[:ed25519.SeedSize])
+		if err != nil {
+			return types.Account{}, err
+		}
+		if !bytes.Equal(acc.PublicKey.Bytes(), b[ed25519.SeedSize:]) {
+			return types.Account{}, fmt.Errorf("private key does not match its public key")
+		}
+		return acc, nil
+	}
+
+	return types.Account{}, fmt.Errorf("invalid private key length: %d", len(b))
+}
+
+// ParsePrivateKey creates an Solana account from the base58 encoded private key
+// (Phantom, Solflare export) or the solana-keygen keypair file content
+func ParsePrivateKey(s string) (types.Account, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") {
+		return AccountFromKeygenJSON([]byte(s))
+	}
+
+	return AccountFromBase58(s)
 }
 
 // AccountFromString creates an Solana account from a base58 encoded string.
@@ -88,3 +146,18 @@ func DeriveTokenAccountPubkey(wallet, mint common.PublicKey) (common.PublicKey,
 
 	return ata, nil
 }
+
+// DeriveTokenAccountWithProgram derives an associated token account from a Solana account and a mint address
+// owned by the given token program, either SPL Token or Token-2022.
+// The token program is a part of the associated token account seeds, so the addresses differ.
+func DeriveTokenAccountWithProgram(wallet, mint, tokenProgram common.PublicKey) (common.PublicKey, error) {
+	ata, _, err := common.FindProgramAddress(
+		[][]byte{wallet.Bytes(), tokenProgram.Bytes(), mint.Bytes()},
+		common.SPLAssociatedTokenAccountProgramID,
+	)
+	if err != nil {
+		return common.PublicKey{}, fmt.Errorf("failed to derive token account: %w", err)
+	}
+
+	return ata, nil
+}
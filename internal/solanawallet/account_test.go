@@ -0,0 +1,26 @@
+package solanawallet_test
+
+import (
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveTokenAccountWithProgram(t *testing.T) {
+	wallet := solanawallet.NewAccount().PublicKey
+	mint := solanawallet.NewAccount().PublicKey
+	token2022 := common.PublicKeyFromString("TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb")
+
+	ata, err := solanawallet.DeriveTokenAccountPubkey(wallet, mint)
+	require.NoError(t, err)
+
+	splAta, err := solanawallet.DeriveTokenAccountWithProgram(wallet, mint, common.TokenProgramID)
+	require.NoError(t, err)
+	require.Equal(t, ata, splAta)
+
+	token2022Ata, err := solanawallet.DeriveTokenAccountWithProgram(wallet, mint, token2022)
+	require.NoError(t, err)
+	require.NotEqual(t, ata, token2022Ata)
+}
@@ -4,11 +4,13 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"errors"
 	"io"
 )
 
-// encrypt string to base64 crypto using AES-256
-func encrypt(plaintext []byte, key []byte) ([]byte, error) {
+// encrypt string to base64 crypto using AES-256.
+// additionalData is authenticated but not encrypted, it may be nil.
+func encrypt(plaintext []byte, key []byte, additionalData []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
@@ -24,12 +26,14 @@ func encrypt(plaintext []byte, key []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	ciphertext := aesGCM.Seal(nonce, nonce, plaintext, nil)
+	ciphertext := aesGCM.Seal(nonce, nonce, plaintext, additionalData)
 	return ciphertext, nil
 }
 
-// decrypt base64 crypto to decrypted string
-func decrypt(ciphertext []byte, key []byte) ([]byte, error) {
+// decrypt base64 crypto to decrypted string.
+// additionalData must be the same as the one used for encryption.
+// Returns ErrAuthenticationFailed if the ciphertext can't be opened with the key.
+func decrypt(ciphertext []byte, key []byte, additionalData []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
@@ -41,10 +45,13 @@ func decrypt(ciphertext []byte, key []byte) ([]byte, error) {
 	}
 
 	nonceSize := aesGCM.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, additionalData)
 	if err != nil {
-		return nil, err
+		return nil, ErrAuthenticationFailed
 	}
 
 	return plaintext, nil
@@ -0,0 +1,25 @@
+package solanawallet
+
+import "encoding/binary"
+
+// AssociatedData identifies the record the encrypted mnemonic belongs to.
+// It's authenticated by AES-GCM along with the ciphertext, so the ciphertext
+// copied to another record fails to decrypt.
+type AssociatedData struct {
+	UserID    string
+	WalletID  string
+	PublicKey string
+}
+
+// Bytes returns a length-prefixed binary representation of the associated data,
+// so the fields boundaries can't be shifted.
+func (a AssociatedData) Bytes() []byte {
+	var b []byte
+	for _, field := range []string{a.UserID, a.WalletID, a.PublicKey} {
+		l := make([]byte, 4)
+		binary.BigEndian.PutUint32(l, uint32(len(field)))
+		b = append(b, l...)
+		b = append(b, field...)
+	}
+	return b
+}
@@ -1,7 +1,10 @@
 package solanawallet
 
 import (
+	"context"
+	"crypto/rand"
 	"fmt"
+	"io"
 
 	"github.com/mr-tron/base58"
 )
@@ -9,55 +12,288 @@ import (
 type (
 	// Client is the main struct for the Solana client
 	Client struct {
-		salt string
+		salt          string
+		previousSalts []string
+		kdf           KDFParams
+		keys          KeyProvider
+		requireAD     bool
 	}
+
+	// Option is a function that configures the Client
+	Option func(*Client)
 )
 
 // NewClient creates a new Solana client
-func NewClient(salt string) *Client {
-	if l := len(salt); l < 16 {
-		panic(fmt.Sprintf("invalid salt length: %d, must be more than 16", l))
+func NewClient(salt string, opts ...Option) *Client {
+	validateSalt(salt)
+
+	c := &Client{salt: salt, kdf: DefaultKDFParams}
+	for _, opt := range opts {
+		opt(c)
 	}
-	return &Client{salt: salt}
+
+	return c
 }
 
 // GenerateMnemonic generates a new mnemonic phrase, encode it to base58 and hash with secret key.
-func (c *Client) EnctyptMnemonic(mnemonic, pin string) (string, error) {
-	return c.encryptMnemonic(mnemonic, pin)
+// The ciphertext is bound to the record with the given associated data.
+func (c *Client) EnctyptMnemonic(ctx context.Context, mnemonic, pin string, ad AssociatedData) (string, error) {
+	return c.encryptMnemonic(ctx, mnemonic, pin, ad)
+}
+
+// DecryptMnemonic decrypts a base58 encoded string with AES-256-GCM and returns the decrypted mnemonic phrase.
+// The associated data must be the same as the one used for encryption,
+// it's ignored for the mnemonics encrypted before the associated data was introduced,
+// unless the client is created with WithRequireAssociatedData.
+func (c *Client) DecryptMnemonic(ctx context.Context, encrypted, pin string, ad AssociatedData) (string, error) {
+	return c.decryptMnemonic(ctx, encrypted, pin, ad)
+}
+
+// NeedsReencryption returns true if the encrypted mnemonic was produced by the legacy
+// key derivation, isn't bound to its record with associated data, was encrypted
+// with the KDF params different from the current ones, or, if the key provider is set,
+// its data key isn't wrapped with the current key encryption key.
+// While the previous salts are set, all mnemonics encrypted without the key provider need it,
+// since the envelope doesn't tell which salt it's encrypted with.
+// Such mnemonics should be re-encrypted the next time the correct PIN is provided.
+func (c *Client) NeedsReencryption(encrypted string) bool {
+	if !isEnvelope(encrypted) {
+		return true
+	}
+
+	e, err := parseEnvelope(encrypted)
+	if err != nil {
+		return false // broken envelope can't be decrypted anyway
+	}
+
+	if e.KDF != KDFArgon2id || e.Params != c.kdf {
+		return true
+	}
+	if c.keys != nil {
+		return e.Version != envelopeVersion3 || e.KeyID != c.keys.CurrentKeyID()
+	}
+
+	return e.Version != envelopeVersion2 || len(c.previousSalts) > 0
+}
+
+// KeyVersion returns the id of the key encryption key wrapping the data key of the encrypted mnemonic.
+// Returns an empty string if the mnemonic is encrypted without the data key.
+func (c *Client) KeyVersion(encrypted string) string {
+	if !isEnvelope(encrypted) {
+		return ""
+	}
+
+	e, err := parseEnvelope(encrypted)
+	if err != nil || e.Version != envelopeVersion3 {
+		return ""
+	}
+
+	return e.KeyID
+}
+
+// HasAssociatedData reports whether the encrypted mnemonic is bound to its record with associated data.
+// The legacy and v1 mnemonics aren't, they are rejected with WithRequireAssociatedData.
+func (c *Client) HasAssociatedData(encrypted string) bool {
+	if !isEnvelope(encrypted) {
+		return false
+	}
+
+	e, err := parseEnvelope(encrypted)
+	return err == nil && e.Version != envelopeVersion1
 }
 
-// DecryptMnemonic decrypts a base58 encoded string with AES-256-GCM and returns the decrypted mnemonic phrase
-func (c *Client) DecryptMnemonic(encrypted, pin string) (string, error) {
-	return c.decryptMnemonic(encrypted, pin)
+// RewrapMnemonic re-wraps the data key of the encrypted mnemonic with the current key encryption key.
+// The mnemonic itself isn't decrypted, so the PIN isn't required.
+// Returns ErrNotEnvelopeEncrypted for the mnemonics encrypted without the data key,
+// those can be re-encrypted only with the PIN.
+func (c *Client) RewrapMnemonic(ctx context.Context, encrypted string) (string, error) {
+	if c.keys == nil {
+		return "", ErrKeyProviderNotConfigured
+	}
+	if !isEnvelope(encrypted) {
+		return "", ErrNotEnvelopeEncrypted
+	}
+
+	e, err := parseEnvelope(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse envelope: %w", err)
+	}
+	if e.Version != envelopeVersion3 {
+		return "", ErrNotEnvelopeEncrypted
+	}
+
+	dataKey, err := c.keys.UnwrapKey(ctx, e.KeyID, e.WrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	if e.KeyID, e.WrappedKey, err = c.keys.WrapKey(ctx, dataKey); err != nil {
+		return "", fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	return e.String(), nil
 }
 
-// signingKey returns a signing key for the given pin.
+// signingKey returns a signing key for the given pin and server-wide salt.
 // The resulting key is used to encrypt and decrypt mnemonic phrases.
-func (c *Client) signingKey(pin string) []byte {
-	return hash([]byte(pin + c.salt))
+// Deprecated: used only to decrypt legacy mnemonics, see deriveSigningKey.
+func signingKey(pin, serverSalt string) []byte {
+	return hash([]byte(pin + serverSalt))
+}
+
+// deriveSigningKey returns a signing key for the given pin, server-wide salt and per-record salt,
+// derived with the memory-hard Argon2id function.
+func deriveSigningKey(pin, serverSalt string, salt []byte, p KDFParams) []byte {
+	return deriveKey([]byte(pin+serverSalt), salt, p)
+}
+
+// serverSalts returns the current server-wide salt followed by the previous ones,
+// in the order they are tried to decrypt the mnemonics encrypted without the key provider
+func (c *Client) serverSalts() []string {
+	return append([]string{c.salt}, c.previousSalts...)
 }
 
-// encryptMnemonic encrypts a mnemonic phrase with AES-256-GCM and returns the encrypted data as a base58 encoded string
-func (c *Client) encryptMnemonic(mnemonic, pin string) (string, error) {
-	encrypted, err := encrypt([]byte(mnemonic), c.signingKey(pin))
+// encryptMnemonic encrypts a mnemonic phrase with AES-256-GCM and returns the encrypted data as an envelope string
+func (c *Client) encryptMnemonic(ctx context.Context, mnemonic, pin string, ad AssociatedData) (string, error) {
+	salt := make([]byte, kdfSaltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	if c.keys != nil {
+		return c.encryptMnemonicWithDataKey(ctx, mnemonic, pin, salt, ad)
+	}
+
+	encrypted, err := encrypt([]byte(mnemonic), deriveSigningKey(pin, c.salt, salt, c.kdf), ad.Bytes())
 	if err != nil {
 		return "", fmt.Errorf("failed to encrypt mnemonic: %w", err)
 	}
 
-	return base58.Encode(encrypted), nil
+	return envelope{
+		Version:    envelopeVersion2,
+		KDF:        KDFArgon2id,
+		Params:     c.kdf,
+		Salt:       salt,
+		Ciphertext: encrypted,
+	}.String(), nil
 }
 
-// decryptMnemonic decrypts a base58 encoded string with AES-256-GCM and returns the decrypted mnemonic phrase
-func (c *Client) decryptMnemonic(encrypted, pin string) (string, error) {
-	decoded, err := base58.Decode(encrypted)
+// encryptMnemonicWithDataKey encrypts a mnemonic phrase with a new data key
+// combined with the PIN derived key, and wraps the data key with the key provider.
+// The server-wide salt isn't mixed into the PIN key here, the key encryption key replaces it.
+func (c *Client) encryptMnemonicWithDataKey(ctx context.Context, mnemonic, pin string, salt []byte, ad AssociatedData) (string, error) {
+	dataKey := make([]byte, dataKeyLength)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	keyID, wrapped, err := c.keys.WrapKey(ctx, dataKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to decode base58 string: %w", err)
+		return "", fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	key, err := mnemonicKey(dataKey, deriveKey([]byte(pin), salt, c.kdf), salt)
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := encrypt([]byte(mnemonic), key, ad.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt mnemonic: %w", err)
+	}
+
+	return envelope{
+		Version:    envelopeVersion3,
+		KDF:        KDFArgon2id,
+		Params:     c.kdf,
+		Salt:       salt,
+		KeyID:      keyID,
+		WrappedKey: wrapped,
+		Ciphertext: encrypted,
+	}.String(), nil
+}
+
+// decryptMnemonic decrypts an envelope or a legacy base58 encoded string with AES-256-GCM
+// and returns the decrypted mnemonic phrase
+func (c *Client) decryptMnemonic(ctx context.Context, encrypted, pin string, ad AssociatedData) (string, error) {
+	if !isEnvelope(encrypted) {
+		if c.requireAD {
+			return "", ErrAssociatedDataRequired
+		}
+		return c.decryptLegacyMnemonic(encrypted, pin)
+	}
+
+	e, err := parseEnvelope(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse envelope: %w", err)
+	}
+	if e.Version == envelopeVersion1 && c.requireAD {
+		return "", ErrAssociatedDataRequired
+	}
+
+	if e.Version == envelopeVersion3 {
+		return c.decryptMnemonicWithDataKey(ctx, e, pin, ad)
+	}
+
+	var additionalData []byte
+	if e.Version == envelopeVersion2 {
+		additionalData = ad.Bytes()
+	}
+
+	for _, serverSalt := range c.serverSalts() {
+		var decrypted []byte
+		if decrypted, err = decrypt(e.Ciphertext, deriveSigningKey(pin, serverSalt, e.Salt, e.Params), additionalData); err == nil {
+			return string(decrypted), nil
+		}
 	}
 
-	decrypted, err := decrypt(decoded, c.signingKey(pin))
+	return "", fmt.Errorf("failed to decrypt mnemonic: %w", err)
+}
+
+// decryptMnemonicWithDataKey unwraps the data key of the envelope and decrypts the mnemonic phrase
+func (c *Client) decryptMnemonicWithDataKey(ctx context.Context, e envelope, pin string, ad AssociatedData) (string, error) {
+	if c.keys == nil {
+		return "", ErrKeyProviderNotConfigured
+	}
+
+	dataKey, err := c.keys.UnwrapKey(ctx, e.KeyID, e.WrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	key, err := mnemonicKey(dataKey, deriveKey([]byte(pin), e.Salt, e.Params), e.Salt)
+	if err != nil {
+		return "", err
+	}
+
+	decrypted, err := decrypt(e.Ciphertext, key, ad.Bytes())
 	if err != nil {
 		return "", fmt.Errorf("failed to decrypt mnemonic: %w", err)
 	}
 
 	return string(decrypted), nil
 }
+
+// decryptLegacyMnemonic decrypts a base58 encoded string encrypted with the sha256 derived key
+func (c *Client) decryptLegacyMnemonic(encrypted, pin string) (string, error) {
+	decoded, err := base58.Decode(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode base58 string: %w", err)
+	}
+
+	for _, serverSalt := range c.serverSalts() {
+		var decrypted []byte
+		if decrypted, err = decrypt(decoded, signingKey(pin, serverSalt), nil); err == nil {
+			return string(decrypted), nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to decrypt mnemonic: %w", err)
+}
+
+// validateSalt panics if the server-wide salt is too short
+func validateSalt(salt string) {
+	if l := len(salt); l < 16 {
+		panic(fmt.Sprintf("invalid salt length: %d, must be more than 16", l))
+	}
+}
@@ -1,15 +1,24 @@
 package solanawallet_test
 
 import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/dmitrymomot/random"
+	"github.com/dmitrymomot/solana-wallets/internal/kms"
 	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+	"github.com/mr-tron/base58"
 	"github.com/stretchr/testify/require"
 )
 
 func TestEncryptAndDecryptMnemonic(t *testing.T) {
+	ctx := context.Background()
 	pin := "123456"
 	mnemonic, err := solanawallet.NewMnemonic(solanawallet.MnemonicLength12)
 	require.NoError(t, err)
@@ -19,27 +28,288 @@ func TestEncryptAndDecryptMnemonic(t *testing.T) {
 
 	salt := random.String(20)
 	client := solanawallet.NewClient(salt)
+	ad := solanawallet.AssociatedData{UserID: "user-id", WalletID: "wallet-id", PublicKey: "public-key"}
 
-	encrypted, err := client.EnctyptMnemonic(mnemonic, pin)
+	encrypted, err := client.EnctyptMnemonic(ctx, mnemonic, pin, ad)
 	require.NoError(t, err)
 	require.NotEmpty(t, encrypted)
 
 	fmt.Println("encrypted mnemonic:", encrypted)
 
 	t.Run("decrypt with correct pin", func(t *testing.T) {
-		decrypted, err := client.DecryptMnemonic(encrypted, pin)
+		decrypted, err := client.DecryptMnemonic(ctx, encrypted, pin, ad)
 		require.NoError(t, err)
 		require.NotEmpty(t, decrypted)
 		require.Equal(t, mnemonic, decrypted)
 	})
 
 	t.Run("decrypt with incorrect pin", func(t *testing.T) {
-		_, err := client.DecryptMnemonic(encrypted, "654321")
-		require.Error(t, err)
+		_, err := client.DecryptMnemonic(ctx, encrypted, "654321", ad)
+		require.ErrorIs(t, err, solanawallet.ErrAuthenticationFailed)
 	})
 
 	t.Run("decrypt with incorrect salt", func(t *testing.T) {
-		_, err := solanawallet.NewClient(random.String(32)).DecryptMnemonic(encrypted, pin)
+		_, err := solanawallet.NewClient(random.String(32)).DecryptMnemonic(ctx, encrypted, pin, ad)
+		require.Error(t, err)
+	})
+
+	t.Run("decrypt with associated data of another record", func(t *testing.T) {
+		_, err := client.DecryptMnemonic(ctx, encrypted, pin, solanawallet.AssociatedData{
+			UserID:    "another-user-id",
+			WalletID:  ad.WalletID,
+			PublicKey: ad.PublicKey,
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestDecryptLegacyMnemonic(t *testing.T) {
+	ctx := context.Background()
+	pin := "1234"
+	salt := random.String(20)
+	mnemonic, err := solanawallet.NewMnemonic(solanawallet.MnemonicLength12)
+	require.NoError(t, err)
+
+	// legacy format: base58(nonce + AES-256-GCM(mnemonic, sha256(pin + salt)))
+	key := sha256.Sum256([]byte(pin + salt))
+	block, err := aes.NewCipher(key[:])
+	require.NoError(t, err)
+	aesGCM, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := make([]byte, aesGCM.NonceSize())
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+	legacy := base58.Encode(aesGCM.Seal(nonce, nonce, []byte(mnemonic), nil))
+
+	client := solanawallet.NewClient(salt)
+	ad := solanawallet.AssociatedData{UserID: "user-id", WalletID: "wallet-id", PublicKey: "public-key"}
+
+	t.Run("decrypt legacy mnemonic", func(t *testing.T) {
+		decrypted, err := client.DecryptMnemonic(ctx, legacy, pin, ad)
+		require.NoError(t, err)
+		require.Equal(t, mnemonic, decrypted)
+	})
+
+	t.Run("legacy mnemonic needs re-encryption", func(t *testing.T) {
+		require.True(t, client.NeedsReencryption(legacy))
+	})
+
+	t.Run("re-encrypted mnemonic is an envelope", func(t *testing.T) {
+		encrypted, err := client.EnctyptMnemonic(ctx, mnemonic, pin, ad)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(encrypted, "$v2$argon2id$"))
+		require.False(t, client.NeedsReencryption(encrypted))
+
+		decrypted, err := client.DecryptMnemonic(ctx, encrypted, pin, ad)
+		require.NoError(t, err)
+		require.Equal(t, mnemonic, decrypted)
+	})
+
+	t.Run("associated data required", func(t *testing.T) {
+		strict := solanawallet.NewClient(salt, solanawallet.WithRequireAssociatedData())
+		require.False(t, strict.HasAssociatedData(legacy))
+
+		_, err := strict.DecryptMnemonic(ctx, legacy, pin, ad)
+		require.ErrorIs(t, err, solanawallet.ErrAssociatedDataRequired)
+		require.NotErrorIs(t, err, solanawallet.ErrAuthenticationFailed)
+
+		encrypted, err := client.EnctyptMnemonic(ctx, mnemonic, pin, ad)
+		require.NoError(t, err)
+		require.True(t, strict.HasAssociatedData(encrypted))
+
+		decrypted, err := strict.DecryptMnemonic(ctx, encrypted, pin, ad)
+		require.NoError(t, err)
+		require.Equal(t, mnemonic, decrypted)
+	})
+}
+
+func TestKDFParams(t *testing.T) {
+	ctx := context.Background()
+	pin := "1234"
+	salt := random.String(20)
+	params := solanawallet.KDFParams{Time: 1, Memory: 64, Threads: 1}
+
+	client := solanawallet.NewClient(salt, solanawallet.WithKDFParams(params))
+	ad := solanawallet.AssociatedData{UserID: "user-id", WalletID: "wallet-id", PublicKey: "public-key"}
+	encrypted, err := client.EnctyptMnemonic(ctx, "test mnemonic", pin, ad)
+	require.NoError(t, err)
+	require.Contains(t, encrypted, params.String())
+
+	t.Run("params are read from the envelope", func(t *testing.T) {
+		decrypted, err := solanawallet.NewClient(salt).DecryptMnemonic(ctx, encrypted, pin, ad)
+		require.NoError(t, err)
+		require.Equal(t, "test mnemonic", decrypted)
+	})
+
+	t.Run("changed params require re-encryption", func(t *testing.T) {
+		require.False(t, client.NeedsReencryption(encrypted))
+		require.True(t, solanawallet.NewClient(salt).NeedsReencryption(encrypted))
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		require.Panics(t, func() {
+			solanawallet.WithKDFParams(solanawallet.KDFParams{Time: 0, Memory: 64, Threads: 1})
+		})
+	})
+}
+
+func TestPreviousSalts(t *testing.T) {
+	ctx := context.Background()
+	pin := "1234"
+	oldSalt := random.String(20)
+	newSalt := random.String(20)
+	params := solanawallet.KDFParams{Time: 1, Memory: 64, Threads: 1}
+	ad := solanawallet.AssociatedData{UserID: "user-id", WalletID: "wallet-id", PublicKey: "public-key"}
+
+	encrypted, err := solanawallet.NewClient(oldSalt, solanawallet.WithKDFParams(params)).EnctyptMnemonic(ctx, "test mnemonic", pin, ad)
+	require.NoError(t, err)
+
+	key := sha256.Sum256([]byte(pin + oldSalt))
+	block, err := aes.NewCipher(key[:])
+	require.NoError(t, err)
+	aesGCM, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := make([]byte, aesGCM.NonceSize())
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+	legacy := base58.Encode(aesGCM.Seal(nonce, nonce, []byte("test mnemonic"), nil))
+
+	client := solanawallet.NewClient(newSalt, solanawallet.WithKDFParams(params), solanawallet.WithPreviousSalts("", oldSalt))
+
+	t.Run("changed salt can't decrypt without the previous one", func(t *testing.T) {
+		_, err := solanawallet.NewClient(newSalt, solanawallet.WithKDFParams(params)).DecryptMnemonic(ctx, encrypted, pin, ad)
 		require.Error(t, err)
 	})
+
+	t.Run("decrypt with the previous salt", func(t *testing.T) {
+		decrypted, err := client.DecryptMnemonic(ctx, encrypted, pin, ad)
+		require.NoError(t, err)
+		require.Equal(t, "test mnemonic", decrypted)
+		require.True(t, client.NeedsReencryption(encrypted))
+	})
+
+	t.Run("decrypt legacy mnemonic with the previous salt", func(t *testing.T) {
+		decrypted, err := client.DecryptMnemonic(ctx, legacy, pin, ad)
+		require.NoError(t, err)
+		require.Equal(t, "test mnemonic", decrypted)
+	})
+
+	t.Run("wrong pin", func(t *testing.T) {
+		_, err := client.DecryptMnemonic(ctx, encrypted, "4321", ad)
+		require.Error(t, err)
+	})
+
+	t.Run("re-encrypted with the current salt", func(t *testing.T) {
+		reencrypted, err := client.EnctyptMnemonic(ctx, "test mnemonic", pin, ad)
+		require.NoError(t, err)
+
+		current := solanawallet.NewClient(newSalt, solanawallet.WithKDFParams(params))
+		decrypted, err := current.DecryptMnemonic(ctx, reencrypted, pin, ad)
+		require.NoError(t, err)
+		require.Equal(t, "test mnemonic", decrypted)
+		require.False(t, current.NeedsReencryption(reencrypted))
+	})
+
+	t.Run("invalid previous salt", func(t *testing.T) {
+		require.Panics(t, func() {
+			solanawallet.WithPreviousSalts("short")
+		})
+	})
+}
+
+func TestEnvelopeEncryption(t *testing.T) {
+	ctx := context.Background()
+	pin := "1234"
+	salt := random.String(20)
+	params := solanawallet.KDFParams{Time: 1, Memory: 64, Threads: 1}
+	ad := solanawallet.AssociatedData{UserID: "user-id", WalletID: "wallet-id", PublicKey: "public-key"}
+
+	oldKEK := make([]byte, kms.LocalKeySize)
+	newKEK := make([]byte, kms.LocalKeySize)
+	_, err := rand.Read(oldKEK)
+	require.NoError(t, err)
+	_, err = rand.Read(newKEK)
+	require.NoError(t, err)
+
+	oldKeys, err := kms.NewLocalProvider("old", map[string][]byte{"old": oldKEK})
+	require.NoError(t, err)
+	client := solanawallet.NewClient(salt, solanawallet.WithKDFParams(params), solanawallet.WithKeyProvider(oldKeys))
+
+	encrypted, err := client.EnctyptMnemonic(ctx, "test mnemonic", pin, ad)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(encrypted, "$v3$argon2id$"))
+	require.Contains(t, encrypted, "$old$")
+	require.False(t, client.NeedsReencryption(encrypted))
+
+	t.Run("decrypt with data key", func(t *testing.T) {
+		decrypted, err := client.DecryptMnemonic(ctx, encrypted, pin, ad)
+		require.NoError(t, err)
+		require.Equal(t, "test mnemonic", decrypted)
+	})
+
+	t.Run("decrypt with incorrect pin", func(t *testing.T) {
+		_, err := client.DecryptMnemonic(ctx, encrypted, "4321", ad)
+		require.ErrorIs(t, err, solanawallet.ErrAuthenticationFailed)
+	})
+
+	t.Run("decrypt without key provider", func(t *testing.T) {
+		_, err := solanawallet.NewClient(salt).DecryptMnemonic(ctx, encrypted, pin, ad)
+		require.ErrorIs(t, err, solanawallet.ErrKeyProviderNotConfigured)
+	})
+
+	t.Run("decrypt with unknown key encryption key", func(t *testing.T) {
+		keys, err := kms.NewLocalProvider("new", map[string][]byte{"new": newKEK})
+		require.NoError(t, err)
+		_, err = solanawallet.NewClient(salt, solanawallet.WithKeyProvider(keys)).DecryptMnemonic(ctx, encrypted, pin, ad)
+		require.Error(t, err)
+		require.NotErrorIs(t, err, solanawallet.ErrAuthenticationFailed)
+	})
+
+	t.Run("rotated key encryption key requires re-encryption", func(t *testing.T) {
+		keys, err := kms.NewLocalProvider("new", map[string][]byte{"new": newKEK, "old": oldKEK})
+		require.NoError(t, err)
+		rotated := solanawallet.NewClient(salt, solanawallet.WithKDFParams(params), solanawallet.WithKeyProvider(keys))
+		require.True(t, rotated.NeedsReencryption(encrypted))
+
+		decrypted, err := rotated.DecryptMnemonic(ctx, encrypted, pin, ad)
+		require.NoError(t, err)
+		require.Equal(t, "test mnemonic", decrypted)
+	})
+
+	t.Run("rewrap data key with rotated key encryption key", func(t *testing.T) {
+		keys, err := kms.NewLocalProvider("new", map[string][]byte{"new": newKEK, "old": oldKEK})
+		require.NoError(t, err)
+		rotated := solanawallet.NewClient(salt, solanawallet.WithKDFParams(params), solanawallet.WithKeyProvider(keys))
+
+		rewrapped, err := rotated.RewrapMnemonic(ctx, encrypted)
+		require.NoError(t, err)
+		require.Equal(t, "old", rotated.KeyVersion(encrypted))
+		require.Equal(t, "new", rotated.KeyVersion(rewrapped))
+		require.False(t, rotated.NeedsReencryption(rewrapped))
+
+		newOnly, err := kms.NewLocalProvider("new", map[string][]byte{"new": newKEK})
+		require.NoError(t, err)
+		decrypted, err := solanawallet.NewClient(salt, solanawallet.WithKeyProvider(newOnly)).DecryptMnemonic(ctx, rewrapped, pin, ad)
+		require.NoError(t, err)
+		require.Equal(t, "test mnemonic", decrypted)
+	})
+
+	t.Run("mnemonic encrypted without key provider can't be rewrapped", func(t *testing.T) {
+		v2, err := solanawallet.NewClient(salt, solanawallet.WithKDFParams(params)).EnctyptMnemonic(ctx, "test mnemonic", pin, ad)
+		require.NoError(t, err)
+		require.Empty(t, client.KeyVersion(v2))
+
+		_, err = client.RewrapMnemonic(ctx, v2)
+		require.ErrorIs(t, err, solanawallet.ErrNotEnvelopeEncrypted)
+	})
+
+	t.Run("mnemonic encrypted without key provider requires re-encryption", func(t *testing.T) {
+		v2, err := solanawallet.NewClient(salt, solanawallet.WithKDFParams(params)).EnctyptMnemonic(ctx, "test mnemonic", pin, ad)
+		require.NoError(t, err)
+		require.True(t, client.NeedsReencryption(v2))
+
+		decrypted, err := client.DecryptMnemonic(ctx, v2, pin, ad)
+		require.NoError(t, err)
+		require.Equal(t, "test mnemonic", decrypted)
+	})
 }
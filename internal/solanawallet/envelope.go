@@ -0,0 +1,111 @@
+package solanawallet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mr-tron/base58"
+)
+
+// Supported versions of the ciphertext envelope
+const (
+	envelopeVersion1 = "v1" // AES-256-GCM without associated data
+	envelopeVersion2 = "v2" // AES-256-GCM bound to the record with associated data
+	envelopeVersion3 = "v3" // v2 + per-record data key wrapped by the key encryption key
+)
+
+// envelopePrefix is used to distinguish envelopes from legacy base58 strings,
+// since "$" is not a part of the base58 alphabet.
+const envelopePrefix = "$"
+
+// envelope is a self-describing container for the encrypted data.
+// It records everything needed to decrypt the data except the key material:
+// $v2$argon2id$t=3,m=65536,p=2$<base58 salt>$<base58 ciphertext>
+// $v3$argon2id$t=3,m=65536,p=2$<base58 salt>$<kek id>$<base58 wrapped data key>$<base58 ciphertext>
+type envelope struct {
+	Version    string
+	KDF        string
+	Params     KDFParams
+	Salt       []byte
+	KeyID      string // v3 only
+	WrappedKey []byte // v3 only
+	Ciphertext []byte
+}
+
+// String encodes the envelope to the string representation
+func (e envelope) String() string {
+	parts := []string{
+		e.Version,
+		e.KDF,
+		e.Params.String(),
+		base58.Encode(e.Salt),
+	}
+	if e.Version == envelopeVersion3 {
+		parts = append(parts, e.KeyID, base58.Encode(e.WrappedKey))
+	}
+	parts = append(parts, base58.Encode(e.Ciphertext))
+
+	return envelopePrefix + strings.Join(parts, "$")
+}
+
+// isEnvelope returns true if the string is an envelope, false if it's a legacy base58 string
+func isEnvelope(s string) bool {
+	return strings.HasPrefix(s, envelopePrefix)
+}
+
+// parseEnvelope decodes the envelope from the string representation
+func parseEnvelope(s string) (envelope, error) {
+	if !isEnvelope(s) {
+		return envelope{}, fmt.Errorf("not an envelope")
+	}
+
+	parts := strings.Split(strings.TrimPrefix(s, envelopePrefix), "$")
+	switch {
+	case parts[0] == envelopeVersion1 || parts[0] == envelopeVersion2:
+		if len(parts) != 5 {
+			return envelope{}, fmt.Errorf("invalid envelope format")
+		}
+	case parts[0] == envelopeVersion3:
+		if len(parts) != 7 {
+			return envelope{}, fmt.Errorf("invalid envelope format")
+		}
+	default:
+		return envelope{}, fmt.Errorf("unsupported envelope version: %s", parts[0])
+	}
+	if parts[1] != KDFArgon2id {
+		return envelope{}, fmt.Errorf("unsupported kdf: %s", parts[1])
+	}
+
+	params, err := parseKDFParams(parts[2])
+	if err != nil {
+		return envelope{}, err
+	}
+
+	salt, err := base58.Decode(parts[3])
+	if err != nil || len(salt) != kdfSaltLength {
+		return envelope{}, fmt.Errorf("invalid envelope salt")
+	}
+
+	e := envelope{
+		Version: parts[0],
+		KDF:     parts[1],
+		Params:  params,
+		Salt:    salt,
+	}
+
+	if e.Version == envelopeVersion3 {
+		if parts[4] == "" {
+			return envelope{}, fmt.Errorf("invalid envelope key id")
+		}
+		e.KeyID = parts[4]
+		if e.WrappedKey, err = base58.Decode(parts[5]); err != nil || len(e.WrappedKey) == 0 {
+			return envelope{}, fmt.Errorf("invalid envelope wrapped key")
+		}
+	}
+
+	if e.Ciphertext, err = base58.Decode(parts[len(parts)-1]); err != nil {
+		return envelope{}, fmt.Errorf("failed to decode envelope ciphertext: %w", err)
+	}
+
+	return e, nil
+}
@@ -0,0 +1,14 @@
+package solanawallet
+
+import "errors"
+
+// Predefined package errors
+var (
+	ErrKeyProviderNotConfigured = errors.New("key provider is not configured")
+	ErrNotEnvelopeEncrypted     = errors.New("mnemonic is not protected with a wrapped data key")
+	ErrAssociatedDataRequired   = errors.New("mnemonic is not bound to the wallet with associated data")
+	// ErrAuthenticationFailed is returned if the ciphertext can't be opened with the PIN derived key,
+	// i.e. the PIN is wrong or the ciphertext doesn't belong to the record.
+	// Key provider and envelope format errors are returned as is.
+	ErrAuthenticationFailed = errors.New("mnemonic authentication failed")
+)
@@ -0,0 +1,72 @@
+package solanawallet
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Supported key derivation functions
+const (
+	KDFSHA256   = "sha256"   // legacy, used for the data encrypted before envelopes were introduced
+	KDFArgon2id = "argon2id" // memory-hard, used for all new data
+)
+
+// KDF limits
+const (
+	kdfSaltLength = 16
+	kdfKeyLength  = 32
+	kdfMaxTime    = 16
+	kdfMaxMemory  = 1024 * 1024 // 1 GiB, protects from envelopes with abusive params
+)
+
+// DefaultKDFParams is a set of Argon2id parameters used if no custom ones were set.
+// See recommendations in RFC 9106, section 4.
+var DefaultKDFParams = KDFParams{
+	Time:    3,
+	Memory:  64 * 1024,
+	Threads: 2,
+}
+
+// KDFParams is a set of Argon2id parameters
+type KDFParams struct {
+	Time    uint32 // number of passes over the memory
+	Memory  uint32 // memory size in KiB
+	Threads uint8  // degree of parallelism
+}
+
+// Validate checks if the params are in the allowed range
+func (p KDFParams) Validate() error {
+	if p.Time < 1 || p.Time > kdfMaxTime {
+		return fmt.Errorf("invalid kdf time: %d, must be between 1 and %d", p.Time, kdfMaxTime)
+	}
+	if p.Memory < 8*uint32(p.Threads) || p.Memory > kdfMaxMemory {
+		return fmt.Errorf("invalid kdf memory: %d KiB, must be between %d and %d", p.Memory, 8*uint32(p.Threads), kdfMaxMemory)
+	}
+	if p.Threads < 1 {
+		return fmt.Errorf("invalid kdf threads: %d, must be at least 1", p.Threads)
+	}
+	return nil
+}
+
+// String returns params in the PHC string format: t=3,m=65536,p=2
+func (p KDFParams) String() string {
+	return fmt.Sprintf("t=%d,m=%d,p=%d", p.Time, p.Memory, p.Threads)
+}
+
+// parseKDFParams parses params from the PHC string format: t=3,m=65536,p=2
+func parseKDFParams(s string) (KDFParams, error) {
+	var p KDFParams
+	if _, err := fmt.Sscanf(s, "t=%d,m=%d,p=%d", &p.Time, &p.Memory, &p.Threads); err != nil {
+		return KDFParams{}, fmt.Errorf("failed to parse kdf params: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return KDFParams{}, err
+	}
+	return p, nil
+}
+
+// deriveKey derives an encryption key from the password and salt with Argon2id
+func deriveKey(password, salt []byte, p KDFParams) []byte {
+	return argon2.IDKey(password, salt, p.Time, p.Memory, p.Threads, kdfKeyLength)
+}
@@ -0,0 +1,42 @@
+package solanawallet
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// dataKeyLength is the length of the per-record data encryption key
+const dataKeyLength = 32
+
+// dataKeyInfo is the HKDF context string binding the derived key to its purpose
+const dataKeyInfo = "solana-wallets/mnemonic/v3"
+
+// KeyProvider wraps and unwraps per-record data keys with a key encryption key.
+// See the internal/kms package for the implementations.
+type KeyProvider interface {
+	// CurrentKeyID returns the id of the key encryption key used to wrap new data keys.
+	CurrentKeyID() string
+	// WrapKey encrypts the data key with the current key encryption key.
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey decrypts the data key wrapped with the key encryption key with the given id.
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// mnemonicKey combines the unwrapped data key and the PIN derived key
+// into the key used to encrypt the mnemonic, so both are required to decrypt it.
+func mnemonicKey(dataKey, pinKey, salt []byte) ([]byte, error) {
+	ikm := make([]byte, 0, len(pinKey)+len(dataKey))
+	ikm = append(ikm, pinKey...)
+	ikm = append(ikm, dataKey...)
+
+	key := make([]byte, dataKeyLength)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte(dataKeyInfo)), key); err != nil {
+		return nil, fmt.Errorf("failed to derive mnemonic key: %w", err)
+	}
+
+	return key, nil
+}
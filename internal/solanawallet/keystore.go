@@ -0,0 +1,220 @@
+package solanawallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Keystore constants
+const (
+	KeystoreVersion = 1
+	keystoreCipher  = "aes-256-gcm"
+	keystoreKDF     = "scrypt"
+	keystoreKeyLen  = 32
+	keystoreSaltLen = 32
+)
+
+// Limits of the scrypt params accepted from the imported keystore files,
+// so a crafted file can't exhaust the server memory:
+// a derivation takes 128*N*r bytes, up to 256 MiB.
+const (
+	maxScryptN = 1 << 18
+	maxScryptR = 8
+	maxScryptP = 4
+)
+
+// ErrInvalidKeystore is returned if the keystore file can't be decrypted
+var ErrInvalidKeystore = errors.New("invalid keystore")
+
+// DefaultScryptParams is used to encrypt the exported keystore files
+var DefaultScryptParams = ScryptParams{N: 1 << 15, R: 8, P: 1}
+
+type (
+	// Keystore is a portable wallet file encrypted with the export password:
+	// the secret is encrypted with AES-256-GCM using the scrypt derived key.
+	Keystore struct {
+		Version   int            `json:"version"`
+		PublicKey string         `json:"public_key"`
+		Crypto    KeystoreCrypto `json:"crypto"`
+	}
+
+	// KeystoreCrypto holds the encrypted secret and everything needed to decrypt it, except the password
+	KeystoreCrypto struct {
+		Cipher     string       `json:"cipher"`
+		Ciphertext string       `json:"ciphertext"` // base64
+		Nonce      string       `json:"nonce"`      // base64
+		KDF        string       `json:"kdf"`
+		KDFParams  ScryptParams `json:"kdfparams"`
+	}
+
+	// ScryptParams is the scrypt key derivation params
+	ScryptParams struct {
+		N     int    `json:"n"`
+		R     int    `json:"r"`
+		P     int    `json:"p"`
+		DKLen int    `json:"dklen"`
+		Salt  string `json:"salt"` // base64
+	}
+)
+
+// Validate checks the scrypt params are in the allowed range
+func (p ScryptParams) Validate() error {
+	if p.N <= 1 || p.N&(p.N-1) != 0 || p.N > maxScryptN {
+		return fmt.Errorf("%w: scrypt n must be a power of 2 up to %d", ErrInvalidKeystore, maxScryptN)
+	}
+	if p.R < 1 || p.R > maxScryptR || p.P < 1 || p.P > maxScryptP {
+		return fmt.Errorf("%w: scrypt r and p are out of range", ErrInvalidKeystore)
+	}
+	return nil
+}
+
+// EncryptKeystore encrypts the wallet secret with the export password
+func EncryptKeystore(secret Secret, password string) (Keystore, error) {
+	return EncryptKeystoreWithParams(secret, password, DefaultScryptParams)
+}
+
+// EncryptKeystoreWithParams encrypts the wallet secret with the export password
+// using the given scrypt params, salt and key length are always generated
+func EncryptKeystoreWithParams(secret Secret, password string, params ScryptParams) (Keystore, error) {
+	if err := params.Validate(); err != nil {
+		return Keystore{}, err
+	}
+
+	acc, err := secret.DeriveAccount(0)
+	if err != nil {
+		return Keystore{}, fmt.Errorf("failed to derive wallet account: %w", err)
+	}
+
+	plaintext, err := secret.Encode()
+	if err != nil {
+		return Keystore{}, err
+	}
+
+	salt := make([]byte, keystoreSaltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return Keystore{}, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	params.Salt = base64.StdEncoding.EncodeToString(salt)
+	params.DKLen = keystoreKeyLen
+
+	aead, err := keystoreAEAD(password, salt, params)
+	if err != nil {
+		return Keystore{}, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return Keystore{}, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	ks := Keystore{
+		Version:   KeystoreVersion,
+		PublicKey: acc.PublicKey.ToBase58(),
+	}
+	ciphertext := aead.Seal(nil, nonce, []byte(plaintext), ks.additionalData())
+
+	ks.Crypto = KeystoreCrypto{
+		Cipher:     keystoreCipher,
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		KDF:        keystoreKDF,
+		KDFParams:  params,
+	}
+
+	return ks, nil
+}
+
+// ParseKeystore decodes the keystore file content
+func ParseKeystore(data []byte) (Keystore, error) {
+	var ks Keystore
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return Keystore{}, fmt.Errorf("%w: %s", ErrInvalidKeystore, err)
+	}
+	return ks, nil
+}
+
+// DecryptKeystore decrypts the wallet secret with the export password.
+// The public key derived from the decrypted secret must match the keystore one.
+func DecryptKeystore(ks Keystore, password string) (Secret, error) {
+	if ks.Version != KeystoreVersion {
+		return Secret{}, fmt.Errorf("%w: unsupported version %d", ErrInvalidKeystore, ks.Version)
+	}
+	if ks.Crypto.Cipher != keystoreCipher || ks.Crypto.KDF != keystoreKDF {
+		return Secret{}, fmt.Errorf("%w: unsupported cipher or kdf", ErrInvalidKeystore)
+	}
+	if ks.Crypto.KDFParams.DKLen != keystoreKeyLen {
+		return Secret{}, fmt.Errorf("%w: unsupported key length", ErrInvalidKeystore)
+	}
+	if err := ks.Crypto.KDFParams.Validate(); err != nil {
+		return Secret{}, err
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(ks.Crypto.KDFParams.Salt)
+	if err != nil || len(salt) == 0 {
+		return Secret{}, fmt.Errorf("%w: invalid salt", ErrInvalidKeystore)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(ks.Crypto.Nonce)
+	if err != nil {
+		return Secret{}, fmt.Errorf("%w: invalid nonce", ErrInvalidKeystore)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(ks.Crypto.Ciphertext)
+	if err != nil {
+		return Secret{}, fmt.Errorf("%w: invalid ciphertext", ErrInvalidKeystore)
+	}
+
+	aead, err := keystoreAEAD(password, salt, ks.Crypto.KDFParams)
+	if err != nil {
+		return Secret{}, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return Secret{}, fmt.Errorf("%w: invalid nonce", ErrInvalidKeystore)
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, ks.additionalData())
+	if err != nil {
+		return Secret{}, fmt.Errorf("%w: wrong password or corrupted file", ErrInvalidKeystore)
+	}
+
+	secret, err := DecodeSecret(string(plaintext))
+	if err != nil {
+		return Secret{}, fmt.Errorf("%w: %s", ErrInvalidKeystore, err)
+	}
+
+	acc, err := secret.DeriveAccount(0)
+	if err != nil {
+		return Secret{}, fmt.Errorf("%w: %s", ErrInvalidKeystore, err)
+	}
+	if acc.PublicKey.ToBase58() != ks.PublicKey {
+		return Secret{}, fmt.Errorf("%w: public key mismatch", ErrInvalidKeystore)
+	}
+
+	return secret, nil
+}
+
+// additionalData binds the ciphertext to the keystore version and public key
+func (ks Keystore) additionalData() []byte {
+	return []byte(fmt.Sprintf("solana-wallets-keystore-v%d:%s", ks.Version, ks.PublicKey))
+}
+
+// keystoreAEAD returns AES-256-GCM cipher with the scrypt derived key
+func keystoreAEAD(password string, salt []byte, p ScryptParams) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(password), salt, p.N, p.R, p.P, keystoreKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive keystore key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,74 @@
+package solanawallet_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeystore(t *testing.T) {
+	mnemonic, err := solanawallet.NewMnemonic(solanawallet.MnemonicLength12)
+	require.NoError(t, err)
+
+	secret := solanawallet.Secret{Mnemonic: mnemonic, Passphrase: "secret"}
+	params := solanawallet.ScryptParams{N: 1 << 10, R: 8, P: 1}
+
+	ks, err := solanawallet.EncryptKeystoreWithParams(secret, "export password", params)
+	require.NoError(t, err)
+	require.Equal(t, solanawallet.KeystoreVersion, ks.Version)
+
+	acc, err := secret.DeriveAccount(0)
+	require.NoError(t, err)
+	require.Equal(t, acc.PublicKey.ToBase58(), ks.PublicKey)
+
+	data, err := json.Marshal(ks)
+	require.NoError(t, err)
+
+	t.Run("decrypt", func(t *testing.T) {
+		parsed, err := solanawallet.ParseKeystore(data)
+		require.NoError(t, err)
+
+		decrypted, err := solanawallet.DecryptKeystore(parsed, "export password")
+		require.NoError(t, err)
+		require.Equal(t, secret, decrypted)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		_, err := solanawallet.DecryptKeystore(ks, "wrong password")
+		require.ErrorIs(t, err, solanawallet.ErrInvalidKeystore)
+	})
+
+	t.Run("tampered public key", func(t *testing.T) {
+		tampered := ks
+		tampered.PublicKey = solanawallet.NewAccount().PublicKey.ToBase58()
+		_, err := solanawallet.DecryptKeystore(tampered, "export password")
+		require.ErrorIs(t, err, solanawallet.ErrInvalidKeystore)
+	})
+
+	t.Run("too expensive kdf params", func(t *testing.T) {
+		expensive := ks
+		expensive.Crypto.KDFParams.N = 1 << 24
+		_, err := solanawallet.DecryptKeystore(expensive, "export password")
+		require.ErrorIs(t, err, solanawallet.ErrInvalidKeystore)
+
+		// 128 * 2^18 * 16 = 512 MiB
+		expensive.Crypto.KDFParams.N = 1 << 18
+		expensive.Crypto.KDFParams.R = 16
+		_, err = solanawallet.DecryptKeystore(expensive, "export password")
+		require.ErrorIs(t, err, solanawallet.ErrInvalidKeystore)
+	})
+
+	t.Run("mnemonic-less wallet", func(t *testing.T) {
+		acc := solanawallet.NewAccount()
+		secret := solanawallet.Secret{PrivateKey: solanawallet.AccountToBase58(acc)}
+
+		ks, err := solanawallet.EncryptKeystoreWithParams(secret, "export password", params)
+		require.NoError(t, err)
+
+		decrypted, err := solanawallet.DecryptKeystore(ks, "export password")
+		require.NoError(t, err)
+		require.Equal(t, secret, decrypted)
+	})
+}
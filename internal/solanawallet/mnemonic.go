@@ -2,15 +2,20 @@ package solanawallet
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/portto/solana-go-sdk/pkg/hdwallet"
 	"github.com/portto/solana-go-sdk/types"
 	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/text/unicode/norm"
 )
 
 // Predefined mnemonic lengths
 const (
 	MnemonicLength12 MnemonicLength = 128 // 128 bits of entropy, 12 words
+	MnemonicLength15 MnemonicLength = 160 // 160 bits of entropy, 15 words
+	MnemonicLength18 MnemonicLength = 192 // 192 bits of entropy, 18 words
+	MnemonicLength21 MnemonicLength = 224 // 224 bits of entropy, 21 words
 	MnemonicLength24 MnemonicLength = 256 // 256 bits of entropy, 24 words
 )
 
@@ -19,23 +24,52 @@ type MnemonicLength int
 
 // NewMnemonic generates a new mnemonic phrase
 func NewMnemonic(len MnemonicLength) (string, error) {
+	return NewMnemonicWithLanguage(len, LanguageEnglish)
+}
+
+// NewMnemonicWithLanguage generates a new mnemonic phrase with the words of the given BIP39 wordlist
+func NewMnemonicWithLanguage(len MnemonicLength, lang Language) (string, error) {
+	if err := lang.Validate(); err != nil {
+		return "", err
+	}
+
 	entropy, err := bip39.NewEntropy(int(len))
 	if err != nil {
 		return "", fmt.Errorf("failed to create new entropy: %w", err)
 	}
 
-	mnemonic, err := bip39.NewMnemonic(entropy)
-	if err != nil {
-		return "", fmt.Errorf("failed to create new mnemonic: %w", err)
-	}
+	words := wordlistsByLanguage[lang].mnemonicFromEntropy(entropy)
 
-	return mnemonic, nil
+	return norm.NFC.String(strings.Join(words, lang.separator())), nil
 }
 
 // DeriveAccountFromMnemonicBip44 derives an Solana account from a mnemonic phrase
 // Compatible with BIP44 (phantom wallet, etc.)
 func DeriveAccountFromMnemonicBip44(mnemonic string) (types.Account, error) {
-	acc, err := deriveFromMnemonicBip44(mnemonic, 0)
+	acc, err := deriveFromMnemonicBip44(mnemonic, "", 0)
+	if err != nil {
+		return types.Account{}, fmt.Errorf("failed to derive account from mnemonic: %w", err)
+	}
+
+	return acc, nil
+}
+
+// DeriveAccountFromMnemonicBip44WithIndex derives an Solana account with the given
+// account index from a mnemonic phrase: m/44'/501'/{index}'/0'.
+// Compatible with BIP44 (phantom wallet, solflare, etc.)
+func DeriveAccountFromMnemonicBip44WithIndex(mnemonic string, index int) (types.Account, error) {
+	return DeriveAccountFromMnemonicBip44WithPassphrase(mnemonic, "", index)
+}
+
+// DeriveAccountFromMnemonicBip44WithPassphrase derives an Solana account with the given
+// account index from a mnemonic phrase protected with the BIP39 passphrase ("25th word").
+// Compatible with the hardware wallets (ledger, trezor, etc.)
+func DeriveAccountFromMnemonicBip44WithPassphrase(mnemonic, passphrase string, index int) (types.Account, error) {
+	if index < 0 {
+		return types.Account{}, fmt.Errorf("invalid account index: %d", index)
+	}
+
+	acc, err := deriveFromMnemonicBip44(mnemonic, passphrase, index)
 	if err != nil {
 		return types.Account{}, fmt.Errorf("failed to derive account from mnemonic: %w", err)
 	}
@@ -45,11 +79,11 @@ func DeriveAccountFromMnemonicBip44(mnemonic string) (types.Account, error) {
 
 // deriveFromMnemonicBip44 derives an Solana account from a mnemonic phrase
 // Compatible with BIP44 (phantom wallet)
-func deriveFromMnemonicBip44(mnemonic string, path int) (types.Account, error) {
-	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
-	if err != nil {
+func deriveFromMnemonicBip44(mnemonic, passphrase string, path int) (types.Account, error) {
+	if _, err := ValidateMnemonic(mnemonic); err != nil {
 		return types.Account{}, fmt.Errorf("failed to create seed from mnemonic: %w", err)
 	}
+	seed := bip39.NewSeed(normalizeMnemonic(mnemonic), norm.NFKD.String(passphrase))
 
 	derivedKey, err := hdwallet.Derived(fmt.Sprintf("m/44'/501'/%d'/0'", path), seed)
 	if err != nil {
@@ -0,0 +1,176 @@
+package solanawallet_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveAccountFromMnemonicBip44WithIndex(t *testing.T) {
+	mnemonic, err := solanawallet.NewMnemonic(solanawallet.MnemonicLength12)
+	require.NoError(t, err)
+
+	primary, err := solanawallet.DeriveAccountFromMnemonicBip44(mnemonic)
+	require.NoError(t, err)
+
+	t.Run("index 0 is the primary account", func(t *testing.T) {
+		acc, err := solanawallet.DeriveAccountFromMnemonicBip44WithIndex(mnemonic, 0)
+		require.NoError(t, err)
+		require.Equal(t, primary.PublicKey.ToBase58(), acc.PublicKey.ToBase58())
+	})
+
+	t.Run("different indexes give different accounts", func(t *testing.T) {
+		acc1, err := solanawallet.DeriveAccountFromMnemonicBip44WithIndex(mnemonic, 1)
+		require.NoError(t, err)
+		acc2, err := solanawallet.DeriveAccountFromMnemonicBip44WithIndex(mnemonic, 2)
+		require.NoError(t, err)
+
+		require.NotEqual(t, primary.PublicKey.ToBase58(), acc1.PublicKey.ToBase58())
+		require.NotEqual(t, acc1.PublicKey.ToBase58(), acc2.PublicKey.ToBase58())
+	})
+
+	t.Run("negative index", func(t *testing.T) {
+		_, err := solanawallet.DeriveAccountFromMnemonicBip44WithIndex(mnemonic, -1)
+		require.Error(t, err)
+	})
+}
+
+func TestDeriveAccountWithPassphrase(t *testing.T) {
+	mnemonic, err := solanawallet.NewMnemonic(solanawallet.MnemonicLength12)
+	require.NoError(t, err)
+
+	primary, err := solanawallet.DeriveAccountFromMnemonicBip44(mnemonic)
+	require.NoError(t, err)
+
+	t.Run("empty passphrase gives the primary account", func(t *testing.T) {
+		acc, err := solanawallet.DeriveAccountFromMnemonicBip44WithPassphrase(mnemonic, "", 0)
+		require.NoError(t, err)
+		require.Equal(t, primary.PublicKey.ToBase58(), acc.PublicKey.ToBase58())
+	})
+
+	t.Run("passphrase gives another account", func(t *testing.T) {
+		acc, err := solanawallet.DeriveAccountFromMnemonicBip44WithPassphrase(mnemonic, "secret", 0)
+		require.NoError(t, err)
+		require.NotEqual(t, primary.PublicKey.ToBase58(), acc.PublicKey.ToBase58())
+	})
+
+	t.Run("secret encoding", func(t *testing.T) {
+		plain, err := solanawallet.Secret{Mnemonic: mnemonic}.Encode()
+		require.NoError(t, err)
+		require.Equal(t, mnemonic, plain)
+
+		secret := solanawallet.Secret{Mnemonic: mnemonic, Passphrase: "secret"}
+		encoded, err := secret.Encode()
+		require.NoError(t, err)
+
+		decoded, err := solanawallet.DecodeSecret(encoded)
+		require.NoError(t, err)
+		require.Equal(t, secret, decoded)
+
+		decoded, err = solanawallet.DecodeSecret(mnemonic)
+		require.NoError(t, err)
+		require.Equal(t, solanawallet.Secret{Mnemonic: mnemonic}, decoded)
+
+		acc, err := decoded.DeriveAccount(0)
+		require.NoError(t, err)
+		require.Equal(t, primary.PublicKey.ToBase58(), acc.PublicKey.ToBase58())
+	})
+}
+
+func TestMnemonicLanguages(t *testing.T) {
+	t.Run("known english vector", func(t *testing.T) {
+		lang, err := solanawallet.ValidateMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+		require.NoError(t, err)
+		require.Equal(t, solanawallet.LanguageEnglish, lang)
+	})
+
+	t.Run("invalid checksum", func(t *testing.T) {
+		_, err := solanawallet.ValidateMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon")
+		require.ErrorIs(t, err, solanawallet.ErrInvalidMnemonic)
+	})
+
+	t.Run("unsupported number of words", func(t *testing.T) {
+		_, err := solanawallet.ValidateMnemonic("abandon abandon abandon")
+		require.ErrorIs(t, err, solanawallet.ErrInvalidMnemonic)
+	})
+
+	t.Run("unsupported language", func(t *testing.T) {
+		_, err := solanawallet.NewMnemonicWithLanguage(solanawallet.MnemonicLength12, "klingon")
+		require.ErrorIs(t, err, solanawallet.ErrUnsupportedLanguage)
+	})
+
+	for _, words := range []int{12, 15, 18, 21, 24} {
+		length, err := solanawallet.MnemonicLengthFromWords(words)
+		require.NoError(t, err)
+
+		for _, lang := range []solanawallet.Language{
+			solanawallet.LanguageEnglish,
+			solanawallet.LanguageJapanese,
+			solanawallet.LanguageKorean,
+			solanawallet.LanguageSpanish,
+			solanawallet.LanguageChineseSimplified,
+			solanawallet.LanguageChineseTraditional,
+			solanawallet.LanguageFrench,
+			solanawallet.LanguageItalian,
+			solanawallet.LanguageCzech,
+		} {
+			t.Run(fmt.Sprintf("%s %d words", lang, words), func(t *testing.T) {
+				mnemonic, err := solanawallet.NewMnemonicWithLanguage(length, lang)
+				require.NoError(t, err)
+
+				_, err = solanawallet.ValidateMnemonic(mnemonic)
+				require.NoError(t, err)
+
+				_, err = solanawallet.DeriveAccountFromMnemonicBip44(mnemonic)
+				require.NoError(t, err)
+			})
+		}
+	}
+}
+
+func TestImportPrivateKey(t *testing.T) {
+	acc := solanawallet.NewAccount()
+
+	keypair := solanawallet.AccountToKeygenJSON(acc)
+	require.True(t, strings.HasPrefix(string(keypair), "["))
+
+	t.Run("base58 private key", func(t *testing.T) {
+		imported, err := solanawallet.ParsePrivateKey(solanawallet.AccountToBase58(acc))
+		require.NoError(t, err)
+		require.Equal(t, acc.PublicKey, imported.PublicKey)
+	})
+
+	t.Run("solana-keygen keypair", func(t *testing.T) {
+		imported, err := solanawallet.ParsePrivateKey(string(keypair))
+		require.NoError(t, err)
+		require.Equal(t, acc.PublicKey, imported.PublicKey)
+	})
+
+	t.Run("mismatched public key", func(t *testing.T) {
+		broken := append([]byte{}, acc.PrivateKey...)
+		broken[63] ^= 0xff
+		_, err := solanawallet.AccountFromPrivateKey(broken)
+		require.Error(t, err)
+	})
+
+	t.Run("mnemonic-less secret", func(t *testing.T) {
+		secret := solanawallet.Secret{PrivateKey: solanawallet.AccountToBase58(acc)}
+		require.False(t, secret.HasMnemonic())
+
+		encoded, err := secret.Encode()
+		require.NoError(t, err)
+		decoded, err := solanawallet.DecodeSecret(encoded)
+		require.NoError(t, err)
+		require.Equal(t, secret, decoded)
+
+		derived, err := decoded.DeriveAccount(0)
+		require.NoError(t, err)
+		require.Equal(t, acc.PublicKey, derived.PublicKey)
+
+		_, err = decoded.DeriveAccount(1)
+		require.ErrorIs(t, err, solanawallet.ErrNoMnemonic)
+	})
+}
@@ -0,0 +1,53 @@
+package solanawallet
+
+// WithKDFParams sets custom Argon2id parameters for the PIN key derivation.
+// Panics if the params are out of the allowed range.
+func WithKDFParams(p KDFParams) Option {
+	if err := p.Validate(); err != nil {
+		panic(err)
+	}
+	return func(c *Client) {
+		c.kdf = p
+	}
+}
+
+// WithKeyProvider enables the envelope encryption: every mnemonic is encrypted
+// with its own data key wrapped by the provider's key encryption key.
+// Mnemonics encrypted without the key provider are still readable
+// and get re-encrypted the next time the correct PIN is provided.
+func WithKeyProvider(p KeyProvider) Option {
+	if p == nil {
+		panic("key provider is nil")
+	}
+	return func(c *Client) {
+		c.keys = p
+	}
+}
+
+// WithPreviousSalts sets the server-wide salts used before the current one, to rotate the salt.
+// Mnemonics encrypted without the key provider are decrypted with the current salt first,
+// then with the previous ones, and get re-encrypted with the current salt
+// the next time the correct PIN is provided. Empty salts are skipped.
+// Panics if a salt is too short.
+func WithPreviousSalts(salts ...string) Option {
+	previous := make([]string, 0, len(salts))
+	for _, salt := range salts {
+		if salt == "" {
+			continue
+		}
+		validateSalt(salt)
+		previous = append(previous, salt)
+	}
+	return func(c *Client) {
+		c.previousSalts = previous
+	}
+}
+
+// WithRequireAssociatedData rejects the mnemonics which aren't bound to their record
+// with associated data, i.e. the legacy and v1 ones, with ErrAssociatedDataRequired.
+// Enable it once cmd/rotate-keys reports no unbound wallets left.
+func WithRequireAssociatedData() Option {
+	return func(c *Client) {
+		c.requireAD = true
+	}
+}
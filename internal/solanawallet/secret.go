@@ -0,0 +1,71 @@
+package solanawallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// ErrNoMnemonic is returned on attempt to derive an additional account
+// from the wallet imported with a private key
+var ErrNoMnemonic = errors.New("wallet has no mnemonic")
+
+// Secret is the wallet secret material encrypted under the PIN.
+// It holds either the mnemonic or, for the wallets imported from a private key, the private key.
+type Secret struct {
+	Mnemonic   string `json:"mnemonic,omitempty"`
+	Passphrase string `json:"passphrase,omitempty"`  // optional BIP39 passphrase ("25th word")
+	PrivateKey string `json:"private_key,omitempty"` // base58 encoded, for the mnemonic-less wallets only
+}
+
+// Encode encodes the secret to the plaintext to be encrypted.
+// Secrets with the mnemonic only are encoded as the bare mnemonic,
+// the same way as the wallets stored before the passphrase support.
+func (s Secret) Encode() (string, error) {
+	if s.Passphrase == "" && s.PrivateKey == "" {
+		return s.Mnemonic, nil
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode wallet secret: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// DecodeSecret decodes the decrypted plaintext to the secret.
+// A mnemonic never starts with "{", so it's safe to tell JSON from the bare mnemonic.
+func DecodeSecret(plaintext string) (Secret, error) {
+	if !strings.HasPrefix(plaintext, "{") {
+		return Secret{Mnemonic: plaintext}, nil
+	}
+
+	var s Secret
+	if err := json.Unmarshal([]byte(plaintext), &s); err != nil {
+		return Secret{}, fmt.Errorf("failed to decode wallet secret: %w", err)
+	}
+
+	return s, nil
+}
+
+// HasMnemonic returns false for the wallets imported from a private key
+func (s Secret) HasMnemonic() bool {
+	return s.Mnemonic != ""
+}
+
+// DeriveAccount derives the account with the given BIP44 index from the secret.
+// The mnemonic-less secret has the only account with index 0.
+func (s Secret) DeriveAccount(index int) (types.Account, error) {
+	if !s.HasMnemonic() {
+		if index != 0 {
+			return types.Account{}, ErrNoMnemonic
+		}
+		return AccountFromBase58(s.PrivateKey)
+	}
+
+	return DeriveAccountFromMnemonicBip44WithPassphrase(s.Mnemonic, s.Passphrase, index)
+}
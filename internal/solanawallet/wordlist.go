@@ -0,0 +1,193 @@
+package solanawallet
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Supported BIP39 wordlist languages
+const (
+	LanguageEnglish            Language = "english"
+	LanguageJapanese           Language = "japanese"
+	LanguageKorean             Language = "korean"
+	LanguageSpanish            Language = "spanish"
+	LanguageChineseSimplified  Language = "chinese_simplified"
+	LanguageChineseTraditional Language = "chinese_traditional"
+	LanguageFrench             Language = "french"
+	LanguageItalian            Language = "italian"
+	LanguageCzech              Language = "czech"
+)
+
+// Predefined wordlist errors
+var (
+	ErrUnsupportedLanguage = errors.New("unsupported mnemonic language")
+	ErrInvalidMnemonic     = errors.New("invalid mnemonic")
+)
+
+// Language is a BIP39 wordlist language
+type Language string
+
+// wordlist is a BIP39 wordlist with the reverse index.
+// Words are NFKD normalized, as BIP39 requires for the seed derivation.
+type wordlist struct {
+	words []string
+	index map[string]int
+}
+
+// languages in the order they are tried when detecting the mnemonic language
+var languages = []Language{
+	LanguageEnglish,
+	LanguageJapanese,
+	LanguageKorean,
+	LanguageSpanish,
+	LanguageChineseSimplified,
+	LanguageChineseTraditional,
+	LanguageFrench,
+	LanguageItalian,
+	LanguageCzech,
+}
+
+// the go-bip39 package keeps a single global wordlist, which isn't safe to switch
+// per request, so the wordlists are indexed here
+var wordlistsByLanguage = map[Language]wordlist{
+	LanguageEnglish:            newWordlist(wordlists.English),
+	LanguageJapanese:           newWordlist(wordlists.Japanese),
+	LanguageKorean:             newWordlist(wordlists.Korean),
+	LanguageSpanish:            newWordlist(wordlists.Spanish),
+	LanguageChineseSimplified:  newWordlist(wordlists.ChineseSimplified),
+	LanguageChineseTraditional: newWordlist(wordlists.ChineseTraditional),
+	LanguageFrench:             newWordlist(wordlists.French),
+	LanguageItalian:            newWordlist(wordlists.Italian),
+	LanguageCzech:              newWordlist(wordlists.Czech),
+}
+
+// newWordlist builds the normalized wordlist with the reverse index
+func newWordlist(words []string) wordlist {
+	wl := wordlist{
+		words: make([]string, len(words)),
+		index: make(map[string]int, len(words)),
+	}
+	for i, w := range words {
+		w = norm.NFKD.String(w)
+		wl.words[i] = w
+		wl.index[w] = i
+	}
+	return wl
+}
+
+// Validate returns ErrUnsupportedLanguage if the language is not supported
+func (l Language) Validate() error {
+	if _, ok := wordlistsByLanguage[l]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedLanguage, l)
+	}
+	return nil
+}
+
+// separator returns the words separator of the language.
+// Japanese mnemonics are joined with the ideographic space.
+func (l Language) separator() string {
+	if l == LanguageJapanese {
+		return "　"
+	}
+	return " "
+}
+
+// MnemonicLengthFromWords returns the mnemonic length for the given number of words
+func MnemonicLengthFromWords(words int) (MnemonicLength, error) {
+	switch words {
+	case 12:
+		return MnemonicLength12, nil
+	case 15:
+		return MnemonicLength15, nil
+	case 18:
+		return MnemonicLength18, nil
+	case 21:
+		return MnemonicLength21, nil
+	case 24:
+		return MnemonicLength24, nil
+	}
+	return 0, fmt.Errorf("%w: unsupported number of words: %d", ErrInvalidMnemonic, words)
+}
+
+// ValidateMnemonic checks the mnemonic words and checksum against all the supported wordlists.
+// Returns the language of the mnemonic.
+func ValidateMnemonic(mnemonic string) (Language, error) {
+	words := strings.Fields(normalizeMnemonic(mnemonic))
+	if _, err := MnemonicLengthFromWords(len(words)); err != nil {
+		return "", err
+	}
+
+	for _, lang := range languages {
+		if wordlistsByLanguage[lang].checksumValid(words) {
+			return lang, nil
+		}
+	}
+
+	return "", ErrInvalidMnemonic
+}
+
+// normalizeMnemonic returns the NFKD normalized mnemonic with the words separated by a single space
+func normalizeMnemonic(mnemonic string) string {
+	return strings.Join(strings.Fields(norm.NFKD.String(mnemonic)), " ")
+}
+
+// mnemonicFromEntropy encodes the entropy to the mnemonic words of the wordlist
+func (wl wordlist) mnemonicFromEntropy(entropy []byte) []string {
+	checksum := sha256.Sum256(entropy)
+	data := append(append(make([]byte, 0, len(entropy)+1), entropy...), checksum[0])
+
+	bits := len(entropy)*8 + len(entropy)/4 // entropy + checksum bits
+	words := make([]string, bits/11)
+	for i := range words {
+		words[i] = wl.words[readBits(data, i*11, 11)]
+	}
+
+	return words
+}
+
+// checksumValid returns true if all the words are in the wordlist and the checksum matches
+func (wl wordlist) checksumValid(words []string) bool {
+	bits := len(words) * 11
+	checksumBits := bits / 33
+	data := make([]byte, (bits+7)/8)
+
+	for i, w := range words {
+		idx, ok := wl.index[w]
+		if !ok {
+			return false
+		}
+		writeBits(data, i*11, 11, idx)
+	}
+
+	entropyLen := (bits - checksumBits) / 8
+	checksum := sha256.Sum256(data[:entropyLen])
+
+	return readBits(data, entropyLen*8, checksumBits) == int(checksum[0]>>(8-checksumBits))
+}
+
+// readBits reads n bits starting from the offset bit as a big-endian integer
+func readBits(data []byte, offset, n int) int {
+	v := 0
+	for i := offset; i < offset+n; i++ {
+		v <<= 1
+		if data[i/8]&(1<<(7-uint(i%8))) != 0 {
+			v |= 1
+		}
+	}
+	return v
+}
+
+// writeBits writes n lower bits of v starting from the offset bit
+func writeBits(data []byte, offset, n, v int) {
+	for i := 0; i < n; i++ {
+		if v&(1<<uint(n-1-i)) != 0 {
+			bit := offset + i
+			data[bit/8] |= 1 << (7 - uint(bit%8))
+		}
+	}
+}
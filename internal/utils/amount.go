@@ -1,11 +1,16 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 )
 
+// ErrInvalidAmount is returned by ParseAmount if the amount string is not a valid decimal amount
+var ErrInvalidAmount = errors.New("invalid amount")
+
 // AmountToFloat64 converts amount lamports to float64 with given decimals.
 func AmountToFloat64(amount uint64, decimals uint8) float64 {
 	return float64(amount) / math.Pow10(int(decimals))
@@ -41,3 +46,38 @@ func Float64ToString(amount float64) string {
 
 	return s
 }
+
+// ParseAmount converts the decimal amount string to lamports with given decimals without the float rounding.
+// For example, "1.5" with decimals 9 is converted to 1500000000.
+// Returns ErrInvalidAmount if the string is not a positive decimal number,
+// has more fractional digits than decimals or overflows uint64.
+func ParseAmount(s string, decimals uint8) (uint64, error) {
+	s = strings.TrimSpace(s)
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, ErrInvalidAmount
+	}
+	if len(frac) > int(decimals) {
+		return 0, fmt.Errorf("%w: too many decimal places, max %d", ErrInvalidAmount, decimals)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	digits := whole + frac + strings.Repeat("0", int(decimals)-len(frac))
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+		}
+	}
+
+	amount, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if amount == 0 {
+		return 0, fmt.Errorf("%w: must be greater than zero", ErrInvalidAmount)
+	}
+
+	return amount, nil
+}
@@ -161,3 +161,109 @@ func TestAmountToString(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAmount(t *testing.T) {
+	type args struct {
+		amount   string
+		decimals uint8
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    uint64
+		wantErr bool
+	}{
+		{
+			name: "1.5 with decimals 9",
+			args: args{
+				amount:   "1.5",
+				decimals: 9,
+			},
+			want: 1500000000,
+		},
+		{
+			name: "0.000000001 with decimals 9",
+			args: args{
+				amount:   "0.000000001",
+				decimals: 9,
+			},
+			want: 1,
+		},
+		{
+			name: ".25 with decimals 2",
+			args: args{
+				amount:   ".25",
+				decimals: 2,
+			},
+			want: 25,
+		},
+		{
+			name: "42 with decimals 0",
+			args: args{
+				amount:   "42",
+				decimals: 0,
+			},
+			want: 42,
+		},
+		{
+			name: "too many decimal places",
+			args: args{
+				amount:   "0.0000000001",
+				decimals: 9,
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero",
+			args: args{
+				amount:   "0.0",
+				decimals: 9,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative",
+			args: args{
+				amount:   "-1",
+				decimals: 9,
+			},
+			wantErr: true,
+		},
+		{
+			name: "exponent",
+			args: args{
+				amount:   "1e9",
+				decimals: 9,
+			},
+			wantErr: true,
+		},
+		{
+			name: "overflow",
+			args: args{
+				amount:   "18446744073.709551616",
+				decimals: 9,
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty",
+			args: args{
+				amount:   "",
+				decimals: 9,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := utils.ParseAmount(tt.args.amount, tt.args.decimals)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseAmount() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
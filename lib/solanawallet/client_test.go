@@ -1,6 +1,7 @@
 package solanawallet_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -10,6 +11,7 @@ import (
 )
 
 func TestEncryptAndDecryptMnemonic(t *testing.T) {
+	ctx := context.Background()
 	pin := "123456"
 	mnemonic, err := solanawallet.NewMnemonic(solanawallet.MnemonicLength12)
 	require.NoError(t, err)
@@ -19,27 +21,37 @@ func TestEncryptAndDecryptMnemonic(t *testing.T) {
 
 	salt := random.String(20)
 	client := solanawallet.NewClient(salt)
+	ad := solanawallet.AssociatedData{UserID: "user-id", WalletID: "wallet-id", PublicKey: "public-key"}
 
-	encrypted, err := client.EnctyptMnemonic(mnemonic, pin)
+	encrypted, err := client.EnctyptMnemonic(ctx, mnemonic, pin, ad)
 	require.NoError(t, err)
 	require.NotEmpty(t, encrypted)
 
 	fmt.Println("encrypted mnemonic:", encrypted)
 
 	t.Run("decrypt with correct pin", func(t *testing.T) {
-		decrypted, err := client.DecryptMnemonic(encrypted, pin)
+		decrypted, err := client.DecryptMnemonic(ctx, encrypted, pin, ad)
 		require.NoError(t, err)
 		require.NotEmpty(t, decrypted)
 		require.Equal(t, mnemonic, decrypted)
 	})
 
 	t.Run("decrypt with incorrect pin", func(t *testing.T) {
-		_, err := client.DecryptMnemonic(encrypted, "654321")
+		_, err := client.DecryptMnemonic(ctx, encrypted, "654321", ad)
 		require.Error(t, err)
 	})
 
 	t.Run("decrypt with incorrect salt", func(t *testing.T) {
-		_, err := solanawallet.NewClient(random.String(32)).DecryptMnemonic(encrypted, pin)
+		_, err := solanawallet.NewClient(random.String(32)).DecryptMnemonic(ctx, encrypted, pin, ad)
+		require.Error(t, err)
+	})
+
+	t.Run("decrypt with associated data of another record", func(t *testing.T) {
+		_, err := client.DecryptMnemonic(ctx, encrypted, pin, solanawallet.AssociatedData{
+			UserID:    "another-user-id",
+			WalletID:  ad.WalletID,
+			PublicKey: ad.PublicKey,
+		})
 		require.Error(t, err)
 	})
 }
@@ -3,7 +3,9 @@ package balance
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/dmitrymomot/solana-wallets/internal/events"
 	"github.com/dmitrymomot/solana/metadata"
 	"github.com/dmitrymomot/solana/token_metadata"
 	"github.com/dmitrymomot/solana/types"
@@ -27,6 +29,7 @@ type (
 	// service struct
 	service struct {
 		solana solanaClient
+		events events.Emitter
 	}
 
 	// solana rpc client interface
@@ -41,9 +44,10 @@ type (
 )
 
 // NewService is a factory function,
-// returns a new instance of the Service interface implementation
-func NewService(solana solanaClient) Service {
-	return &service{solana: solana}
+// returns a new instance of the Service interface implementation.
+// The emitter is optional, nil disables the events.
+func NewService(solana solanaClient, emitter events.Emitter) Service {
+	return &service{solana: solana, events: emitter}
 }
 
 // GetSOLBalance returns the SOL balance of a wallet
@@ -58,6 +62,8 @@ func (s *service) GetSOLBalance(ctx context.Context, walletAddr string) (Balance
 		return Balance{}, fmt.Errorf("failed to get SOL metadata: %w", err)
 	}
 
+	s.emitBalanceChecked(walletAddr, "SOL", types.NewDefaultTokenAmount(balance))
+
 	return Balance{
 		Pubkey:   walletAddr,
 		Mint:     "SOL",
@@ -74,6 +80,8 @@ func (s *service) GetTokenBalance(ctx context.Context, walletAddr, tokenMint str
 		return types.TokenAmount{}, fmt.Errorf("failed to get token balance: %w", err)
 	}
 
+	s.emitBalanceChecked(walletAddr, tokenMint, balance)
+
 	return balance, nil
 }
 
@@ -165,3 +173,18 @@ func (s *service) GetNonFungibleTokens(ctx context.Context, walletAddr string) (
 
 	return result, nil
 }
+
+// emitBalanceChecked fires the BalanceChecked event if the emitter is set
+func (s *service) emitBalanceChecked(walletAddr, mint string, balance types.TokenAmount) {
+	if s.events == nil {
+		return
+	}
+
+	s.events.Emit(events.BalanceChecked, events.BalanceCheckedPayload{
+		WalletAddress: walletAddr,
+		Mint:          mint,
+		Amount:        balance.Amount,
+		Decimals:      balance.Decimals,
+		OccurredAt:    time.Now().UTC(),
+	})
+}
@@ -0,0 +1,138 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net"
+	"net/http"
+
+	wallet_repository "github.com/dmitrymomot/solana-wallets/svc/wallet/repository"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+// Audited wallet operations
+const (
+	AuditActionStore           = "store"
+	AuditActionImport          = "import"
+	AuditActionExport          = "export"
+	AuditActionChangePIN       = "change_pin"
+	AuditActionRename          = "rename"
+	AuditActionDelete          = "delete"
+	AuditActionRestore         = "restore"
+	AuditActionSignMessage     = "sign_message"
+	AuditActionSignTransaction = "sign_transaction"
+	AuditActionSendTransaction = "send_transaction"
+	AuditActionUpdatePolicy    = "update_policy"
+	AuditActionTransfer        = "transfer"
+	AuditActionCreateNonce     = "create_nonce"
+	AuditActionCloseNonce      = "close_nonce"
+)
+
+// Audited operation outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AdminScope is the access token scope required to read the audit log of all users
+const AdminScope = "wallets:admin"
+
+// Audit log page size limits
+const (
+	DefaultAuditLogLimit = 50
+	MaxAuditLogLimit     = 100
+)
+
+type (
+	// auditRecord collects the audit log entry data while the operation is running
+	auditRecord struct {
+		userID   string
+		walletID uuid.UUID
+		action   string
+		details  map[string]interface{}
+	}
+
+	clientIPContextKey struct{}
+)
+
+// newAuditRecord returns the audit record of the given user action
+func newAuditRecord(uid, action string) *auditRecord {
+	return &auditRecord{
+		userID:  uid,
+		action:  action,
+		details: map[string]interface{}{},
+	}
+}
+
+// writeAudit appends the entry with the operation outcome to the audit log.
+// A failed write does not change the operation result, it's reported to the logger.
+func (s *service) writeAudit(ctx context.Context, a *auditRecord, err error) {
+	details, merr := json.Marshal(a.details)
+	if merr != nil {
+		details = []byte("{}")
+	}
+
+	entry := wallet_repository.CreateAuditLogEntryParams{
+		UserID:    a.userID,
+		WalletID:  uuid.NullUUID{UUID: a.walletID, Valid: a.walletID != uuid.Nil},
+		Action:    a.action,
+		Outcome:   AuditOutcomeSuccess,
+		Details:   details,
+		RequestID: nullString(middleware.GetReqID(ctx)),
+		IP:        nullString(clientIPFromContext(ctx)),
+	}
+	if err != nil {
+		entry.Outcome = AuditOutcomeFailure
+		entry.Error = nullString(err.Error())
+	}
+
+	if werr := s.repo.CreateAuditLogEntry(ctx, entry); werr != nil && s.log != nil {
+		s.log.Log(
+			"msg", "failed to write wallet audit log entry",
+			"err", werr,
+			"user_id", a.userID,
+			"action", a.action,
+		)
+	}
+}
+
+// clientIPToContext is a go-kit server before func, stores the client IP in the request context.
+// The chi RealIP middleware is expected to set the remote address from the proxy headers.
+func clientIPToContext(ctx context.Context, r *http.Request) context.Context {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return context.WithValue(ctx, clientIPContextKey{}, ip)
+}
+
+// clientIPFromContext returns the client IP stored in the request context
+func clientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPContextKey{}).(string)
+	return ip
+}
+
+// cast repository audit log model to the public audit log entry representation
+func castAuditLogEntry(e wallet_repository.WalletAuditLog) AuditLogEntry {
+	entry := AuditLogEntry{
+		ID:        e.ID.String(),
+		UserID:    e.UserID,
+		Action:    e.Action,
+		Outcome:   e.Outcome,
+		Error:     e.Error.String,
+		Details:   e.Details,
+		RequestID: e.RequestID.String,
+		IP:        e.IP.String,
+		CreatedAt: e.CreatedAt,
+	}
+	if e.WalletID.Valid {
+		entry.WalletID = e.WalletID.UUID.String()
+	}
+	return entry
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,53 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// Priority fee estimation defaults
+const (
+	// DefaultPriorityFeePercentile is the percentile of the recent prioritization fees used in the auto mode
+	DefaultPriorityFeePercentile = 75
+	// DefaultMaxComputeUnitPrice is the max compute unit price in micro-lamports,
+	// 0.001 SOL of the priority fee for the default 200k compute units
+	DefaultMaxComputeUnitPrice = 5_000_000
+)
+
+// resolve the compute budget of the transaction built by the server:
+// validate the requested limit and price, estimate the price in the auto mode
+// and calculate the priority fee for the given instructions.
+// The estimated price is capped by the max compute unit price, the requested one is rejected above it.
+func (s *service) resolveComputeBudget(ctx context.Context, budget ComputeBudget, instructions []types.Instruction) (ComputeBudget, error) {
+	if budget.UnitLimit > solanatx.MaxComputeUnitLimit {
+		return ComputeBudget{}, fmt.Errorf("%w: compute unit limit must not exceed %d", ErrInvalidParameter, solanatx.MaxComputeUnitLimit)
+	}
+	if budget.AutoPrice && budget.UnitPrice > 0 {
+		return ComputeBudget{}, fmt.Errorf("%w: compute unit price can't be set in the auto mode", ErrInvalidParameter)
+	}
+	if budget.UnitPrice > s.maxComputeUnitPrice {
+		return ComputeBudget{}, fmt.Errorf("%w: compute unit price must not exceed %d micro-lamports", ErrInvalidParameter, s.maxComputeUnitPrice)
+	}
+
+	if budget.AutoPrice {
+		fees, err := s.solana.GetRecentPrioritizationFees(ctx, solanatx.InstructionsWritableAccounts(instructions))
+		if err != nil {
+			return ComputeBudget{}, fmt.Errorf("failed to estimate compute unit price: %w", err)
+		}
+		budget.UnitPrice = solanatx.EstimateComputeUnitPrice(fees, s.priorityFeePercentile)
+		if budget.UnitPrice > s.maxComputeUnitPrice {
+			budget.UnitPrice = s.maxComputeUnitPrice
+		}
+	}
+
+	limit := budget.UnitLimit
+	if limit == 0 {
+		limit = solanatx.DefaultComputeUnitLimit(instructions)
+	}
+	budget.PriorityFee = solanatx.PriorityFee(limit, budget.UnitPrice)
+
+	return budget, nil
+}
@@ -2,8 +2,11 @@ package wallet
 
 import (
 	"context"
+	"strings"
 
 	"github.com/dmitrymomot/oauth2-server/lib/middleware"
+	"github.com/dmitrymomot/solana-wallets/internal/signingpolicy"
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
 	"github.com/dmitrymomot/solana-wallets/internal/validator"
 	"github.com/go-kit/kit/endpoint"
 )
@@ -13,14 +16,36 @@ type (
 	Endpoints struct {
 		GenerateWallet         endpoint.Endpoint
 		StoreWallet            endpoint.Endpoint
+		ImportWallet           endpoint.Endpoint
+		ImportKeystore         endpoint.Endpoint
+		ListWallets            endpoint.Endpoint
 		GetWallet              endpoint.Endpoint
 		DeleteWallet           endpoint.Endpoint
+		ListDeletedWallets     endpoint.Endpoint
+		RestoreWallet          endpoint.Endpoint
 		UpdateWalletName       endpoint.Endpoint
 		ChangeWalletPin        endpoint.Endpoint
 		ExportWallet           endpoint.Endpoint
+		AddAccount             endpoint.Endpoint
+		ListAccounts           endpoint.Endpoint
 		SignTransaction        endpoint.Endpoint
 		SignMessage            endpoint.Endpoint
 		SignAndSendTransaction endpoint.Endpoint
+		PreviewTransaction     endpoint.Endpoint
+		SimulateTransaction    endpoint.Endpoint
+		TransferSOL            endpoint.Endpoint
+		TransferToken          endpoint.Endpoint
+		TransferNFT            endpoint.Endpoint
+		ListTransactions       endpoint.Endpoint
+		GetTransaction         endpoint.Endpoint
+		CreateNonceAccount     endpoint.Endpoint
+		ListNonceAccounts      endpoint.Endpoint
+		GetNonceAccount        endpoint.Endpoint
+		CloseNonceAccount      endpoint.Endpoint
+		GetSigningPolicy       endpoint.Endpoint
+		UpdateSigningPolicy    endpoint.Endpoint
+		ListAuditLog           endpoint.Endpoint
+		AdminListAuditLog      endpoint.Endpoint
 	}
 )
 
@@ -29,14 +54,36 @@ func MakeEndpoints(s Service, m ...endpoint.Middleware) Endpoints {
 	e := Endpoints{
 		GenerateWallet:         MakeGenerateWalletEndpoint(s),
 		StoreWallet:            MakeStoreWalletEndpoint(s),
+		ImportWallet:           MakeImportWalletEndpoint(s),
+		ImportKeystore:         MakeImportKeystoreEndpoint(s),
+		ListWallets:            MakeListWalletsEndpoint(s),
 		GetWallet:              MakeGetWalletEndpoint(s),
 		DeleteWallet:           MakeDeleteWalletEndpoint(s),
+		ListDeletedWallets:     MakeListDeletedWalletsEndpoint(s),
+		RestoreWallet:          MakeRestoreWalletEndpoint(s),
 		UpdateWalletName:       MakeUpdateWalletNameEndpoint(s),
 		ChangeWalletPin:        MakeChangeWalletPinEndpoint(s),
 		ExportWallet:           MakeExportWalletEndpoint(s),
+		AddAccount:             MakeAddAccountEndpoint(s),
+		ListAccounts:           MakeListAccountsEndpoint(s),
 		SignTransaction:        MakeSignTransactionEndpoint(s),
 		SignMessage:            MakeSignMessageEndpoint(s),
 		SignAndSendTransaction: MakeSignAndSendTransactionEndpoint(s),
+		PreviewTransaction:     MakePreviewTransactionEndpoint(s),
+		SimulateTransaction:    MakeSimulateTransactionEndpoint(s),
+		TransferSOL:            MakeTransferSOLEndpoint(s),
+		TransferToken:          MakeTransferTokenEndpoint(s),
+		TransferNFT:            MakeTransferNFTEndpoint(s),
+		ListTransactions:       MakeListTransactionsEndpoint(s),
+		GetTransaction:         MakeGetTransactionEndpoint(s),
+		CreateNonceAccount:     MakeCreateNonceAccountEndpoint(s),
+		ListNonceAccounts:      MakeListNonceAccountsEndpoint(s),
+		GetNonceAccount:        MakeGetNonceAccountEndpoint(s),
+		CloseNonceAccount:      MakeCloseNonceAccountEndpoint(s),
+		GetSigningPolicy:       MakeGetSigningPolicyEndpoint(s),
+		UpdateSigningPolicy:    MakeUpdateSigningPolicyEndpoint(s),
+		ListAuditLog:           MakeListAuditLogEndpoint(s),
+		AdminListAuditLog:      MakeAdminListAuditLogEndpoint(s),
 	}
 
 	// setup middlewares for each endpoints
@@ -44,32 +91,70 @@ func MakeEndpoints(s Service, m ...endpoint.Middleware) Endpoints {
 		for _, mdw := range m {
 			e.GenerateWallet = mdw(e.GenerateWallet)
 			e.StoreWallet = mdw(e.StoreWallet)
+			e.ImportWallet = mdw(e.ImportWallet)
+			e.ImportKeystore = mdw(e.ImportKeystore)
+			e.ListWallets = mdw(e.ListWallets)
 			e.GetWallet = mdw(e.GetWallet)
 			e.DeleteWallet = mdw(e.DeleteWallet)
+			e.ListDeletedWallets = mdw(e.ListDeletedWallets)
+			e.RestoreWallet = mdw(e.RestoreWallet)
 			e.UpdateWalletName = mdw(e.UpdateWalletName)
 			e.ChangeWalletPin = mdw(e.ChangeWalletPin)
 			e.ExportWallet = mdw(e.ExportWallet)
+			e.AddAccount = mdw(e.AddAccount)
+			e.ListAccounts = mdw(e.ListAccounts)
 			e.SignTransaction = mdw(e.SignTransaction)
 			e.SignMessage = mdw(e.SignMessage)
 			e.SignAndSendTransaction = mdw(e.SignAndSendTransaction)
+			e.PreviewTransaction = mdw(e.PreviewTransaction)
+			e.SimulateTransaction = mdw(e.SimulateTransaction)
+			e.TransferSOL = mdw(e.TransferSOL)
+			e.TransferToken = mdw(e.TransferToken)
+			e.TransferNFT = mdw(e.TransferNFT)
+			e.ListTransactions = mdw(e.ListTransactions)
+			e.GetTransaction = mdw(e.GetTransaction)
+			e.CreateNonceAccount = mdw(e.CreateNonceAccount)
+			e.ListNonceAccounts = mdw(e.ListNonceAccounts)
+			e.GetNonceAccount = mdw(e.GetNonceAccount)
+			e.CloseNonceAccount = mdw(e.CloseNonceAccount)
+			e.GetSigningPolicy = mdw(e.GetSigningPolicy)
+			e.UpdateSigningPolicy = mdw(e.UpdateSigningPolicy)
+			e.ListAuditLog = mdw(e.ListAuditLog)
+			e.AdminListAuditLog = mdw(e.AdminListAuditLog)
 		}
 	}
 
 	return e
 }
 
+// GenerateWalletRequest is a request for GenerateWallet method
+type GenerateWalletRequest struct {
+	Words      int    `json:"words" validate:"enum:12,15,18,21,24" label:"Number of words"`
+	Language   string `json:"language" validate:"enum:english,japanese,korean,spanish,chinese_simplified,chinese_traditional,french,italian,czech" label:"Language"`
+	Passphrase string `json:"passphrase" validate:"maxLen:256" label:"Passphrase"`
+}
+
 // MakeGenerateWalletEndpoint returns an endpoint function for the GenerateWallet method.
 func MakeGenerateWalletEndpoint(s Service) endpoint.Endpoint {
-	return func(ctx context.Context, req interface{}) (interface{}, error) {
-		return s.GenerateWallet(ctx)
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(GenerateWalletRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.GenerateWallet(ctx, req.Words, req.Language, req.Passphrase)
 	}
 }
 
 // StoreWalletRequest is a request for StoreWallet method
 type StoreWalletRequest struct {
-	Name     string `json:"name" validate:"required|minLen:3|maxLen:50" label:"Name"`
-	Pin      string `json:"pin" validate:"required|minLen:4|maxLen:50" label:"PIN Code"`
-	Mnemonic string `json:"mnemonic" validate:"required" label:"Mnemonic"`
+	Name       string `json:"name" validate:"required|minLen:3|maxLen:50" label:"Name"`
+	Pin        string `json:"pin" validate:"required|minLen:4|maxLen:50" label:"PIN Code"`
+	Mnemonic   string `json:"mnemonic" validate:"required" label:"Mnemonic"`
+	Passphrase string `json:"passphrase" validate:"maxLen:256" label:"Passphrase"`
 }
 
 // MakeStoreWalletEndpoint returns an endpoint function for the StoreWallet method.
@@ -88,29 +173,104 @@ func MakeStoreWalletEndpoint(s Service) endpoint.Endpoint {
 			return nil, validator.NewValidationError(v)
 		}
 
-		if err := s.StoreWallet(ctx, userID, req.Pin, req.Mnemonic, req.Name); err != nil {
-			return nil, err
+		return s.StoreWallet(ctx, userID, req.Pin, req.Mnemonic, req.Passphrase, req.Name)
+	}
+}
+
+// ImportWalletRequest is a request for ImportWallet method.
+// PrivateKey is either the base58 encoded private key or the solana-keygen keypair file content.
+type ImportWalletRequest struct {
+	Name       string `json:"name" validate:"required|minLen:3|maxLen:50" label:"Name"`
+	Pin        string `json:"pin" validate:"required|minLen:4|maxLen:50" label:"PIN Code"`
+	PrivateKey string `json:"private_key" validate:"required" label:"Private Key"`
+}
+
+// MakeImportWalletEndpoint returns an endpoint function for the ImportWallet method.
+func MakeImportWalletEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
 		}
 
-		return true, nil
+		req, ok := request.(ImportWalletRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.ImportWallet(ctx, userID, req.Pin, req.PrivateKey, req.Name)
+	}
+}
+
+// ImportKeystoreRequest is a request for ImportKeystore method.
+// Keystore is the keystore file content returned by the ExportWallet method.
+type ImportKeystoreRequest struct {
+	Name     string `json:"name" validate:"required|minLen:3|maxLen:50" label:"Name"`
+	Pin      string `json:"pin" validate:"required|minLen:4|maxLen:50" label:"PIN Code"`
+	Keystore string `json:"keystore" validate:"required" label:"Keystore"`
+	Password string `json:"password" validate:"required" label:"Export Password"`
+}
+
+// MakeImportKeystoreEndpoint returns an endpoint function for the ImportKeystore method.
+func MakeImportKeystoreEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(ImportKeystoreRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.ImportKeystore(ctx, userID, req.Pin, req.Keystore, req.Password, req.Name)
+	}
+}
+
+// MakeListWalletsEndpoint returns an endpoint function for the ListWallets method.
+func MakeListWalletsEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		return s.ListWallets(ctx, userID)
 	}
 }
 
+// GetWalletRequest is a request for GetWallet method
+type GetWalletRequest struct {
+	UserID   string `json:"user_id" validate:"required" label:"User ID"`
+	WalletID string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+}
+
 // MakeGetWalletEndpoint returns an endpoint function for the GetWallet method.
 func MakeGetWalletEndpoint(s Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		userID, ok := request.(string)
+		req, ok := request.(GetWalletRequest)
 		if !ok {
 			return nil, ErrInvalidParameter
 		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
 
-		return s.GetWallet(ctx, userID)
+		return s.GetWallet(ctx, req.UserID, req.WalletID)
 	}
 }
 
 // DeleteWalletRequest is a request for DeleteWallet method
 type DeleteWalletRequest struct {
-	Pin string `json:"pin" validate:"required" label:"PIN Code"`
+	WalletID string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+	Pin      string `json:"pin" validate:"required" label:"PIN Code"`
 }
 
 // MakeDeleteWalletEndpoint returns an endpoint function for the DeleteWallet method.
@@ -129,7 +289,7 @@ func MakeDeleteWalletEndpoint(s Service) endpoint.Endpoint {
 			return nil, validator.NewValidationError(v)
 		}
 
-		if err := s.DeleteWallet(ctx, userID, req.Pin); err != nil {
+		if err := s.DeleteWallet(ctx, userID, req.WalletID, req.Pin); err != nil {
 			return nil, err
 		}
 
@@ -137,10 +297,49 @@ func MakeDeleteWalletEndpoint(s Service) endpoint.Endpoint {
 	}
 }
 
+// MakeListDeletedWalletsEndpoint returns an endpoint function for the ListDeletedWallets method.
+func MakeListDeletedWalletsEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		return s.ListDeletedWallets(ctx, userID)
+	}
+}
+
+// RestoreWalletRequest is a request for RestoreWallet method
+type RestoreWalletRequest struct {
+	WalletID string `json:"wallet_id" validate:"required|uuid" label:"Wallet ID"`
+	Pin      string `json:"pin" validate:"required" label:"PIN Code"`
+}
+
+// MakeRestoreWalletEndpoint returns an endpoint function for the RestoreWallet method.
+func MakeRestoreWalletEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(RestoreWalletRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.RestoreWallet(ctx, userID, req.WalletID, req.Pin)
+	}
+}
+
 // UpdateWalletNameRequest is a request for UpdateWalletName method
 type UpdateWalletNameRequest struct {
-	Pin  string `json:"pin" validate:"required" label:"PIN Code"`
-	Name string `json:"name" validate:"required|minLen:3|maxLen:50" label:"Name"`
+	WalletID string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+	Pin      string `json:"pin" validate:"required" label:"PIN Code"`
+	Name     string `json:"name" validate:"required|minLen:3|maxLen:50" label:"Name"`
 }
 
 // MakeUpdateWalletNameEndpoint returns an endpoint function for the UpdateWalletName method.
@@ -159,7 +358,7 @@ func MakeUpdateWalletNameEndpoint(s Service) endpoint.Endpoint {
 			return nil, validator.NewValidationError(v)
 		}
 
-		if err := s.UpdateWalletName(ctx, userID, req.Pin, req.Name); err != nil {
+		if err := s.UpdateWalletName(ctx, userID, req.WalletID, req.Pin, req.Name); err != nil {
 			return nil, err
 		}
 
@@ -169,8 +368,9 @@ func MakeUpdateWalletNameEndpoint(s Service) endpoint.Endpoint {
 
 // ChangeWalletPinRequest is a request for ChangeWalletPin method
 type ChangeWalletPinRequest struct {
-	Pin    string `json:"pin" validate:"required" label:"PIN Code"`
-	NewPin string `json:"new_pin" validate:"required|minLen:4|maxLen:50" label:"New PIN Code"`
+	WalletID string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+	Pin      string `json:"pin" validate:"required" label:"PIN Code"`
+	NewPin   string `json:"new_pin" validate:"required|minLen:4|maxLen:50" label:"New PIN Code"`
 }
 
 // MakeChangeWalletPinEndpoint returns an endpoint function for the ChangeWalletPin method.
@@ -189,7 +389,7 @@ func MakeChangeWalletPinEndpoint(s Service) endpoint.Endpoint {
 			return nil, validator.NewValidationError(v)
 		}
 
-		if err := s.ChangeWalletPin(ctx, userID, req.Pin, req.NewPin); err != nil {
+		if err := s.ChangeWalletPin(ctx, userID, req.WalletID, req.Pin, req.NewPin); err != nil {
 			return nil, err
 		}
 
@@ -197,9 +397,13 @@ func MakeChangeWalletPinEndpoint(s Service) endpoint.Endpoint {
 	}
 }
 
-// ExportWalletRequest is a request for ExportWallet method
+// ExportWalletRequest is a request for ExportWallet method.
+// Password is required for the keystore format only.
 type ExportWalletRequest struct {
-	Pin string `json:"pin" validate:"required" label:"PIN Code"`
+	WalletID string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+	Pin      string `json:"pin" validate:"required" label:"PIN Code"`
+	Format   string `json:"format" validate:"enum:plain,keypair,keystore" label:"Export Format"`
+	Password string `json:"password" validate:"minLen:8|maxLen:256" label:"Export Password"`
 }
 
 // MakeExportWalletEndpoint returns an endpoint function for the ExportWallet method.
@@ -218,14 +422,69 @@ func MakeExportWalletEndpoint(s Service) endpoint.Endpoint {
 			return nil, validator.NewValidationError(v)
 		}
 
-		return s.ExportWallet(ctx, userID, req.Pin)
+		return s.ExportWallet(ctx, userID, req.WalletID, req.Pin, req.Format, req.Password)
+	}
+}
+
+// AddAccountRequest is a request for AddAccount method
+type AddAccountRequest struct {
+	WalletID string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+	Pin      string `json:"pin" validate:"required" label:"PIN Code"`
+	Index    int    `json:"index" validate:"min:0" label:"Account index"`
+	Name     string `json:"name" validate:"minLen:3|maxLen:50" label:"Name"`
+}
+
+// MakeAddAccountEndpoint returns an endpoint function for the AddAccount method.
+func MakeAddAccountEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(AddAccountRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.AddAccount(ctx, userID, req.WalletID, req.Pin, req.Index, req.Name)
+	}
+}
+
+// ListAccountsRequest is a request for ListAccounts method
+type ListAccountsRequest struct {
+	WalletID string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+}
+
+// MakeListAccountsEndpoint returns an endpoint function for the ListAccounts method.
+func MakeListAccountsEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(ListAccountsRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.ListAccounts(ctx, userID, req.WalletID)
 	}
 }
 
 // SignTransactionRequest is a request for SignTransaction method
 type SignTransactionRequest struct {
-	Pin string `json:"pin" validate:"required" label:"PIN Code"`
-	Tx  string `json:"tx" validate:"required" label:"Base64 encoded transaction"`
+	WalletID     string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+	AccountIndex int    `json:"account_index" validate:"min:0" label:"Account index"`
+	Pin          string `json:"pin" validate:"required" label:"PIN Code"`
+	Tx           string `json:"tx" validate:"required" label:"Base64 encoded transaction"`
 }
 
 // MakeSignTransactionEndpoint returns an endpoint function for the SignTransaction method.
@@ -244,15 +503,17 @@ func MakeSignTransactionEndpoint(s Service) endpoint.Endpoint {
 			return nil, validator.NewValidationError(v)
 		}
 
-		return s.SignTransaction(ctx, userID, req.Pin, req.Tx)
+		return s.SignTransaction(ctx, userID, req.WalletID, req.AccountIndex, req.Pin, req.Tx)
 	}
 }
 
 type (
 	// SignMessageRequest is a request for SignMessage method
 	SignMessageRequest struct {
-		Pin string `json:"pin" validate:"required" label:"PIN Code"`
-		Msg string `json:"msg" validate:"required" label:"Message"`
+		WalletID     string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+		AccountIndex int    `json:"account_index" validate:"min:0" label:"Account index"`
+		Pin          string `json:"pin" validate:"required" label:"PIN Code"`
+		Msg          string `json:"msg" validate:"required" label:"Message"`
 	}
 
 	// SignMessageResponse is a response for SignMessage method
@@ -278,7 +539,7 @@ func MakeSignMessageEndpoint(s Service) endpoint.Endpoint {
 			return nil, validator.NewValidationError(v)
 		}
 
-		msg, sig, err := s.SignMessage(ctx, userID, req.Pin, req.Msg)
+		msg, sig, err := s.SignMessage(ctx, userID, req.WalletID, req.AccountIndex, req.Pin, req.Msg)
 		if err != nil {
 			return nil, err
 		}
@@ -293,13 +554,20 @@ func MakeSignMessageEndpoint(s Service) endpoint.Endpoint {
 type (
 	// SignAndSendTransactionRequest is a request for SignAndSendTransaction method
 	SignAndSendTransactionRequest struct {
-		Pin string `json:"pin" validate:"required" label:"PIN Code"`
-		Tx  string `json:"tx" validate:"required" label:"Base64 encoded transaction"`
+		WalletID     string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+		AccountIndex int    `json:"account_index" validate:"min:0" label:"Account index"`
+		Pin          string `json:"pin" validate:"required" label:"PIN Code"`
+		Tx           string `json:"tx" validate:"required" label:"Base64 encoded transaction"`
+		// Simulate the transaction before sending, the service default is used if not set
+		Simulate *bool `json:"simulate,omitempty" label:"Simulate before sending"`
+		// Send the transaction even if the simulation fails
+		IgnoreSimulationError bool `json:"ignore_simulation_error,omitempty" label:"Ignore simulation error"`
 	}
 
 	// SignAndSendTransactionResponse is a response for SignAndSendTransaction method
 	SignAndSendTransactionResponse struct {
-		TxSignature string `json:"tx_signature" label:"Transaction signature"`
+		TxSignature string               `json:"tx_signature" label:"Transaction signature"`
+		Simulation  *solanatx.Simulation `json:"simulation,omitempty" label:"Simulation result"`
 	}
 )
 
@@ -319,13 +587,526 @@ func MakeSignAndSendTransactionEndpoint(s Service) endpoint.Endpoint {
 			return nil, validator.NewValidationError(v)
 		}
 
-		sig, err := s.SignAndSendTransaction(ctx, userID, req.Pin, req.Tx)
+		sig, sim, err := s.SignAndSendTransaction(ctx, userID, req.WalletID, req.AccountIndex, req.Pin, req.Tx, SendOptions{
+			Simulate:              req.Simulate,
+			IgnoreSimulationError: req.IgnoreSimulationError,
+		})
 		if err != nil {
 			return nil, err
 		}
 
 		return SignAndSendTransactionResponse{
 			TxSignature: sig,
+			Simulation:  sim,
 		}, nil
 	}
 }
+
+// PreviewTransactionRequest is a request for PreviewTransaction method
+type PreviewTransactionRequest struct {
+	Tx string `json:"tx" validate:"required" label:"Base64 encoded transaction"`
+}
+
+// MakePreviewTransactionEndpoint returns an endpoint function for the PreviewTransaction method.
+func MakePreviewTransactionEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if _, ok := middleware.GetUserIDFromContext(ctx); !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(PreviewTransactionRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.PreviewTransaction(ctx, req.Tx)
+	}
+}
+
+// SimulateTransactionRequest is a request for SimulateTransaction method
+type SimulateTransactionRequest struct {
+	WalletID     string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+	AccountIndex int    `json:"account_index" validate:"min:0" label:"Account index"`
+	Tx           string `json:"tx" validate:"required" label:"Base64 encoded transaction"`
+}
+
+// MakeSimulateTransactionEndpoint returns an endpoint function for the SimulateTransaction method.
+func MakeSimulateTransactionEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(SimulateTransactionRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.SimulateTransaction(ctx, userID, req.WalletID, req.AccountIndex, req.Tx)
+	}
+}
+
+type (
+	// TransferSOLRequest is a request for TransferSOL method
+	TransferSOLRequest struct {
+		WalletID     string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+		AccountIndex int    `json:"account_index" validate:"min:0" label:"Account index"`
+		Pin          string `json:"pin" validate:"required" label:"PIN Code"`
+		Destination  string `json:"destination" validate:"required" label:"Destination address"`
+		Amount       string `json:"amount" validate:"required" label:"Amount"` // decimal string, e.g. "1.5"
+		Memo         string `json:"memo" validate:"maxLen:256" label:"Memo"`
+		TransferOptionsRequest
+	}
+
+	// TransferTokenRequest is a request for TransferToken method
+	TransferTokenRequest struct {
+		WalletID     string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+		AccountIndex int    `json:"account_index" validate:"min:0" label:"Account index"`
+		Pin          string `json:"pin" validate:"required" label:"PIN Code"`
+		Mint         string `json:"mint" validate:"required" label:"Token mint address"`
+		Destination  string `json:"destination" validate:"required" label:"Destination address"` // owner wallet address
+		Amount       string `json:"amount" validate:"required" label:"Amount"`                   // decimal string, e.g. "1.5"
+		Memo         string `json:"memo" validate:"maxLen:256" label:"Memo"`
+		TransferOptionsRequest
+	}
+
+	// TransferNFTRequest is a request for TransferNFT method
+	TransferNFTRequest struct {
+		WalletID     string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+		AccountIndex int    `json:"account_index" validate:"min:0" label:"Account index"`
+		Pin          string `json:"pin" validate:"required" label:"PIN Code"`
+		Mint         string `json:"mint" validate:"required" label:"NFT mint address"`
+		Destination  string `json:"destination" validate:"required" label:"Destination address"` // owner wallet address
+		Memo         string `json:"memo" validate:"maxLen:256" label:"Memo"`
+		TransferOptionsRequest
+	}
+
+	// TransferOptionsRequest is the optional compute budget and durable nonce account
+	// of the transactions built by the server
+	TransferOptionsRequest struct {
+		ComputeUnitLimit uint32 `json:"compute_unit_limit" validate:"max:1400000" label:"Compute unit limit"`
+		ComputeUnitPrice uint64 `json:"compute_unit_price" label:"Compute unit price"` // micro-lamports per compute unit
+		AutoPriorityFee  bool   `json:"auto_priority_fee" label:"Auto priority fee"`   // estimate the compute unit price
+		NonceAccount     string `json:"nonce_account" label:"Nonce account"`           // use the durable nonce instead of a recent blockhash
+	}
+
+	// TransferResponse is a response for the transfer methods.
+	// ComputeBudget is set if the compute unit limit or price was set.
+	TransferResponse struct {
+		TxSignature   string         `json:"tx_signature" label:"Transaction signature"`
+		ComputeBudget *ComputeBudget `json:"compute_budget,omitempty" label:"Compute budget"`
+	}
+)
+
+// transferOptions returns the requested transfer options
+func (r TransferOptionsRequest) transferOptions() TransferOptions {
+	return TransferOptions{
+		ComputeBudget: ComputeBudget{
+			UnitLimit: r.ComputeUnitLimit,
+			UnitPrice: r.ComputeUnitPrice,
+			AutoPrice: r.AutoPriorityFee,
+		},
+		NonceAccount: r.NonceAccount,
+	}
+}
+
+// newTransferResponse returns the transfer response, omitting the compute budget if it's not set
+func newTransferResponse(txSignature string, budget ComputeBudget) TransferResponse {
+	resp := TransferResponse{TxSignature: txSignature}
+	if budget.UnitLimit > 0 || budget.UnitPrice > 0 || budget.AutoPrice {
+		resp.ComputeBudget = &budget
+	}
+	return resp
+}
+
+// MakeTransferSOLEndpoint returns an endpoint function for the TransferSOL method.
+func MakeTransferSOLEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(TransferSOLRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		sig, budget, err := s.TransferSOL(ctx, userID, req.WalletID, req.AccountIndex, req.Pin, req.Destination, req.Amount, req.Memo, req.transferOptions())
+		if err != nil {
+			return nil, err
+		}
+
+		return newTransferResponse(sig, budget), nil
+	}
+}
+
+// MakeTransferTokenEndpoint returns an endpoint function for the TransferToken method.
+func MakeTransferTokenEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(TransferTokenRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		sig, budget, err := s.TransferToken(ctx, userID, req.WalletID, req.AccountIndex, req.Pin, req.Mint, req.Destination, req.Amount, req.Memo, req.transferOptions())
+		if err != nil {
+			return nil, err
+		}
+
+		return newTransferResponse(sig, budget), nil
+	}
+}
+
+// MakeTransferNFTEndpoint returns an endpoint function for the TransferNFT method.
+func MakeTransferNFTEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(TransferNFTRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		sig, budget, err := s.TransferNFT(ctx, userID, req.WalletID, req.AccountIndex, req.Pin, req.Mint, req.Destination, req.Memo, req.transferOptions())
+		if err != nil {
+			return nil, err
+		}
+
+		return newTransferResponse(sig, budget), nil
+	}
+}
+
+// ListTransactionsRequest is a request for ListTransactions method
+type ListTransactionsRequest struct {
+	WalletID string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+	Status   string `json:"status" validate:"enum:pending,processed,confirmed,finalized,failed,expired" label:"Status"`
+	Limit    int    `json:"limit" validate:"min:0|max:100" label:"Limit"`
+	Offset   int    `json:"offset" validate:"min:0" label:"Offset"`
+}
+
+// MakeListTransactionsEndpoint returns an endpoint function for the ListTransactions method.
+func MakeListTransactionsEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(ListTransactionsRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.ListTransactions(ctx, userID, req.WalletID, req.Status, req.Limit, req.Offset)
+	}
+}
+
+// GetTransactionRequest is a request for GetTransaction method
+type GetTransactionRequest struct {
+	Signature string `json:"signature" validate:"required" label:"Transaction signature"`
+}
+
+// MakeGetTransactionEndpoint returns an endpoint function for the GetTransaction method.
+func MakeGetTransactionEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(GetTransactionRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.GetTransaction(ctx, userID, req.Signature)
+	}
+}
+
+type (
+	// CreateNonceAccountRequest is a request for CreateNonceAccount method
+	CreateNonceAccountRequest struct {
+		WalletID     string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+		AccountIndex int    `json:"account_index" validate:"min:0" label:"Account index"`
+		Pin          string `json:"pin" validate:"required" label:"PIN Code"`
+	}
+
+	// CreateNonceAccountResponse is a response for CreateNonceAccount method
+	CreateNonceAccountResponse struct {
+		NonceAccount NonceAccount `json:"nonce_account" label:"Nonce account"`
+		TxSignature  string       `json:"tx_signature" label:"Transaction signature"`
+	}
+)
+
+// MakeCreateNonceAccountEndpoint returns an endpoint function for the CreateNonceAccount method.
+func MakeCreateNonceAccountEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(CreateNonceAccountRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		acc, sig, err := s.CreateNonceAccount(ctx, userID, req.WalletID, req.AccountIndex, req.Pin)
+		if err != nil {
+			return nil, err
+		}
+
+		return CreateNonceAccountResponse{
+			NonceAccount: acc,
+			TxSignature:  sig,
+		}, nil
+	}
+}
+
+// ListNonceAccountsRequest is a request for ListNonceAccounts method
+type ListNonceAccountsRequest struct {
+	WalletID string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+}
+
+// MakeListNonceAccountsEndpoint returns an endpoint function for the ListNonceAccounts method.
+func MakeListNonceAccountsEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(ListNonceAccountsRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.ListNonceAccounts(ctx, userID, req.WalletID)
+	}
+}
+
+// GetNonceAccountRequest is a request for GetNonceAccount method
+type GetNonceAccountRequest struct {
+	Address string `json:"address" validate:"required" label:"Nonce account address"`
+}
+
+// MakeGetNonceAccountEndpoint returns an endpoint function for the GetNonceAccount method.
+func MakeGetNonceAccountEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(GetNonceAccountRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.GetNonceAccount(ctx, userID, req.Address)
+	}
+}
+
+type (
+	// CloseNonceAccountRequest is a request for CloseNonceAccount method
+	CloseNonceAccountRequest struct {
+		Address string `json:"address" validate:"required" label:"Nonce account address"`
+		Pin     string `json:"pin" validate:"required" label:"PIN Code"`
+	}
+
+	// CloseNonceAccountResponse is a response for CloseNonceAccount method.
+	// TxSignature is empty if the account didn't exist on-chain.
+	CloseNonceAccountResponse struct {
+		TxSignature string `json:"tx_signature,omitempty" label:"Transaction signature"`
+	}
+)
+
+// MakeCloseNonceAccountEndpoint returns an endpoint function for the CloseNonceAccount method.
+func MakeCloseNonceAccountEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(CloseNonceAccountRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		sig, err := s.CloseNonceAccount(ctx, userID, req.Address, req.Pin)
+		if err != nil {
+			return nil, err
+		}
+
+		return CloseNonceAccountResponse{TxSignature: sig}, nil
+	}
+}
+
+// GetSigningPolicyRequest is a request for GetSigningPolicy method
+type GetSigningPolicyRequest struct {
+	WalletID string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+}
+
+// MakeGetSigningPolicyEndpoint returns an endpoint function for the GetSigningPolicy method.
+func MakeGetSigningPolicyEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(GetSigningPolicyRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.GetSigningPolicy(ctx, userID, req.WalletID)
+	}
+}
+
+// UpdateSigningPolicyRequest is a request for UpdateSigningPolicy method
+type UpdateSigningPolicyRequest struct {
+	WalletID string               `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+	Pin      string               `json:"pin" validate:"required" label:"PIN Code"`
+	Policy   signingpolicy.Policy `json:"policy" label:"Signing policy"`
+}
+
+// MakeUpdateSigningPolicyEndpoint returns an endpoint function for the UpdateSigningPolicy method.
+func MakeUpdateSigningPolicyEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(UpdateSigningPolicyRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.UpdateSigningPolicy(ctx, userID, req.WalletID, req.Pin, req.Policy)
+	}
+}
+
+// ListAuditLogRequest is a request for ListAuditLog method
+type ListAuditLogRequest struct {
+	WalletID string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+	Limit    int    `json:"limit" validate:"min:0|max:100" label:"Limit"`
+	Offset   int    `json:"offset" validate:"min:0" label:"Offset"`
+}
+
+// MakeListAuditLogEndpoint returns an endpoint function for the ListAuditLog method.
+// Lists the audit log of the current user only.
+func MakeListAuditLogEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		userID, ok := middleware.GetUserIDFromContext(ctx)
+		if !ok {
+			return nil, ErrUnauthorized
+		}
+
+		req, ok := request.(ListAuditLogRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.ListAuditLog(ctx, userID, req.WalletID, req.Limit, req.Offset)
+	}
+}
+
+// AdminListAuditLogRequest is a request for ListAuditLog method on behalf of an admin.
+// Empty UserID lists the audit log of all users.
+type AdminListAuditLogRequest struct {
+	UserID   string `json:"user_id" label:"User ID"`
+	WalletID string `json:"wallet_id" validate:"uuid" label:"Wallet ID"`
+	Limit    int    `json:"limit" validate:"min:0|max:100" label:"Limit"`
+	Offset   int    `json:"offset" validate:"min:0" label:"Offset"`
+}
+
+// MakeAdminListAuditLogEndpoint returns an endpoint function for the ListAuditLog method.
+// The access token must have the AdminScope.
+func MakeAdminListAuditLogEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if !hasScope(ctx, AdminScope) {
+			return nil, ErrForbidden
+		}
+
+		req, ok := request.(AdminListAuditLogRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.ListAuditLog(ctx, req.UserID, req.WalletID, req.Limit, req.Offset)
+	}
+}
+
+// hasScope reports whether the access token from the context has the given scope
+func hasScope(ctx context.Context, scope string) bool {
+	info, ok := middleware.GetTokenInfoFromContext(ctx)
+	if !ok || info == nil {
+		return false
+	}
+
+	for _, s := range strings.Fields(info.Scope) {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
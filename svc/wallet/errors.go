@@ -4,9 +4,22 @@ import "errors"
 
 // Predefined package errors
 var (
-	ErrInvalidParameter = errors.New("invalid parameter")
-	ErrNotFound         = errors.New("not found")
-	ErrInvalidPIN       = errors.New("invalid pin code")
-	ErrForbidden        = errors.New("forbidden")
-	ErrUnauthorized     = errors.New("unauthorized")
+	ErrInvalidParameter   = errors.New("invalid parameter")
+	ErrNotFound           = errors.New("not found")
+	ErrInvalidPIN         = errors.New("invalid pin code")
+	ErrForbidden          = errors.New("forbidden")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrTooManyAttempts    = errors.New("too many failed pin attempts")
+	ErrTooManyImports     = errors.New("too many keystore imports in progress, try again later")
+	ErrWalletLocked       = errors.New("wallet is locked")
+	ErrInvalidMnemonic    = errors.New("invalid mnemonic")
+	ErrInvalidPrivateKey  = errors.New("invalid private key")
+	ErrInvalidKeystore    = errors.New("invalid keystore")
+	ErrInvalidTransaction = errors.New("invalid transaction")
+	ErrSimulationFailed   = errors.New("transaction simulation failed")
+	ErrInvalidDestination = errors.New("invalid destination address")
+	ErrInvalidAmount      = errors.New("invalid amount")
+	ErrInsufficientFunds  = errors.New("insufficient funds")
+	ErrNoMnemonic         = errors.New("wallet imported from a private key has no mnemonic to derive accounts from")
 )
@@ -0,0 +1,34 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportKeystoreConcurrencyLimit(t *testing.T) {
+	secret := solanawallet.Secret{PrivateKey: solanawallet.AccountToBase58(solanawallet.NewAccount())}
+	ks, err := solanawallet.EncryptKeystoreWithParams(secret, "export password", solanawallet.ScryptParams{N: 1 << 10, R: 8, P: 1})
+	require.NoError(t, err)
+	data, err := json.Marshal(ks)
+	require.NoError(t, err)
+
+	s := NewService(&fakePolicyRepository{}, nil, nil, nil, WithMaxConcurrentKeystoreImports(1)).(*service)
+
+	t.Run("import above the limit is rejected", func(t *testing.T) {
+		s.keystoreImports <- struct{}{}
+		defer func() { <-s.keystoreImports }()
+
+		_, err := s.ImportKeystore(context.Background(), "user", "pin", string(data), "export password", "")
+		require.ErrorIs(t, err, ErrTooManyImports)
+	})
+
+	t.Run("slot is released after the import", func(t *testing.T) {
+		_, err := s.ImportKeystore(context.Background(), "user", "pin", string(data), "wrong password", "")
+		require.ErrorIs(t, err, ErrInvalidKeystore)
+		require.Empty(t, s.keystoreImports)
+	})
+}
@@ -0,0 +1,132 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/metaplex/token_metadata"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// ProgrammableTransferComputeUnits is the compute unit limit of the pNFT transfer transaction
+// if no custom limit is requested, the default limit is not always enough to evaluate the rule set
+const ProgrammableTransferComputeUnits = 400_000
+
+// Transfer the NFT from the wallet account to the destination wallet.
+// The transfer path is picked by the token standard: NFTs and print editions are sent with the token program,
+// pNFTs with the Token Metadata program, which checks the rule set and updates the token records.
+// The destination token account is created if it does not exist yet, paid by the source account.
+// Returns the transaction signature and the resolved compute budget.
+func (s *service) TransferNFT(ctx context.Context, uid, walletID string, accountIndex int, pin, mint, destination, memoText string, opts TransferOptions) (_ string, _ ComputeBudget, err error) {
+	a := newAuditRecord(uid, AuditActionTransfer)
+	a.details["account_index"] = accountIndex
+	a.details["mint"] = mint
+	a.details["destination"] = destination
+	a.details["amount"] = 1
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	if accountIndex < 0 || len(memoText) > MaxMemoLength {
+		return "", ComputeBudget{}, ErrInvalidParameter
+	}
+	if err := solanawallet.ValidateSolanaWalletAddr(mint); err != nil {
+		return "", ComputeBudget{}, fmt.Errorf("%w: invalid mint address", ErrInvalidParameter)
+	}
+
+	w, err := s.getWallet(ctx, uid, walletID)
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+	a.walletID = w.ID
+
+	source, err := s.accountPublicKey(ctx, w, accountIndex)
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+	if err := validateDestination(source, destination); err != nil {
+		return "", ComputeBudget{}, err
+	}
+
+	md, tokenProgram, err := s.getNFTMetadata(ctx, mint)
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+	a.details["programmable"] = md.IsProgrammable()
+
+	t, err := s.newTokenTransfer(ctx, source, destination, mint, tokenProgram)
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+	if t.balance < 1 {
+		return "", ComputeBudget{}, fmt.Errorf("%w: the wallet account doesn't hold the NFT", ErrInsufficientFunds)
+	}
+
+	var newAccounts []uint64
+	if t.createDestination {
+		newAccounts = append(newAccounts, solanatx.TokenAccountSize)
+	}
+
+	if !md.IsProgrammable() {
+		return s.sendTransfer(ctx, a, uid, walletID, accountIndex, pin, t.from, t.instructions(1, 0), memoText, opts, newAccounts)
+	}
+
+	// the program creates the destination token account and token record if missing
+	transfer, err := solanatx.ProgrammableTransfer(solanatx.ProgrammableTransferParam{
+		Source:           t.source,
+		SourceOwner:      t.from,
+		Destination:      t.destination,
+		DestinationOwner: t.to,
+		Mint:             t.mint,
+		Payer:            t.from,
+		RuleSet:          md.RuleSet,
+		TokenProgram:     t.tokenProgram,
+		Amount:           1,
+	})
+	if err != nil {
+		return "", ComputeBudget{}, fmt.Errorf("failed to build transfer instruction: %w", err)
+	}
+	if t.createDestination {
+		newAccounts = append(newAccounts, solanatx.TokenRecordSize)
+	}
+
+	if opts.ComputeBudget.UnitLimit == 0 {
+		opts.ComputeBudget.UnitLimit = ProgrammableTransferComputeUnits
+	}
+
+	return s.sendTransfer(ctx, a, uid, walletID, accountIndex, pin, t.from, []types.Instruction{transfer}, memoText, opts, newAccounts)
+}
+
+// getNFTMetadata loads the mint and its Metaplex metadata,
+// returns ErrInvalidParameter if the mint is not an NFT.
+// Returns the metadata and the token program id of the mint.
+func (s *service) getNFTMetadata(ctx context.Context, mint string) (solanatx.TokenMetadata, string, error) {
+	metadataAccount, err := token_metadata.GetTokenMetaPubkey(common.PublicKeyFromString(mint))
+	if err != nil {
+		return solanatx.TokenMetadata{}, "", fmt.Errorf("failed to derive metadata account: %w", err)
+	}
+
+	states, err := s.getAccountStates(ctx, mint, metadataAccount.ToBase58())
+	if err != nil {
+		return solanatx.TokenMetadata{}, "", fmt.Errorf("failed to get mint account: %w", err)
+	}
+
+	decimals, ok := solanatx.ParseMintDecimals(states[0])
+	if !ok {
+		return solanatx.TokenMetadata{}, "", fmt.Errorf("%w: %s is not a token mint", ErrInvalidParameter, mint)
+	}
+	if states[1] == nil || states[1].Owner != common.MetaplexTokenMetaProgramID.ToBase58() {
+		return solanatx.TokenMetadata{}, "", fmt.Errorf("%w: %s has no token metadata", ErrInvalidParameter, mint)
+	}
+
+	md, err := solanatx.ParseTokenMetadata(states[1].Data)
+	if err != nil {
+		return solanatx.TokenMetadata{}, "", fmt.Errorf("failed to get token metadata: %w", err)
+	}
+	if decimals != 0 || !md.IsNonFungible() {
+		return solanatx.TokenMetadata{}, "", fmt.Errorf("%w: %s is not an NFT", ErrInvalidParameter, mint)
+	}
+
+	return md, states[0].Owner, nil
+}
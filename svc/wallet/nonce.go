@@ -0,0 +1,257 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+	wallet_repository "github.com/dmitrymomot/solana-wallets/svc/wallet/repository"
+	"github.com/google/uuid"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/system"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// Create a durable nonce account owned by the wallet account.
+// The account address is derived from the wallet account with a random seed,
+// so the wallet account is the only signer. It's funded with the rent exempt minimum
+// from the wallet account and its authority is the wallet account.
+// Returns the nonce account and the creation transaction signature.
+func (s *service) CreateNonceAccount(ctx context.Context, uid, walletID string, accountIndex int, pin string) (_ NonceAccount, _ string, err error) {
+	a := newAuditRecord(uid, AuditActionCreateNonce)
+	a.details["account_index"] = accountIndex
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	if accountIndex < 0 {
+		return NonceAccount{}, "", ErrInvalidParameter
+	}
+
+	w, err := s.getWallet(ctx, uid, walletID)
+	if err != nil {
+		return NonceAccount{}, "", err
+	}
+	a.walletID = w.ID
+
+	source, err := s.accountPublicKey(ctx, w, accountIndex)
+	if err != nil {
+		return NonceAccount{}, "", err
+	}
+
+	rent, err := s.solana.GetMinimumBalanceForRentExemption(ctx, solanatx.NonceAccountSize)
+	if err != nil {
+		return NonceAccount{}, "", fmt.Errorf("failed to get rent exempt minimum: %w", err)
+	}
+
+	// the seed is limited to 32 bytes
+	seed := strings.ReplaceAll(uuid.New().String(), "-", "")
+	from := common.PublicKeyFromString(source)
+	nonceAccount := common.CreateWithSeed(from, seed, common.SystemProgramID)
+	a.details["nonce_account"] = nonceAccount.ToBase58()
+
+	instructions := []types.Instruction{
+		system.CreateAccountWithSeed(system.CreateAccountWithSeedParam{
+			From:     from,
+			New:      nonceAccount,
+			Base:     from,
+			Owner:    common.SystemProgramID,
+			Seed:     seed,
+			Lamports: rent,
+			Space:    solanatx.NonceAccountSize,
+		}),
+		system.InitializeNonceAccount(system.InitializeNonceAccountParam{
+			Nonce: nonceAccount,
+			Auth:  from,
+		}),
+	}
+
+	txSignature, _, err := s.sendTransfer(ctx, a, uid, w.ID.String(), accountIndex, pin, from, instructions, "", TransferOptions{}, []uint64{solanatx.NonceAccountSize})
+	if err != nil {
+		return NonceAccount{}, "", err
+	}
+
+	acc, err := s.repo.CreateWalletNonceAccount(ctx, wallet_repository.CreateWalletNonceAccountParams{
+		UserID:       uid,
+		WalletID:     uuid.NullUUID{UUID: w.ID, Valid: true},
+		AccountIndex: int32(accountIndex),
+		Address:      nonceAccount.ToBase58(),
+		Seed:         seed,
+	})
+	if err != nil {
+		return NonceAccount{}, "", fmt.Errorf("failed to store nonce account created by transaction %s: %w", txSignature, err)
+	}
+
+	// the nonce is not readable until the transaction is processed
+	result := castNonceAccount(acc)
+	result.Authority = source
+	result.Lamports = rent
+
+	return result, txSignature, nil
+}
+
+// List the open durable nonce accounts of the user's wallets with their on-chain state.
+// Empty walletID lists the nonce accounts of all user's wallets.
+func (s *service) ListNonceAccounts(ctx context.Context, uid, walletID string) ([]NonceAccount, error) {
+	params := wallet_repository.GetWalletNonceAccountsParams{UserID: uid}
+	if walletID != "" {
+		id, err := uuid.Parse(walletID)
+		if err != nil {
+			return nil, ErrInvalidParameter
+		}
+		params.WalletID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	accounts, err := s.repo.GetWalletNonceAccounts(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce accounts: %w", err)
+	}
+	if len(accounts) == 0 {
+		return []NonceAccount{}, nil
+	}
+
+	addresses := make([]string, 0, len(accounts))
+	for _, acc := range accounts {
+		addresses = append(addresses, acc.Address)
+	}
+	states, err := s.getAccountStates(ctx, addresses...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce account states: %w", err)
+	}
+
+	result := make([]NonceAccount, 0, len(accounts))
+	for i, acc := range accounts {
+		result = append(result, withNonceState(castNonceAccount(acc), states[i]))
+	}
+
+	return result, nil
+}
+
+// Get the open durable nonce account of the user's wallet with its on-chain state
+func (s *service) GetNonceAccount(ctx context.Context, uid, address string) (NonceAccount, error) {
+	return s.getNonceAccount(ctx, uid, address)
+}
+
+// Close the durable nonce account: withdraw all its lamports back to the wallet account
+// which closes the account. Nonce transactions signed with it can't be sent anymore.
+// If the account doesn't exist on-chain, it's closed without a transaction,
+// the signature is empty then.
+func (s *service) CloseNonceAccount(ctx context.Context, uid, address, pin string) (_ string, err error) {
+	a := newAuditRecord(uid, AuditActionCloseNonce)
+	a.details["nonce_account"] = address
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	acc, err := s.getWalletNonceAccount(ctx, uid, address)
+	if err != nil {
+		return "", err
+	}
+	// the wallet is purged, nobody can sign the withdrawal anymore
+	if !acc.WalletID.Valid {
+		return "", ErrNotFound
+	}
+	a.walletID = acc.WalletID.UUID
+	a.details["account_index"] = int(acc.AccountIndex)
+
+	w, err := s.getWallet(ctx, uid, acc.WalletID.UUID.String())
+	if err != nil {
+		return "", err
+	}
+	source, err := s.accountPublicKey(ctx, w, int(acc.AccountIndex))
+	if err != nil {
+		return "", err
+	}
+
+	states, err := s.getAccountStates(ctx, acc.Address)
+	if err != nil {
+		return "", fmt.Errorf("failed to get nonce account state: %w", err)
+	}
+
+	var txSignature string
+	if states[0] != nil {
+		state, ok := solanatx.ParseNonceAccount(states[0])
+		if ok && state.Authority != source {
+			return "", fmt.Errorf("%w: nonce account authority is not the wallet account", ErrForbidden)
+		}
+
+		from := common.PublicKeyFromString(source)
+		instructions := []types.Instruction{
+			system.WithdrawNonceAccount(system.WithdrawNonceAccountParam{
+				Nonce:  common.PublicKeyFromString(acc.Address),
+				Auth:   from,
+				To:     from,
+				Amount: states[0].Lamports,
+			}),
+		}
+		if txSignature, _, err = s.sendTransfer(ctx, a, uid, w.ID.String(), int(acc.AccountIndex), pin, from, instructions, "", TransferOptions{}, nil); err != nil {
+			return "", err
+		}
+	} else if _, err := s.getAccount(ctx, a, uid, w.ID.String(), int(acc.AccountIndex), pin); err != nil {
+		return "", err
+	}
+
+	if err := s.repo.CloseWalletNonceAccount(ctx, acc.ID); err != nil {
+		return "", fmt.Errorf("failed to close nonce account: %w", err)
+	}
+
+	return txSignature, nil
+}
+
+// getNonceAccount returns the open nonce account of the user's wallet with its on-chain state
+func (s *service) getNonceAccount(ctx context.Context, uid, address string) (NonceAccount, error) {
+	acc, err := s.getWalletNonceAccount(ctx, uid, address)
+	if err != nil {
+		return NonceAccount{}, err
+	}
+
+	states, err := s.getAccountStates(ctx, acc.Address)
+	if err != nil {
+		return NonceAccount{}, fmt.Errorf("failed to get nonce account state: %w", err)
+	}
+
+	return withNonceState(castNonceAccount(acc), states[0]), nil
+}
+
+// getWalletNonceAccount returns the stored open nonce account of the user's wallet
+func (s *service) getWalletNonceAccount(ctx context.Context, uid, address string) (wallet_repository.WalletNonceAccount, error) {
+	acc, err := s.repo.GetWalletNonceAccount(ctx, wallet_repository.GetWalletNonceAccountParams{
+		UserID:  uid,
+		Address: address,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return wallet_repository.WalletNonceAccount{}, ErrNotFound
+		}
+		return wallet_repository.WalletNonceAccount{}, fmt.Errorf("failed to get nonce account: %w", err)
+	}
+
+	return acc, nil
+}
+
+// withNonceState fills the on-chain state of the nonce account,
+// nil state means the account does not exist
+func withNonceState(acc NonceAccount, state *solanatx.AccountState) NonceAccount {
+	if state == nil {
+		return acc
+	}
+	acc.Lamports = state.Lamports
+	if nonce, ok := solanatx.ParseNonceAccount(state); ok {
+		acc.Authority = nonce.Authority
+		acc.Nonce = nonce.Nonce
+		acc.LamportsPerSignature = nonce.LamportsPerSignature
+	}
+	return acc
+}
+
+// cast repository nonce account model to the public nonce account representation
+func castNonceAccount(acc wallet_repository.WalletNonceAccount) NonceAccount {
+	result := NonceAccount{
+		Address:      acc.Address,
+		AccountIndex: int(acc.AccountIndex),
+		CreatedAt:    acc.CreatedAt,
+	}
+	if acc.WalletID.Valid {
+		result.WalletID = acc.WalletID.UUID.String()
+	}
+	return result
+}
@@ -0,0 +1,158 @@
+package wallet
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/dmitrymomot/solana-wallets/internal/signingpolicy"
+)
+
+type (
+	// Option is a function that configures the service
+	Option func(*service)
+
+	// PINLockoutPolicy defines how the service reacts on failed PIN attempts.
+	// After FreeAttempts failures in a row, every next failure locks the wallet
+	// for an exponentially growing delay, starting from BaseDelay and capped by MaxDelay.
+	// Once the number of failures reaches MaxAttempts, the wallet is locked permanently.
+	PINLockoutPolicy struct {
+		FreeAttempts int
+		BaseDelay    time.Duration
+		MaxDelay     time.Duration
+		MaxAttempts  int
+	}
+)
+
+// DefaultPINLockoutPolicy is used if no custom policy is provided
+var DefaultPINLockoutPolicy = PINLockoutPolicy{
+	FreeAttempts: 3,
+	BaseDelay:    30 * time.Second,
+	MaxDelay:     time.Hour,
+	MaxAttempts:  10,
+}
+
+// WithLogger sets the logger for the errors which must not fail the operation,
+// e.g. failed audit log writes
+func WithLogger(l logger) Option {
+	return func(s *service) {
+		s.log = l
+	}
+}
+
+// WithDB sets the database the repository is prepared on.
+// The changes which must be atomic run in its transactions,
+// e.g. the daily outflow limit check together with the outflow record.
+func WithDB(db *sql.DB) Option {
+	return func(s *service) {
+		s.db = db
+	}
+}
+
+// DefaultDeletionGracePeriod is how long a deleted wallet can be restored, if no custom period is provided
+const DefaultDeletionGracePeriod = 7 * 24 * time.Hour
+
+// WithDeletionGracePeriod sets how long a deleted wallet is kept pending deletion
+// and can be restored before it's purged. Zero value falls back to the default period.
+func WithDeletionGracePeriod(d time.Duration) Option {
+	return func(s *service) {
+		if d > 0 {
+			s.deletionGracePeriod = d
+		}
+	}
+}
+
+// WithDefaultSigningPolicy sets the signing policy applied to all wallets.
+// Wallet policies are applied on top of it.
+func WithDefaultSigningPolicy(p signingpolicy.Policy) Option {
+	return func(s *service) {
+		s.defaultPolicy = p
+	}
+}
+
+// WithSimulateBeforeSend sets whether transactions are simulated before sending by default,
+// callers can override it per request. Enabled by default.
+func WithSimulateBeforeSend(enabled bool) Option {
+	return func(s *service) {
+		s.simulateBeforeSending = enabled
+	}
+}
+
+// DefaultMaxConcurrentKeystoreImports is how many keystore files can be decrypted at once, if no custom limit is provided
+const DefaultMaxConcurrentKeystoreImports = 4
+
+// WithMaxConcurrentKeystoreImports limits how many keystore files can be decrypted at once,
+// the imports above the limit are rejected. Zero value falls back to the default limit.
+func WithMaxConcurrentKeystoreImports(n int) Option {
+	return func(s *service) {
+		if n > 0 {
+			s.keystoreImports = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithTransactionWatchBatchSize sets the number of sent transactions checked by the confirmation watcher at once.
+// Zero value falls back to the default size, the size is capped by the RPC method limit.
+func WithTransactionWatchBatchSize(n int) Option {
+	return func(s *service) {
+		if n > maxSignatureStatuses {
+			n = maxSignatureStatuses
+		}
+		if n > 0 {
+			s.txWatchBatchSize = n
+		}
+	}
+}
+
+// WithPriorityFeeEstimate sets the percentile (0-100) of the recent prioritization fees
+// used as the compute unit price in the auto mode, and the max compute unit price in micro-lamports
+// accepted from the caller or estimated. Zero values fall back to the defaults.
+func WithPriorityFeeEstimate(percentile int, maxUnitPrice uint64) Option {
+	return func(s *service) {
+		if percentile > 0 && percentile <= 100 {
+			s.priorityFeePercentile = percentile
+		}
+		if maxUnitPrice > 0 {
+			s.maxComputeUnitPrice = maxUnitPrice
+		}
+	}
+}
+
+// WithPINLockoutPolicy sets the policy for failed PIN attempts.
+// Zero values fall back to the default policy ones.
+func WithPINLockoutPolicy(p PINLockoutPolicy) Option {
+	return func(s *service) {
+		if p.FreeAttempts > 0 {
+			s.lockout.FreeAttempts = p.FreeAttempts
+		}
+		if p.BaseDelay > 0 {
+			s.lockout.BaseDelay = p.BaseDelay
+		}
+		if p.MaxDelay > 0 {
+			s.lockout.MaxDelay = p.MaxDelay
+		}
+		if p.MaxAttempts > 0 {
+			s.lockout.MaxAttempts = p.MaxAttempts
+		}
+	}
+}
+
+// lockDuration returns the delay to lock the wallet for after the given number of failed attempts.
+// Zero means the wallet must not be locked.
+func (p PINLockoutPolicy) lockDuration(failed int) time.Duration {
+	if failed <= p.FreeAttempts {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failed; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,173 @@
+package wallet
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmitrymomot/solana-wallets/internal/kms"
+	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+	wallet_repository "github.com/dmitrymomot/solana-wallets/svc/wallet/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// fakePINRepository keeps a single wallet and its pin lockout state in memory,
+	// transactions hold the wallet row lock, the other repository methods are not implemented
+	fakePINRepository struct {
+		walletRepository
+		row    sync.Mutex
+		mu     sync.Mutex
+		wallet wallet_repository.Wallet
+	}
+
+	// failingKeys is a key provider which can't unwrap any data key, e.g. during a KMS outage
+	failingKeys struct {
+		solanawallet.KeyProvider
+	}
+)
+
+func (r *fakePINRepository) InTx(ctx context.Context, fn func(repo walletRepository) error) error {
+	r.row.Lock()
+	defer r.row.Unlock()
+	return fn(r)
+}
+
+func (r *fakePINRepository) GetWalletForUpdate(ctx context.Context, id uuid.UUID) (wallet_repository.Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.wallet, nil
+}
+
+func (r *fakePINRepository) IncrementFailedPINAttempts(ctx context.Context, id uuid.UUID) (int32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.wallet.FailedPinAttempts++
+	return r.wallet.FailedPinAttempts, nil
+}
+
+func (r *fakePINRepository) LockWallet(ctx context.Context, arg wallet_repository.LockWalletParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if arg.LockedUntil.Valid && (!r.wallet.LockedUntil.Valid || arg.LockedUntil.Time.After(r.wallet.LockedUntil.Time)) {
+		r.wallet.LockedUntil = arg.LockedUntil
+	}
+	r.wallet.IsLocked = r.wallet.IsLocked || arg.IsLocked
+	return nil
+}
+
+func (r *fakePINRepository) ResetFailedPINAttempts(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.wallet.FailedPinAttempts = 0
+	r.wallet.LockedUntil.Valid = false
+	return nil
+}
+
+func (k failingKeys) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	return nil, errors.New("key service is unavailable")
+}
+
+func TestDecryptSecretFailedAttempts(t *testing.T) {
+	ctx := context.Background()
+	salt := "0123456789abcdef"
+	params := solanawallet.KDFParams{Time: 1, Memory: 64, Threads: 1}
+
+	kek := make([]byte, kms.LocalKeySize)
+	_, err := rand.Read(kek)
+	require.NoError(t, err)
+	keys, err := kms.NewLocalProvider("current", map[string][]byte{"current": kek})
+	require.NoError(t, err)
+	client := solanawallet.NewClient(salt, solanawallet.WithKDFParams(params), solanawallet.WithKeyProvider(keys))
+
+	newRepo := func(t *testing.T) *fakePINRepository {
+		w := wallet_repository.Wallet{ID: uuid.New(), UserID: "user", PublicKey: "public-key"}
+		encrypted, err := client.EnctyptMnemonic(ctx, "test mnemonic", "1234", associatedData(w))
+		require.NoError(t, err)
+		w.Mnemonic = encrypted
+		return &fakePINRepository{wallet: w}
+	}
+
+	t.Run("wrong pin is counted", func(t *testing.T) {
+		repo := newRepo(t)
+		s := NewService(repo, client, nil, nil).(*service)
+
+		w := repo.wallet
+		_, err := s.decryptSecret(ctx, &w, "4321")
+		require.ErrorIs(t, err, ErrInvalidPIN)
+		require.EqualValues(t, 1, repo.wallet.FailedPinAttempts)
+	})
+
+	t.Run("key provider failure is not counted", func(t *testing.T) {
+		repo := newRepo(t)
+		broken := solanawallet.NewClient(salt, solanawallet.WithKDFParams(params), solanawallet.WithKeyProvider(failingKeys{}))
+		s := NewService(repo, broken, nil, nil).(*service)
+
+		for i := 0; i < DefaultPINLockoutPolicy.MaxAttempts+1; i++ {
+			w := repo.wallet
+			_, err := s.decryptSecret(ctx, &w, "1234")
+			require.Error(t, err)
+			require.NotErrorIs(t, err, ErrInvalidPIN)
+			require.NotErrorIs(t, err, ErrWalletLocked)
+		}
+		require.Zero(t, repo.wallet.FailedPinAttempts)
+		require.False(t, repo.wallet.IsLocked)
+		require.False(t, repo.wallet.LockedUntil.Valid)
+	})
+	t.Run("lockout state is read under the row lock", func(t *testing.T) {
+		repo := newRepo(t)
+		s := NewService(repo, client, nil, nil).(*service)
+
+		w := repo.wallet // stale snapshot taken before the wallet is locked
+		repo.wallet.IsLocked = true
+		_, err := s.decryptSecret(ctx, &w, "1234")
+		require.ErrorIs(t, err, ErrWalletLocked)
+	})
+
+	t.Run("concurrent attempts don't exceed the lockout policy", func(t *testing.T) {
+		repo := newRepo(t)
+		s := NewService(repo, client, nil, nil, WithPINLockoutPolicy(PINLockoutPolicy{
+			FreeAttempts: 1,
+			BaseDelay:    time.Hour,
+			MaxDelay:     time.Hour,
+			MaxAttempts:  10,
+		})).(*service)
+
+		const requests = 20
+		errs := make(chan error, requests)
+		snapshot := repo.wallet // every request starts with the same wallet state
+		var wg sync.WaitGroup
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w := snapshot
+				_, err := s.decryptSecret(ctx, &w, "4321")
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		var invalid, throttled int
+		for err := range errs {
+			switch {
+			case errors.Is(err, ErrInvalidPIN):
+				invalid++
+			case errors.Is(err, ErrTooManyAttempts):
+				throttled++
+			default:
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		require.Equal(t, 1, invalid)
+		require.Equal(t, requests-1, throttled)
+		require.EqualValues(t, 2, repo.wallet.FailedPinAttempts)
+		require.True(t, repo.wallet.LockedUntil.Valid)
+		require.False(t, repo.wallet.IsLocked)
+	})
+}
@@ -0,0 +1,188 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dmitrymomot/solana-wallets/internal/signingpolicy"
+	wallet_repository "github.com/dmitrymomot/solana-wallets/svc/wallet/repository"
+	"github.com/google/uuid"
+)
+
+// outflowWindow is the period the daily outflow limits are checked against
+const outflowWindow = 24 * time.Hour
+
+// Get the wallet signing policy, the effective one with the defaults applied and today's outflows
+func (s *service) GetSigningPolicy(ctx context.Context, uid, walletID string) (SigningPolicy, error) {
+	w, err := s.getWallet(ctx, uid, walletID)
+	if err != nil {
+		return SigningPolicy{}, err
+	}
+
+	own, err := s.loadSigningPolicy(ctx, w.ID)
+	if err != nil {
+		return SigningPolicy{}, err
+	}
+
+	return s.castSigningPolicy(ctx, w.ID, own)
+}
+
+// Replace the wallet signing policy, nil fields fall back to the default policy.
+// The policy can only tighten the defaults.
+func (s *service) UpdateSigningPolicy(ctx context.Context, uid, walletID, pin string, policy signingpolicy.Policy) (_ SigningPolicy, err error) {
+	a := newAuditRecord(uid, AuditActionUpdatePolicy)
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	if err := policy.Validate(); err != nil {
+		return SigningPolicy{}, fmt.Errorf("%w: %s", ErrInvalidParameter, err)
+	}
+	if err := policy.ValidateOverride(s.defaultPolicy); err != nil {
+		return SigningPolicy{}, fmt.Errorf("%w: %s", ErrInvalidParameter, err)
+	}
+	if err := signingpolicy.Merge(s.defaultPolicy, policy).ValidateEffective(); err != nil {
+		return SigningPolicy{}, fmt.Errorf("%w: %s", ErrInvalidParameter, err)
+	}
+
+	w, err := s.getWallet(ctx, uid, walletID)
+	if err != nil {
+		return SigningPolicy{}, err
+	}
+	a.walletID = w.ID
+
+	if _, err := s.decryptSecret(ctx, &w, pin); err != nil {
+		return SigningPolicy{}, err
+	}
+
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return SigningPolicy{}, fmt.Errorf("failed to encode signing policy: %w", err)
+	}
+	a.details["policy"] = json.RawMessage(data)
+
+	if _, err := s.repo.UpsertSigningPolicy(ctx, wallet_repository.UpsertSigningPolicyParams{
+		WalletID: w.ID,
+		Policy:   data,
+	}); err != nil {
+		return SigningPolicy{}, fmt.Errorf("failed to store signing policy: %w", err)
+	}
+
+	return s.castSigningPolicy(ctx, w.ID, policy)
+}
+
+// enforceSigningPolicy checks the transaction against the effective wallet signing policy
+// and records the wallet outflows for the daily limits.
+// The check and the record run in one transaction holding the wallet row lock,
+// so concurrent requests can't exceed the daily limits together.
+// Outflows are recorded before the transaction is signed, so a failed signing or sending
+// can only make the daily limits stricter, never bypass them.
+func (s *service) enforceSigningPolicy(ctx context.Context, audit *auditRecord, signer, base64Tx string) error {
+	tx, err := s.decodeTransaction(ctx, base64Tx)
+	if err != nil {
+		return err
+	}
+
+	// outflows are stored as BIGINT, larger amounts would wrap and bypass the limits
+	outflows, _ := signingpolicy.Outflows(tx, signer)
+	for mint, amount := range outflows {
+		if amount > math.MaxInt64 {
+			return fmt.Errorf("%w: transaction sends %d base units of %s, more than supported", ErrInvalidTransaction, amount, mint)
+		}
+	}
+
+	own, err := s.loadSigningPolicy(ctx, audit.walletID)
+	if err != nil {
+		return err
+	}
+	policy := signingpolicy.Merge(s.defaultPolicy, own)
+
+	return s.inTx(ctx, func(repo walletRepository, _ *sql.Tx) error {
+		if _, err := repo.GetWalletForUpdate(ctx, audit.walletID); err != nil {
+			return fmt.Errorf("failed to lock wallet: %w", err)
+		}
+
+		spent, err := spentSince(ctx, repo, audit.walletID, time.Now().UTC().Add(-outflowWindow))
+		if err != nil {
+			return err
+		}
+
+		if err := signingpolicy.Check(policy, tx, signer, spent); err != nil {
+			var v *signingpolicy.Violation
+			if errors.As(err, &v) {
+				audit.details["policy_rule"] = v.Rule
+			}
+			return err
+		}
+
+		for mint, amount := range outflows {
+			if amount == 0 {
+				continue
+			}
+			if err := repo.CreateWalletOutflow(ctx, wallet_repository.CreateWalletOutflowParams{
+				WalletID: uuid.NullUUID{UUID: audit.walletID, Valid: true},
+				Mint:     mint,
+				Amount:   int64(amount),
+			}); err != nil {
+				return fmt.Errorf("failed to record wallet outflow: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// loadSigningPolicy returns the policy stored for the wallet, empty policy if there is none
+func (s *service) loadSigningPolicy(ctx context.Context, walletID uuid.UUID) (signingpolicy.Policy, error) {
+	var result signingpolicy.Policy
+
+	p, err := s.repo.GetSigningPolicy(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return result, nil
+		}
+		return result, fmt.Errorf("failed to get signing policy: %w", err)
+	}
+
+	if err := json.Unmarshal(p.Policy, &result); err != nil {
+		return result, fmt.Errorf("failed to decode signing policy: %w", err)
+	}
+
+	return result, nil
+}
+
+// spentSince returns the amounts sent from the wallet since the given time, by mint
+func spentSince(ctx context.Context, repo walletRepository, walletID uuid.UUID, since time.Time) (map[string]uint64, error) {
+	rows, err := repo.GetWalletOutflowsSince(ctx, wallet_repository.GetWalletOutflowsSinceParams{
+		WalletID:  uuid.NullUUID{UUID: walletID, Valid: true},
+		CreatedAt: since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet outflows: %w", err)
+	}
+
+	result := make(map[string]uint64, len(rows))
+	for _, r := range rows {
+		result[r.Mint] = uint64(r.Total)
+	}
+
+	return result, nil
+}
+
+// cast the wallet policy to the public signing policy representation
+func (s *service) castSigningPolicy(ctx context.Context, walletID uuid.UUID, own signingpolicy.Policy) (SigningPolicy, error) {
+	spent, err := spentSince(ctx, s.repo, walletID, time.Now().UTC().Add(-outflowWindow))
+	if err != nil {
+		return SigningPolicy{}, err
+	}
+
+	return SigningPolicy{
+		WalletID:   walletID.String(),
+		Policy:     own,
+		Effective:  signingpolicy.Merge(s.defaultPolicy, own),
+		SpentToday: spent,
+	}, nil
+}
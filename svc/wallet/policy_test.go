@@ -0,0 +1,190 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/internal/signingpolicy"
+	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+	"github.com/dmitrymomot/solana-wallets/internal/utils"
+	wallet_repository "github.com/dmitrymomot/solana-wallets/svc/wallet/repository"
+	"github.com/google/uuid"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/system"
+	"github.com/portto/solana-go-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// fakePolicyRepository keeps the wallet, its signing policy and outflows in memory,
+	// the other repository methods are not implemented
+	fakePolicyRepository struct {
+		walletRepository
+		wallet   wallet_repository.Wallet
+		policy   *signingpolicy.Policy
+		outflows []wallet_repository.CreateWalletOutflowParams
+		locked   int
+	}
+
+	// fakeSecrets returns the secret as is, any pin is valid
+	fakeSecrets struct {
+		solanaWallet
+		secret string
+	}
+
+	// fakeSigner records the signed transactions
+	fakeSigner struct {
+		solanaClient
+		signed []string
+	}
+)
+
+func (r *fakePolicyRepository) GetWallet(ctx context.Context, arg wallet_repository.GetWalletParams) (wallet_repository.Wallet, error) {
+	if arg.ID != r.wallet.ID || arg.UserID != r.wallet.UserID {
+		return wallet_repository.Wallet{}, sql.ErrNoRows
+	}
+	return r.wallet, nil
+}
+
+func (r *fakePolicyRepository) GetWalletForUpdate(ctx context.Context, id uuid.UUID) (wallet_repository.Wallet, error) {
+	r.locked++
+	return r.wallet, nil
+}
+
+func (r *fakePolicyRepository) GetSigningPolicy(ctx context.Context, walletID uuid.UUID) (wallet_repository.WalletSigningPolicy, error) {
+	if r.policy == nil {
+		return wallet_repository.WalletSigningPolicy{}, sql.ErrNoRows
+	}
+	data, err := json.Marshal(r.policy)
+	return wallet_repository.WalletSigningPolicy{WalletID: walletID, Policy: data}, err
+}
+
+func (r *fakePolicyRepository) GetWalletOutflowsSince(ctx context.Context, arg wallet_repository.GetWalletOutflowsSinceParams) ([]wallet_repository.GetWalletOutflowsSinceRow, error) {
+	totals := make(map[string]int64)
+	for _, o := range r.outflows {
+		totals[o.Mint] += o.Amount
+	}
+	rows := make([]wallet_repository.GetWalletOutflowsSinceRow, 0, len(totals))
+	for mint, total := range totals {
+		rows = append(rows, wallet_repository.GetWalletOutflowsSinceRow{Mint: mint, Total: total})
+	}
+	return rows, nil
+}
+
+func (r *fakePolicyRepository) CreateWalletOutflow(ctx context.Context, arg wallet_repository.CreateWalletOutflowParams) error {
+	r.outflows = append(r.outflows, arg)
+	return nil
+}
+
+func (r *fakePolicyRepository) CreateAuditLogEntry(ctx context.Context, arg wallet_repository.CreateAuditLogEntryParams) error {
+	return nil
+}
+
+func (w *fakeSecrets) DecryptMnemonic(ctx context.Context, encrypted, pin string, ad solanawallet.AssociatedData) (string, error) {
+	return w.secret, nil
+}
+
+func (w *fakeSecrets) NeedsReencryption(encrypted string) bool {
+	return false
+}
+
+func (c *fakeSigner) SignTransaction(ctx context.Context, wallet types.Account, txSource string) (string, error) {
+	c.signed = append(c.signed, txSource)
+	return txSource, nil
+}
+
+func TestSignTransactionPolicy(t *testing.T) {
+	acc := types.NewAccount()
+	secret, err := solanawallet.Secret{PrivateKey: utils.BytesToBase58(acc.PrivateKey)}.Encode()
+	require.NoError(t, err)
+
+	transfer := func(t *testing.T, lamports uint64) string {
+		tx, err := types.NewTransaction(types.NewTransactionParam{
+			Message: types.NewMessage(types.NewMessageParam{
+				FeePayer:        acc.PublicKey,
+				RecentBlockhash: "9rAtxuhtKn8qagc3UtZFyhLrw5zgh6etCLnm3zGTSuC8",
+				Instructions: []types.Instruction{
+					system.Transfer(system.TransferParam{
+						From:   acc.PublicKey,
+						To:     types.NewAccount().PublicKey,
+						Amount: lamports,
+					}),
+				},
+			}),
+			Signers: []types.Account{acc},
+		})
+		require.NoError(t, err)
+
+		txb, err := tx.Serialize()
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(txb)
+	}
+
+	newService := func(policy *signingpolicy.Policy) (*service, *fakePolicyRepository, *fakeSigner) {
+		repo := &fakePolicyRepository{
+			wallet: wallet_repository.Wallet{
+				ID:        uuid.New(),
+				UserID:    "user",
+				PublicKey: acc.PublicKey.ToBase58(),
+			},
+			policy: policy,
+		}
+		signer := &fakeSigner{}
+		dailyLimit := uint64(1_000)
+		s := NewService(repo, &fakeSecrets{secret: secret}, signer, nil, WithDefaultSigningPolicy(signingpolicy.Policy{
+			AllowedPrograms: []string{common.SystemProgramID.ToBase58()},
+			DailySOLLimit:   &dailyLimit,
+		})).(*service)
+		return s, repo, signer
+	}
+
+	t.Run("outflow is recorded when the check passes", func(t *testing.T) {
+		s, repo, signer := newService(nil)
+
+		_, err := s.SignTransaction(context.Background(), "user", repo.wallet.ID.String(), 0, "pin", transfer(t, 600))
+		require.NoError(t, err)
+		require.Len(t, signer.signed, 1)
+		require.Equal(t, 2, repo.locked) // once to check the pin, once to check the outflows
+		require.Equal(t, []wallet_repository.CreateWalletOutflowParams{{
+			WalletID: uuid.NullUUID{UUID: repo.wallet.ID, Valid: true},
+			Mint:     signingpolicy.NativeMint,
+			Amount:   600,
+		}}, repo.outflows)
+	})
+
+	t.Run("daily limit violation blocks signing", func(t *testing.T) {
+		s, repo, signer := newService(nil)
+
+		_, err := s.SignTransaction(context.Background(), "user", repo.wallet.ID.String(), 0, "pin", transfer(t, 600))
+		require.NoError(t, err)
+
+		_, err = s.SignTransaction(context.Background(), "user", repo.wallet.ID.String(), 0, "pin", transfer(t, 600))
+		require.ErrorIs(t, err, signingpolicy.ErrViolation)
+		require.Len(t, signer.signed, 1)
+		require.Len(t, repo.outflows, 1)
+	})
+
+	t.Run("wallet policy violation blocks signing", func(t *testing.T) {
+		maxPerTx := uint64(100)
+		s, repo, signer := newService(&signingpolicy.Policy{MaxSOLPerTx: &maxPerTx})
+
+		_, err := s.SignTransaction(context.Background(), "user", repo.wallet.ID.String(), 0, "pin", transfer(t, 101))
+		require.ErrorIs(t, err, signingpolicy.ErrViolation)
+		require.Equal(t, signingpolicy.RuleMaxSOLPerTx, err.(*signingpolicy.Violation).Rule)
+		require.Empty(t, signer.signed)
+		require.Empty(t, repo.outflows)
+	})
+
+	t.Run("amount above the max outflow is rejected", func(t *testing.T) {
+		s, repo, signer := newService(nil)
+
+		_, err := s.SignTransaction(context.Background(), "user", repo.wallet.ID.String(), 0, "pin", transfer(t, math.MaxInt64+1))
+		require.ErrorIs(t, err, ErrInvalidTransaction)
+		require.Empty(t, signer.signed)
+		require.Empty(t, repo.outflows)
+	})
+}
@@ -24,34 +24,206 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.closeWalletNonceAccountStmt, err = db.PrepareContext(ctx, closeWalletNonceAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query CloseWalletNonceAccount: %w", err)
+	}
+	if q.countWalletsByUserIDStmt, err = db.PrepareContext(ctx, countWalletsByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query CountWalletsByUserID: %w", err)
+	}
+	if q.countWalletsForKeyRotationStmt, err = db.PrepareContext(ctx, countWalletsForKeyRotation); err != nil {
+		return nil, fmt.Errorf("error preparing query CountWalletsForKeyRotation: %w", err)
+	}
+	if q.createAuditLogEntryStmt, err = db.PrepareContext(ctx, createAuditLogEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAuditLogEntry: %w", err)
+	}
 	if q.createWalletStmt, err = db.PrepareContext(ctx, createWallet); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateWallet: %w", err)
 	}
-	if q.deleteWalletStmt, err = db.PrepareContext(ctx, deleteWallet); err != nil {
-		return nil, fmt.Errorf("error preparing query DeleteWallet: %w", err)
+	if q.createWalletAccountStmt, err = db.PrepareContext(ctx, createWalletAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWalletAccount: %w", err)
+	}
+	if q.createWalletNonceAccountStmt, err = db.PrepareContext(ctx, createWalletNonceAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWalletNonceAccount: %w", err)
+	}
+	if q.createWalletOutflowStmt, err = db.PrepareContext(ctx, createWalletOutflow); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWalletOutflow: %w", err)
+	}
+	if q.createWalletTransactionStmt, err = db.PrepareContext(ctx, createWalletTransaction); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWalletTransaction: %w", err)
+	}
+	if q.getAuditLogStmt, err = db.PrepareContext(ctx, getAuditLog); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAuditLog: %w", err)
+	}
+	if q.getDefaultWalletStmt, err = db.PrepareContext(ctx, getDefaultWallet); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDefaultWallet: %w", err)
+	}
+	if q.getDeletedWalletStmt, err = db.PrepareContext(ctx, getDeletedWallet); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDeletedWallet: %w", err)
+	}
+	if q.getDeletedWalletsByUserIDStmt, err = db.PrepareContext(ctx, getDeletedWalletsByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDeletedWalletsByUserID: %w", err)
+	}
+	if q.getLastWalletAccountIndexStmt, err = db.PrepareContext(ctx, getLastWalletAccountIndex); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLastWalletAccountIndex: %w", err)
+	}
+	if q.getSigningPolicyStmt, err = db.PrepareContext(ctx, getSigningPolicy); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSigningPolicy: %w", err)
+	}
+	if q.getUnsettledWalletTransactionsStmt, err = db.PrepareContext(ctx, getUnsettledWalletTransactions); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUnsettledWalletTransactions: %w", err)
 	}
 	if q.getWalletStmt, err = db.PrepareContext(ctx, getWallet); err != nil {
 		return nil, fmt.Errorf("error preparing query GetWallet: %w", err)
 	}
+	if q.getWalletAccountStmt, err = db.PrepareContext(ctx, getWalletAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWalletAccount: %w", err)
+	}
+	if q.getWalletAccountsStmt, err = db.PrepareContext(ctx, getWalletAccounts); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWalletAccounts: %w", err)
+	}
 	if q.getWalletByPublicKeyStmt, err = db.PrepareContext(ctx, getWalletByPublicKey); err != nil {
 		return nil, fmt.Errorf("error preparing query GetWalletByPublicKey: %w", err)
 	}
+	if q.getWalletForUpdateStmt, err = db.PrepareContext(ctx, getWalletForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWalletForUpdate: %w", err)
+	}
+	if q.getWalletNonceAccountStmt, err = db.PrepareContext(ctx, getWalletNonceAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWalletNonceAccount: %w", err)
+	}
+	if q.getWalletNonceAccountsStmt, err = db.PrepareContext(ctx, getWalletNonceAccounts); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWalletNonceAccounts: %w", err)
+	}
+	if q.getWalletOutflowsSinceStmt, err = db.PrepareContext(ctx, getWalletOutflowsSince); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWalletOutflowsSince: %w", err)
+	}
+	if q.getWalletTransactionStmt, err = db.PrepareContext(ctx, getWalletTransaction); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWalletTransaction: %w", err)
+	}
+	if q.getWalletTransactionsStmt, err = db.PrepareContext(ctx, getWalletTransactions); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWalletTransactions: %w", err)
+	}
+	if q.getWalletsByUserIDStmt, err = db.PrepareContext(ctx, getWalletsByUserID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWalletsByUserID: %w", err)
+	}
+	if q.getWalletsForKeyRotationStmt, err = db.PrepareContext(ctx, getWalletsForKeyRotation); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWalletsForKeyRotation: %w", err)
+	}
+	if q.incrementFailedPINAttemptsStmt, err = db.PrepareContext(ctx, incrementFailedPINAttempts); err != nil {
+		return nil, fmt.Errorf("error preparing query IncrementFailedPINAttempts: %w", err)
+	}
+	if q.lockWalletStmt, err = db.PrepareContext(ctx, lockWallet); err != nil {
+		return nil, fmt.Errorf("error preparing query LockWallet: %w", err)
+	}
+	if q.purgeDeletedWalletsStmt, err = db.PrepareContext(ctx, purgeDeletedWallets); err != nil {
+		return nil, fmt.Errorf("error preparing query PurgeDeletedWallets: %w", err)
+	}
+	if q.resetFailedPINAttemptsStmt, err = db.PrepareContext(ctx, resetFailedPINAttempts); err != nil {
+		return nil, fmt.Errorf("error preparing query ResetFailedPINAttempts: %w", err)
+	}
+	if q.restoreWalletStmt, err = db.PrepareContext(ctx, restoreWallet); err != nil {
+		return nil, fmt.Errorf("error preparing query RestoreWallet: %w", err)
+	}
+	if q.rotateWalletMnemonicStmt, err = db.PrepareContext(ctx, rotateWalletMnemonic); err != nil {
+		return nil, fmt.Errorf("error preparing query RotateWalletMnemonic: %w", err)
+	}
+	if q.setDefaultWalletStmt, err = db.PrepareContext(ctx, setDefaultWallet); err != nil {
+		return nil, fmt.Errorf("error preparing query SetDefaultWallet: %w", err)
+	}
+	if q.softDeleteWalletStmt, err = db.PrepareContext(ctx, softDeleteWallet); err != nil {
+		return nil, fmt.Errorf("error preparing query SoftDeleteWallet: %w", err)
+	}
 	if q.updateWalletStmt, err = db.PrepareContext(ctx, updateWallet); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateWallet: %w", err)
 	}
+	if q.updateWalletTransactionStatusStmt, err = db.PrepareContext(ctx, updateWalletTransactionStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateWalletTransactionStatus: %w", err)
+	}
+	if q.upsertSigningPolicyStmt, err = db.PrepareContext(ctx, upsertSigningPolicy); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertSigningPolicy: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.closeWalletNonceAccountStmt != nil {
+		if cerr := q.closeWalletNonceAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing closeWalletNonceAccountStmt: %w", cerr)
+		}
+	}
+	if q.countWalletsByUserIDStmt != nil {
+		if cerr := q.countWalletsByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countWalletsByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.countWalletsForKeyRotationStmt != nil {
+		if cerr := q.countWalletsForKeyRotationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countWalletsForKeyRotationStmt: %w", cerr)
+		}
+	}
+	if q.createAuditLogEntryStmt != nil {
+		if cerr := q.createAuditLogEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAuditLogEntryStmt: %w", cerr)
+		}
+	}
 	if q.createWalletStmt != nil {
 		if cerr := q.createWalletStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createWalletStmt: %w", cerr)
 		}
 	}
-	if q.deleteWalletStmt != nil {
-		if cerr := q.deleteWalletStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteWalletStmt: %w", cerr)
+	if q.createWalletAccountStmt != nil {
+		if cerr := q.createWalletAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWalletAccountStmt: %w", cerr)
+		}
+	}
+	if q.createWalletNonceAccountStmt != nil {
+		if cerr := q.createWalletNonceAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWalletNonceAccountStmt: %w", cerr)
+		}
+	}
+	if q.createWalletOutflowStmt != nil {
+		if cerr := q.createWalletOutflowStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWalletOutflowStmt: %w", cerr)
+		}
+	}
+	if q.createWalletTransactionStmt != nil {
+		if cerr := q.createWalletTransactionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWalletTransactionStmt: %w", cerr)
+		}
+	}
+	if q.getAuditLogStmt != nil {
+		if cerr := q.getAuditLogStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAuditLogStmt: %w", cerr)
+		}
+	}
+	if q.getDefaultWalletStmt != nil {
+		if cerr := q.getDefaultWalletStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDefaultWalletStmt: %w", cerr)
+		}
+	}
+	if q.getDeletedWalletStmt != nil {
+		if cerr := q.getDeletedWalletStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDeletedWalletStmt: %w", cerr)
+		}
+	}
+	if q.getDeletedWalletsByUserIDStmt != nil {
+		if cerr := q.getDeletedWalletsByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDeletedWalletsByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.getLastWalletAccountIndexStmt != nil {
+		if cerr := q.getLastWalletAccountIndexStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLastWalletAccountIndexStmt: %w", cerr)
+		}
+	}
+	if q.getSigningPolicyStmt != nil {
+		if cerr := q.getSigningPolicyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSigningPolicyStmt: %w", cerr)
+		}
+	}
+	if q.getUnsettledWalletTransactionsStmt != nil {
+		if cerr := q.getUnsettledWalletTransactionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUnsettledWalletTransactionsStmt: %w", cerr)
 		}
 	}
 	if q.getWalletStmt != nil {
@@ -59,16 +231,116 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getWalletStmt: %w", cerr)
 		}
 	}
+	if q.getWalletAccountStmt != nil {
+		if cerr := q.getWalletAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWalletAccountStmt: %w", cerr)
+		}
+	}
+	if q.getWalletAccountsStmt != nil {
+		if cerr := q.getWalletAccountsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWalletAccountsStmt: %w", cerr)
+		}
+	}
 	if q.getWalletByPublicKeyStmt != nil {
 		if cerr := q.getWalletByPublicKeyStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getWalletByPublicKeyStmt: %w", cerr)
 		}
 	}
+	if q.getWalletForUpdateStmt != nil {
+		if cerr := q.getWalletForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWalletForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getWalletNonceAccountStmt != nil {
+		if cerr := q.getWalletNonceAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWalletNonceAccountStmt: %w", cerr)
+		}
+	}
+	if q.getWalletNonceAccountsStmt != nil {
+		if cerr := q.getWalletNonceAccountsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWalletNonceAccountsStmt: %w", cerr)
+		}
+	}
+	if q.getWalletOutflowsSinceStmt != nil {
+		if cerr := q.getWalletOutflowsSinceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWalletOutflowsSinceStmt: %w", cerr)
+		}
+	}
+	if q.getWalletTransactionStmt != nil {
+		if cerr := q.getWalletTransactionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWalletTransactionStmt: %w", cerr)
+		}
+	}
+	if q.getWalletTransactionsStmt != nil {
+		if cerr := q.getWalletTransactionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWalletTransactionsStmt: %w", cerr)
+		}
+	}
+	if q.getWalletsByUserIDStmt != nil {
+		if cerr := q.getWalletsByUserIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWalletsByUserIDStmt: %w", cerr)
+		}
+	}
+	if q.getWalletsForKeyRotationStmt != nil {
+		if cerr := q.getWalletsForKeyRotationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWalletsForKeyRotationStmt: %w", cerr)
+		}
+	}
+	if q.incrementFailedPINAttemptsStmt != nil {
+		if cerr := q.incrementFailedPINAttemptsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing incrementFailedPINAttemptsStmt: %w", cerr)
+		}
+	}
+	if q.lockWalletStmt != nil {
+		if cerr := q.lockWalletStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing lockWalletStmt: %w", cerr)
+		}
+	}
+	if q.purgeDeletedWalletsStmt != nil {
+		if cerr := q.purgeDeletedWalletsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing purgeDeletedWalletsStmt: %w", cerr)
+		}
+	}
+	if q.resetFailedPINAttemptsStmt != nil {
+		if cerr := q.resetFailedPINAttemptsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing resetFailedPINAttemptsStmt: %w", cerr)
+		}
+	}
+	if q.restoreWalletStmt != nil {
+		if cerr := q.restoreWalletStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing restoreWalletStmt: %w", cerr)
+		}
+	}
+	if q.rotateWalletMnemonicStmt != nil {
+		if cerr := q.rotateWalletMnemonicStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing rotateWalletMnemonicStmt: %w", cerr)
+		}
+	}
+	if q.setDefaultWalletStmt != nil {
+		if cerr := q.setDefaultWalletStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setDefaultWalletStmt: %w", cerr)
+		}
+	}
+	if q.softDeleteWalletStmt != nil {
+		if cerr := q.softDeleteWalletStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing softDeleteWalletStmt: %w", cerr)
+		}
+	}
 	if q.updateWalletStmt != nil {
 		if cerr := q.updateWalletStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateWalletStmt: %w", cerr)
 		}
 	}
+	if q.updateWalletTransactionStatusStmt != nil {
+		if cerr := q.updateWalletTransactionStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateWalletTransactionStatusStmt: %w", cerr)
+		}
+	}
+	if q.upsertSigningPolicyStmt != nil {
+		if cerr := q.upsertSigningPolicyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertSigningPolicyStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -106,23 +378,91 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                       DBTX
-	tx                       *sql.Tx
-	createWalletStmt         *sql.Stmt
-	deleteWalletStmt         *sql.Stmt
-	getWalletStmt            *sql.Stmt
-	getWalletByPublicKeyStmt *sql.Stmt
-	updateWalletStmt         *sql.Stmt
+	db                                 DBTX
+	tx                                 *sql.Tx
+	closeWalletNonceAccountStmt        *sql.Stmt
+	countWalletsByUserIDStmt           *sql.Stmt
+	countWalletsForKeyRotationStmt     *sql.Stmt
+	createAuditLogEntryStmt            *sql.Stmt
+	createWalletStmt                   *sql.Stmt
+	createWalletAccountStmt            *sql.Stmt
+	createWalletNonceAccountStmt       *sql.Stmt
+	createWalletOutflowStmt            *sql.Stmt
+	createWalletTransactionStmt        *sql.Stmt
+	getAuditLogStmt                    *sql.Stmt
+	getDefaultWalletStmt               *sql.Stmt
+	getDeletedWalletStmt               *sql.Stmt
+	getDeletedWalletsByUserIDStmt      *sql.Stmt
+	getLastWalletAccountIndexStmt      *sql.Stmt
+	getSigningPolicyStmt               *sql.Stmt
+	getUnsettledWalletTransactionsStmt *sql.Stmt
+	getWalletStmt                      *sql.Stmt
+	getWalletAccountStmt               *sql.Stmt
+	getWalletAccountsStmt              *sql.Stmt
+	getWalletByPublicKeyStmt           *sql.Stmt
+	getWalletForUpdateStmt             *sql.Stmt
+	getWalletNonceAccountStmt          *sql.Stmt
+	getWalletNonceAccountsStmt         *sql.Stmt
+	getWalletOutflowsSinceStmt         *sql.Stmt
+	getWalletTransactionStmt           *sql.Stmt
+	getWalletTransactionsStmt          *sql.Stmt
+	getWalletsByUserIDStmt             *sql.Stmt
+	getWalletsForKeyRotationStmt       *sql.Stmt
+	incrementFailedPINAttemptsStmt     *sql.Stmt
+	lockWalletStmt                     *sql.Stmt
+	purgeDeletedWalletsStmt            *sql.Stmt
+	resetFailedPINAttemptsStmt         *sql.Stmt
+	restoreWalletStmt                  *sql.Stmt
+	rotateWalletMnemonicStmt           *sql.Stmt
+	setDefaultWalletStmt               *sql.Stmt
+	softDeleteWalletStmt               *sql.Stmt
+	updateWalletStmt                   *sql.Stmt
+	updateWalletTransactionStatusStmt  *sql.Stmt
+	upsertSigningPolicyStmt            *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                       tx,
-		tx:                       tx,
-		createWalletStmt:         q.createWalletStmt,
-		deleteWalletStmt:         q.deleteWalletStmt,
-		getWalletStmt:            q.getWalletStmt,
-		getWalletByPublicKeyStmt: q.getWalletByPublicKeyStmt,
-		updateWalletStmt:         q.updateWalletStmt,
+		db:                                 tx,
+		tx:                                 tx,
+		closeWalletNonceAccountStmt:        q.closeWalletNonceAccountStmt,
+		countWalletsByUserIDStmt:           q.countWalletsByUserIDStmt,
+		countWalletsForKeyRotationStmt:     q.countWalletsForKeyRotationStmt,
+		createAuditLogEntryStmt:            q.createAuditLogEntryStmt,
+		createWalletStmt:                   q.createWalletStmt,
+		createWalletAccountStmt:            q.createWalletAccountStmt,
+		createWalletNonceAccountStmt:       q.createWalletNonceAccountStmt,
+		createWalletOutflowStmt:            q.createWalletOutflowStmt,
+		createWalletTransactionStmt:        q.createWalletTransactionStmt,
+		getAuditLogStmt:                    q.getAuditLogStmt,
+		getDefaultWalletStmt:               q.getDefaultWalletStmt,
+		getDeletedWalletStmt:               q.getDeletedWalletStmt,
+		getDeletedWalletsByUserIDStmt:      q.getDeletedWalletsByUserIDStmt,
+		getLastWalletAccountIndexStmt:      q.getLastWalletAccountIndexStmt,
+		getSigningPolicyStmt:               q.getSigningPolicyStmt,
+		getUnsettledWalletTransactionsStmt: q.getUnsettledWalletTransactionsStmt,
+		getWalletStmt:                      q.getWalletStmt,
+		getWalletAccountStmt:               q.getWalletAccountStmt,
+		getWalletAccountsStmt:              q.getWalletAccountsStmt,
+		getWalletByPublicKeyStmt:           q.getWalletByPublicKeyStmt,
+		getWalletForUpdateStmt:             q.getWalletForUpdateStmt,
+		getWalletNonceAccountStmt:          q.getWalletNonceAccountStmt,
+		getWalletNonceAccountsStmt:         q.getWalletNonceAccountsStmt,
+		getWalletOutflowsSinceStmt:         q.getWalletOutflowsSinceStmt,
+		getWalletTransactionStmt:           q.getWalletTransactionStmt,
+		getWalletTransactionsStmt:          q.getWalletTransactionsStmt,
+		getWalletsByUserIDStmt:             q.getWalletsByUserIDStmt,
+		getWalletsForKeyRotationStmt:       q.getWalletsForKeyRotationStmt,
+		incrementFailedPINAttemptsStmt:     q.incrementFailedPINAttemptsStmt,
+		lockWalletStmt:                     q.lockWalletStmt,
+		purgeDeletedWalletsStmt:            q.purgeDeletedWalletsStmt,
+		resetFailedPINAttemptsStmt:         q.resetFailedPINAttemptsStmt,
+		restoreWalletStmt:                  q.restoreWalletStmt,
+		rotateWalletMnemonicStmt:           q.rotateWalletMnemonicStmt,
+		setDefaultWalletStmt:               q.setDefaultWalletStmt,
+		softDeleteWalletStmt:               q.softDeleteWalletStmt,
+		updateWalletStmt:                   q.updateWalletStmt,
+		updateWalletTransactionStatusStmt:  q.updateWalletTransactionStatusStmt,
+		upsertSigningPolicyStmt:            q.upsertSigningPolicyStmt,
 	}
 }
@@ -6,14 +6,89 @@ package wallet_repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Wallet struct {
-	UserID    string       `json:"user_id"`
-	Name      string       `json:"name"`
-	PublicKey string       `json:"public_key"`
-	Mnemonic  string       `json:"mnemonic"`
-	CreatedAt time.Time    `json:"created_at"`
-	UpdatedAt sql.NullTime `json:"updated_at"`
+	UserID            string         `json:"user_id"`
+	Name              string         `json:"name"`
+	PublicKey         string         `json:"public_key"`
+	Mnemonic          string         `json:"mnemonic"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         sql.NullTime   `json:"updated_at"`
+	ID                uuid.UUID      `json:"id"`
+	IsDefault         bool           `json:"is_default"`
+	FailedPinAttempts int32          `json:"failed_pin_attempts"`
+	LockedUntil       sql.NullTime   `json:"locked_until"`
+	IsLocked          bool           `json:"is_locked"`
+	KeyVersion        sql.NullString `json:"key_version"`
+	DeletedAt         sql.NullTime   `json:"deleted_at"`
+}
+
+type WalletAccount struct {
+	ID           uuid.UUID    `json:"id"`
+	WalletID     uuid.UUID    `json:"wallet_id"`
+	AccountIndex int32        `json:"account_index"`
+	Name         string       `json:"name"`
+	PublicKey    string       `json:"public_key"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    sql.NullTime `json:"updated_at"`
+}
+
+type WalletAuditLog struct {
+	ID        uuid.UUID       `json:"id"`
+	UserID    string          `json:"user_id"`
+	WalletID  uuid.NullUUID   `json:"wallet_id"`
+	Action    string          `json:"action"`
+	Outcome   string          `json:"outcome"`
+	Error     sql.NullString  `json:"error"`
+	Details   json.RawMessage `json:"details"`
+	RequestID sql.NullString  `json:"request_id"`
+	IP        sql.NullString  `json:"ip"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type WalletNonceAccount struct {
+	ID           uuid.UUID     `json:"id"`
+	UserID       string        `json:"user_id"`
+	WalletID     uuid.NullUUID `json:"wallet_id"`
+	AccountIndex int32         `json:"account_index"`
+	Address      string        `json:"address"`
+	Seed         string        `json:"seed"`
+	ClosedAt     sql.NullTime  `json:"closed_at"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    sql.NullTime  `json:"updated_at"`
+}
+
+type WalletOutflow struct {
+	ID        uuid.UUID     `json:"id"`
+	WalletID  uuid.NullUUID `json:"wallet_id"`
+	Mint      string        `json:"mint"`
+	Amount    int64         `json:"amount"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type WalletSigningPolicy struct {
+	WalletID  uuid.UUID       `json:"wallet_id"`
+	Policy    json.RawMessage `json:"policy"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt sql.NullTime    `json:"updated_at"`
+}
+
+type WalletTransaction struct {
+	ID              uuid.UUID      `json:"id"`
+	UserID          string         `json:"user_id"`
+	WalletID        uuid.NullUUID  `json:"wallet_id"`
+	AccountIndex    int32          `json:"account_index"`
+	Signature       string         `json:"signature"`
+	RecentBlockhash string         `json:"recent_blockhash"`
+	Status          string         `json:"status"`
+	Error           sql.NullString `json:"error"`
+	Slot            sql.NullInt64  `json:"slot"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       sql.NullTime   `json:"updated_at"`
+	NonceAccount    sql.NullString `json:"nonce_account"`
 }
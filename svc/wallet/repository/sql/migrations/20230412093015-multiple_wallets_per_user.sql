@@ -0,0 +1,24 @@
+-- +migrate Up
+-- +migrate StatementBegin
+ALTER TABLE wallets ADD COLUMN id UUID NOT NULL DEFAULT uuid_generate_v4();
+ALTER TABLE wallets ADD COLUMN is_default BOOLEAN NOT NULL DEFAULT false;
+
+-- existing rows become the default wallet of their owner
+UPDATE wallets SET is_default = true;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_pkey;
+ALTER TABLE wallets ADD PRIMARY KEY (id);
+CREATE INDEX wallets_user_id ON wallets (user_id);
+CREATE UNIQUE INDEX wallets_user_id_default ON wallets (user_id) WHERE is_default;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+DELETE FROM wallets WHERE NOT is_default;
+DROP INDEX IF EXISTS wallets_user_id_default;
+DROP INDEX IF EXISTS wallets_user_id;
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_pkey;
+ALTER TABLE wallets ADD PRIMARY KEY (user_id);
+ALTER TABLE wallets DROP COLUMN IF EXISTS is_default;
+ALTER TABLE wallets DROP COLUMN IF EXISTS id;
+-- +migrate StatementEnd
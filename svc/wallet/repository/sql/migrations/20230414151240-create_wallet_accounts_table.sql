@@ -0,0 +1,20 @@
+-- +migrate Up
+-- +migrate StatementBegin
+CREATE TABLE IF NOT EXISTS wallet_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL REFERENCES wallets (id) ON DELETE CASCADE,
+    account_index INTEGER NOT NULL,
+    name VARCHAR NOT NULL,
+    public_key VARCHAR NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NULL
+);
+CREATE UNIQUE INDEX wallet_accounts_wallet_id_account_index ON wallet_accounts (wallet_id, account_index);
+CREATE UNIQUE INDEX wallet_accounts_public_key ON wallet_accounts (public_key);
+CREATE TRIGGER update_wallet_accounts_modtime BEFORE
+UPDATE ON wallet_accounts FOR EACH ROW EXECUTE PROCEDURE wallets_update_updated_at_column();
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS update_wallet_accounts_modtime ON wallet_accounts;
+DROP TABLE IF EXISTS wallet_accounts;
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE wallets ADD COLUMN failed_pin_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE wallets ADD COLUMN locked_until TIMESTAMP DEFAULT NULL;
+ALTER TABLE wallets ADD COLUMN is_locked BOOLEAN NOT NULL DEFAULT false;
+
+-- +migrate Down
+ALTER TABLE wallets DROP COLUMN IF EXISTS is_locked;
+ALTER TABLE wallets DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE wallets DROP COLUMN IF EXISTS failed_pin_attempts;
@@ -0,0 +1,12 @@
+-- +migrate Up
+-- +migrate StatementBegin
+-- id of the key encryption key wrapping the mnemonic data key, NULL for the mnemonics encrypted without it
+ALTER TABLE wallets ADD COLUMN key_version VARCHAR DEFAULT NULL;
+CREATE INDEX wallets_key_version ON wallets (key_version);
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+DROP INDEX IF EXISTS wallets_key_version;
+ALTER TABLE wallets DROP COLUMN IF EXISTS key_version;
+-- +migrate StatementEnd
@@ -0,0 +1,13 @@
+-- +migrate Up
+-- +migrate StatementBegin
+-- wallets pending deletion, purged once the recovery window is over
+ALTER TABLE wallets ADD COLUMN deleted_at TIMESTAMP DEFAULT NULL;
+CREATE INDEX wallets_deleted_at ON wallets (deleted_at) WHERE deleted_at IS NOT NULL;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+DELETE FROM wallets WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS wallets_deleted_at;
+ALTER TABLE wallets DROP COLUMN IF EXISTS deleted_at;
+-- +migrate StatementEnd
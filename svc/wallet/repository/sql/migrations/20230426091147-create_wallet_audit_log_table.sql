@@ -0,0 +1,32 @@
+-- +migrate Up
+-- +migrate StatementBegin
+-- wallet_id has no foreign key, so the audit trail outlives purged wallets
+CREATE TABLE IF NOT EXISTS wallet_audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id VARCHAR NOT NULL,
+    wallet_id UUID DEFAULT NULL,
+    action VARCHAR NOT NULL,
+    outcome VARCHAR NOT NULL,
+    error VARCHAR DEFAULT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    request_id VARCHAR DEFAULT NULL,
+    ip VARCHAR DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX wallet_audit_log_user_id_created_at ON wallet_audit_log (user_id, created_at DESC);
+CREATE INDEX wallet_audit_log_wallet_id_created_at ON wallet_audit_log (wallet_id, created_at DESC);
+
+CREATE
+OR REPLACE FUNCTION wallet_audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN RAISE EXCEPTION 'wallet_audit_log is append-only';
+END;
+$$ LANGUAGE 'plpgsql';
+
+CREATE TRIGGER wallet_audit_log_append_only BEFORE
+UPDATE OR DELETE ON wallet_audit_log FOR EACH ROW EXECUTE PROCEDURE wallet_audit_log_append_only();
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS wallet_audit_log_append_only ON wallet_audit_log;
+DROP TABLE IF EXISTS wallet_audit_log;
+DROP FUNCTION IF EXISTS wallet_audit_log_append_only();
@@ -0,0 +1,27 @@
+-- +migrate Up
+-- +migrate StatementBegin
+CREATE TABLE IF NOT EXISTS wallet_signing_policies (
+    wallet_id UUID PRIMARY KEY REFERENCES wallets (id) ON DELETE CASCADE,
+    policy JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NULL
+);
+CREATE TRIGGER update_wallet_signing_policies_modtime BEFORE
+UPDATE ON wallet_signing_policies FOR EACH ROW EXECUTE PROCEDURE wallets_update_updated_at_column();
+
+-- amounts sent from the wallets, used to check the daily outflow limits;
+-- the ledger outlives purged wallets, wallet_id is cleared then
+CREATE TABLE IF NOT EXISTS wallet_outflows (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID DEFAULT NULL REFERENCES wallets (id) ON DELETE SET NULL,
+    mint VARCHAR NOT NULL,
+    amount BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX wallet_outflows_wallet_id_created_at ON wallet_outflows (wallet_id, created_at);
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TABLE IF EXISTS wallet_outflows;
+DROP TRIGGER IF EXISTS update_wallet_signing_policies_modtime ON wallet_signing_policies;
+DROP TABLE IF EXISTS wallet_signing_policies;
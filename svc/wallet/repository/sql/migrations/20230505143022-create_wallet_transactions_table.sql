@@ -0,0 +1,28 @@
+-- +migrate Up
+-- +migrate StatementBegin
+CREATE TABLE IF NOT EXISTS wallet_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id VARCHAR NOT NULL,
+    -- the history outlives purged wallets, wallet_id is cleared then
+    wallet_id UUID DEFAULT NULL REFERENCES wallets (id) ON DELETE SET NULL,
+    account_index INTEGER NOT NULL DEFAULT 0,
+    signature VARCHAR NOT NULL UNIQUE,
+    recent_blockhash VARCHAR NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'pending',
+    error VARCHAR DEFAULT NULL,
+    slot BIGINT DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NULL
+);
+CREATE INDEX wallet_transactions_user_id_created_at ON wallet_transactions (user_id, created_at DESC);
+-- the confirmation watcher polls the transactions which are not settled yet
+CREATE INDEX wallet_transactions_unsettled ON wallet_transactions (created_at)
+WHERE status IN ('pending', 'processed', 'confirmed');
+
+CREATE TRIGGER update_wallet_transactions_modtime BEFORE
+UPDATE ON wallet_transactions FOR EACH ROW EXECUTE PROCEDURE wallets_update_updated_at_column();
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS update_wallet_transactions_modtime ON wallet_transactions;
+DROP TABLE IF EXISTS wallet_transactions;
@@ -0,0 +1,30 @@
+-- +migrate Up
+-- +migrate StatementBegin
+CREATE TABLE IF NOT EXISTS wallet_nonce_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id VARCHAR NOT NULL,
+    -- the record outlives purged wallets, wallet_id is cleared then
+    wallet_id UUID DEFAULT NULL REFERENCES wallets (id) ON DELETE SET NULL,
+    account_index INTEGER NOT NULL DEFAULT 0,
+    address VARCHAR NOT NULL UNIQUE,
+    seed VARCHAR NOT NULL, -- the address is derived from the wallet account with this seed
+    closed_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NULL
+);
+CREATE INDEX wallet_nonce_accounts_user_id ON wallet_nonce_accounts (user_id, created_at DESC)
+WHERE closed_at IS NULL;
+
+CREATE TRIGGER update_wallet_nonce_accounts_modtime BEFORE
+UPDATE ON wallet_nonce_accounts FOR EACH ROW EXECUTE PROCEDURE wallets_update_updated_at_column();
+
+-- durable transactions expire when the nonce is advanced, not when the blockhash gets too old
+ALTER TABLE wallet_transactions ADD COLUMN nonce_account VARCHAR DEFAULT NULL;
+-- +migrate StatementEnd
+
+-- +migrate Down
+-- +migrate StatementBegin
+ALTER TABLE wallet_transactions DROP COLUMN IF EXISTS nonce_account;
+DROP TRIGGER IF EXISTS update_wallet_nonce_accounts_modtime ON wallet_nonce_accounts;
+DROP TABLE IF EXISTS wallet_nonce_accounts;
+-- +migrate StatementEnd
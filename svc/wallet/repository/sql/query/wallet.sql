@@ -1,14 +1,59 @@
 -- name: CreateWallet :one
-INSERT INTO wallets (user_id, name, public_key, mnemonic) VALUES ($1, $2, $3, $4) RETURNING *;
+INSERT INTO wallets (id, user_id, name, public_key, mnemonic, is_default, key_version) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING *;
 
 -- name: GetWallet :one
-SELECT * FROM wallets WHERE user_id = $1;
+SELECT * FROM wallets WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL;
+
+-- name: GetWalletForUpdate :one
+SELECT * FROM wallets WHERE id = $1 FOR UPDATE;
+
+-- name: GetDefaultWallet :one
+SELECT * FROM wallets WHERE user_id = $1 AND deleted_at IS NULL ORDER BY is_default DESC, created_at ASC LIMIT 1;
+
+-- name: GetWalletsByUserID :many
+SELECT * FROM wallets WHERE user_id = $1 AND deleted_at IS NULL ORDER BY is_default DESC, created_at ASC;
+
+-- name: CountWalletsByUserID :one
+SELECT COUNT(*) FROM wallets WHERE user_id = $1 AND deleted_at IS NULL;
 
 -- name: GetWalletByPublicKey :one
 SELECT * FROM wallets WHERE public_key = $1;
 
 -- name: UpdateWallet :one
-UPDATE wallets SET name = $2, mnemonic = $3 WHERE user_id = $1 RETURNING *;
+UPDATE wallets SET name = $2, mnemonic = $3, key_version = $4 WHERE id = $1 RETURNING *;
+
+-- name: SoftDeleteWallet :exec
+UPDATE wallets SET deleted_at = $2, is_default = false WHERE id = $1;
+
+-- name: GetDeletedWallet :one
+SELECT * FROM wallets WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL;
+
+-- name: GetDeletedWalletsByUserID :many
+SELECT * FROM wallets WHERE user_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC;
+
+-- name: RestoreWallet :one
+UPDATE wallets SET deleted_at = NULL, is_default = $2 WHERE id = $1 RETURNING *;
+
+-- name: SetDefaultWallet :exec
+UPDATE wallets SET is_default = true WHERE id = $1;
+
+-- name: PurgeDeletedWallets :execrows
+DELETE FROM wallets WHERE deleted_at IS NOT NULL AND deleted_at < $1;
+
+-- name: IncrementFailedPINAttempts :one
+UPDATE wallets SET failed_pin_attempts = failed_pin_attempts + 1 WHERE id = $1 RETURNING failed_pin_attempts;
+
+-- name: LockWallet :exec
+UPDATE wallets SET locked_until = GREATEST(locked_until, $2), is_locked = is_locked OR $3 WHERE id = $1;
+
+-- name: ResetFailedPINAttempts :exec
+UPDATE wallets SET failed_pin_attempts = 0, locked_until = NULL WHERE id = $1 AND NOT is_locked;
+
+-- name: CountWalletsForKeyRotation :one
+SELECT COUNT(*) FROM wallets WHERE key_version IS NULL OR key_version <> $1;
+
+-- name: GetWalletsForKeyRotation :many
+SELECT * FROM wallets WHERE id > $1 AND (key_version IS NULL OR key_version <> $2) ORDER BY id LIMIT $3;
 
--- name: DeleteWallet :exec
-DELETE FROM wallets WHERE user_id = $1;
\ No newline at end of file
+-- name: RotateWalletMnemonic :execrows
+UPDATE wallets SET mnemonic = @new_mnemonic, key_version = @key_version WHERE id = @id AND mnemonic = @old_mnemonic;
@@ -0,0 +1,11 @@
+-- name: CreateWalletAccount :one
+INSERT INTO wallet_accounts (wallet_id, account_index, name, public_key) VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: GetWalletAccount :one
+SELECT * FROM wallet_accounts WHERE wallet_id = $1 AND account_index = $2;
+
+-- name: GetWalletAccounts :many
+SELECT * FROM wallet_accounts WHERE wallet_id = $1 ORDER BY account_index ASC;
+
+-- name: GetLastWalletAccountIndex :one
+SELECT COALESCE(MAX(account_index), 0)::INTEGER FROM wallet_accounts WHERE wallet_id = $1;
@@ -0,0 +1,9 @@
+-- name: CreateAuditLogEntry :exec
+INSERT INTO wallet_audit_log (user_id, wallet_id, action, outcome, error, details, request_id, ip) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: GetAuditLog :many
+SELECT * FROM wallet_audit_log
+WHERE (@user_id::VARCHAR = '' OR user_id = @user_id)
+AND (sqlc.narg(wallet_id)::UUID IS NULL OR wallet_id = sqlc.narg(wallet_id))
+ORDER BY created_at DESC, id DESC
+LIMIT @limit_val OFFSET @offset_val;
@@ -0,0 +1,15 @@
+-- name: CreateWalletNonceAccount :one
+INSERT INTO wallet_nonce_accounts (user_id, wallet_id, account_index, address, seed) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: GetWalletNonceAccount :one
+SELECT * FROM wallet_nonce_accounts WHERE user_id = $1 AND address = $2 AND closed_at IS NULL;
+
+-- name: GetWalletNonceAccounts :many
+SELECT * FROM wallet_nonce_accounts
+WHERE user_id = @user_id
+AND (sqlc.narg(wallet_id)::UUID IS NULL OR wallet_id = sqlc.narg(wallet_id))
+AND closed_at IS NULL
+ORDER BY created_at DESC, id DESC;
+
+-- name: CloseWalletNonceAccount :exec
+UPDATE wallet_nonce_accounts SET closed_at = NOW() WHERE id = $1;
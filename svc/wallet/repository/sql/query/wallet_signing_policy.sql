@@ -0,0 +1,15 @@
+-- name: GetSigningPolicy :one
+SELECT * FROM wallet_signing_policies WHERE wallet_id = $1;
+
+-- name: UpsertSigningPolicy :one
+INSERT INTO wallet_signing_policies (wallet_id, policy) VALUES ($1, $2)
+ON CONFLICT (wallet_id) DO UPDATE SET policy = EXCLUDED.policy
+RETURNING *;
+
+-- name: CreateWalletOutflow :exec
+INSERT INTO wallet_outflows (wallet_id, mint, amount) VALUES ($1, $2, $3);
+
+-- name: GetWalletOutflowsSince :many
+SELECT mint, SUM(amount)::BIGINT AS total FROM wallet_outflows
+WHERE wallet_id = $1 AND created_at >= $2
+GROUP BY mint;
@@ -0,0 +1,22 @@
+-- name: CreateWalletTransaction :one
+INSERT INTO wallet_transactions (user_id, wallet_id, account_index, signature, recent_blockhash, nonce_account) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: GetWalletTransaction :one
+SELECT * FROM wallet_transactions WHERE user_id = $1 AND signature = $2;
+
+-- name: GetWalletTransactions :many
+SELECT * FROM wallet_transactions
+WHERE user_id = @user_id
+AND (sqlc.narg(wallet_id)::UUID IS NULL OR wallet_id = sqlc.narg(wallet_id))
+AND (@status::VARCHAR = '' OR status = @status)
+ORDER BY created_at DESC, id DESC
+LIMIT @limit_val OFFSET @offset_val;
+
+-- name: GetUnsettledWalletTransactions :many
+SELECT * FROM wallet_transactions
+WHERE status IN ('pending', 'processed', 'confirmed')
+ORDER BY created_at
+LIMIT $1;
+
+-- name: UpdateWalletTransactionStatus :exec
+UPDATE wallet_transactions SET status = $2, error = $3, slot = $4 WHERE id = $1;
@@ -7,25 +7,56 @@ package wallet_repository
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
 )
 
+const countWalletsByUserID = `-- name: CountWalletsByUserID :one
+SELECT COUNT(*) FROM wallets WHERE user_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) CountWalletsByUserID(ctx context.Context, userID string) (int64, error) {
+	row := q.queryRow(ctx, q.countWalletsByUserIDStmt, countWalletsByUserID, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countWalletsForKeyRotation = `-- name: CountWalletsForKeyRotation :one
+SELECT COUNT(*) FROM wallets WHERE key_version IS NULL OR key_version <> $1
+`
+
+func (q *Queries) CountWalletsForKeyRotation(ctx context.Context, keyVersion sql.NullString) (int64, error) {
+	row := q.queryRow(ctx, q.countWalletsForKeyRotationStmt, countWalletsForKeyRotation, keyVersion)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createWallet = `-- name: CreateWallet :one
-INSERT INTO wallets (user_id, name, public_key, mnemonic) VALUES ($1, $2, $3, $4) RETURNING user_id, name, public_key, mnemonic, created_at, updated_at
+INSERT INTO wallets (id, user_id, name, public_key, mnemonic, is_default, key_version) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING user_id, name, public_key, mnemonic, created_at, updated_at, id, is_default, failed_pin_attempts, locked_until, is_locked, key_version, deleted_at
 `
 
 type CreateWalletParams struct {
-	UserID    string `json:"user_id"`
-	Name      string `json:"name"`
-	PublicKey string `json:"public_key"`
-	Mnemonic  string `json:"mnemonic"`
+	ID         uuid.UUID      `json:"id"`
+	UserID     string         `json:"user_id"`
+	Name       string         `json:"name"`
+	PublicKey  string         `json:"public_key"`
+	Mnemonic   string         `json:"mnemonic"`
+	IsDefault  bool           `json:"is_default"`
+	KeyVersion sql.NullString `json:"key_version"`
 }
 
 func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error) {
 	row := q.queryRow(ctx, q.createWalletStmt, createWallet,
+		arg.ID,
 		arg.UserID,
 		arg.Name,
 		arg.PublicKey,
 		arg.Mnemonic,
+		arg.IsDefault,
+		arg.KeyVersion,
 	)
 	var i Wallet
 	err := row.Scan(
@@ -35,25 +66,124 @@ func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wal
 		&i.Mnemonic,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ID,
+		&i.IsDefault,
+		&i.FailedPinAttempts,
+		&i.LockedUntil,
+		&i.IsLocked,
+		&i.KeyVersion,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const deleteWallet = `-- name: DeleteWallet :exec
-DELETE FROM wallets WHERE user_id = $1
+const getDefaultWallet = `-- name: GetDefaultWallet :one
+SELECT user_id, name, public_key, mnemonic, created_at, updated_at, id, is_default, failed_pin_attempts, locked_until, is_locked, key_version, deleted_at FROM wallets WHERE user_id = $1 AND deleted_at IS NULL ORDER BY is_default DESC, created_at ASC LIMIT 1
 `
 
-func (q *Queries) DeleteWallet(ctx context.Context, userID string) error {
-	_, err := q.exec(ctx, q.deleteWalletStmt, deleteWallet, userID)
-	return err
+func (q *Queries) GetDefaultWallet(ctx context.Context, userID string) (Wallet, error) {
+	row := q.queryRow(ctx, q.getDefaultWalletStmt, getDefaultWallet, userID)
+	var i Wallet
+	err := row.Scan(
+		&i.UserID,
+		&i.Name,
+		&i.PublicKey,
+		&i.Mnemonic,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ID,
+		&i.IsDefault,
+		&i.FailedPinAttempts,
+		&i.LockedUntil,
+		&i.IsLocked,
+		&i.KeyVersion,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getDeletedWallet = `-- name: GetDeletedWallet :one
+SELECT user_id, name, public_key, mnemonic, created_at, updated_at, id, is_default, failed_pin_attempts, locked_until, is_locked, key_version, deleted_at FROM wallets WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+`
+
+type GetDeletedWalletParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID string    `json:"user_id"`
+}
+
+func (q *Queries) GetDeletedWallet(ctx context.Context, arg GetDeletedWalletParams) (Wallet, error) {
+	row := q.queryRow(ctx, q.getDeletedWalletStmt, getDeletedWallet, arg.ID, arg.UserID)
+	var i Wallet
+	err := row.Scan(
+		&i.UserID,
+		&i.Name,
+		&i.PublicKey,
+		&i.Mnemonic,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ID,
+		&i.IsDefault,
+		&i.FailedPinAttempts,
+		&i.LockedUntil,
+		&i.IsLocked,
+		&i.KeyVersion,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getDeletedWalletsByUserID = `-- name: GetDeletedWalletsByUserID :many
+SELECT user_id, name, public_key, mnemonic, created_at, updated_at, id, is_default, failed_pin_attempts, locked_until, is_locked, key_version, deleted_at FROM wallets WHERE user_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC
+`
+
+func (q *Queries) GetDeletedWalletsByUserID(ctx context.Context, userID string) ([]Wallet, error) {
+	rows, err := q.query(ctx, q.getDeletedWalletsByUserIDStmt, getDeletedWalletsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Wallet
+	for rows.Next() {
+		var i Wallet
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Name,
+			&i.PublicKey,
+			&i.Mnemonic,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ID,
+			&i.IsDefault,
+			&i.FailedPinAttempts,
+			&i.LockedUntil,
+			&i.IsLocked,
+			&i.KeyVersion,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getWallet = `-- name: GetWallet :one
-SELECT user_id, name, public_key, mnemonic, created_at, updated_at FROM wallets WHERE user_id = $1
+SELECT user_id, name, public_key, mnemonic, created_at, updated_at, id, is_default, failed_pin_attempts, locked_until, is_locked, key_version, deleted_at FROM wallets WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
 `
 
-func (q *Queries) GetWallet(ctx context.Context, userID string) (Wallet, error) {
-	row := q.queryRow(ctx, q.getWalletStmt, getWallet, userID)
+type GetWalletParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID string    `json:"user_id"`
+}
+
+func (q *Queries) GetWallet(ctx context.Context, arg GetWalletParams) (Wallet, error) {
+	row := q.queryRow(ctx, q.getWalletStmt, getWallet, arg.ID, arg.UserID)
 	var i Wallet
 	err := row.Scan(
 		&i.UserID,
@@ -62,12 +192,19 @@ func (q *Queries) GetWallet(ctx context.Context, userID string) (Wallet, error)
 		&i.Mnemonic,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ID,
+		&i.IsDefault,
+		&i.FailedPinAttempts,
+		&i.LockedUntil,
+		&i.IsLocked,
+		&i.KeyVersion,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getWalletByPublicKey = `-- name: GetWalletByPublicKey :one
-SELECT user_id, name, public_key, mnemonic, created_at, updated_at FROM wallets WHERE public_key = $1
+SELECT user_id, name, public_key, mnemonic, created_at, updated_at, id, is_default, failed_pin_attempts, locked_until, is_locked, key_version, deleted_at FROM wallets WHERE public_key = $1
 `
 
 func (q *Queries) GetWalletByPublicKey(ctx context.Context, publicKey string) (Wallet, error) {
@@ -80,22 +217,272 @@ func (q *Queries) GetWalletByPublicKey(ctx context.Context, publicKey string) (W
 		&i.Mnemonic,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ID,
+		&i.IsDefault,
+		&i.FailedPinAttempts,
+		&i.LockedUntil,
+		&i.IsLocked,
+		&i.KeyVersion,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getWalletForUpdate = `-- name: GetWalletForUpdate :one
+SELECT user_id, name, public_key, mnemonic, created_at, updated_at, id, is_default, failed_pin_attempts, locked_until, is_locked, key_version, deleted_at FROM wallets WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetWalletForUpdate(ctx context.Context, id uuid.UUID) (Wallet, error) {
+	row := q.queryRow(ctx, q.getWalletForUpdateStmt, getWalletForUpdate, id)
+	var i Wallet
+	err := row.Scan(
+		&i.UserID,
+		&i.Name,
+		&i.PublicKey,
+		&i.Mnemonic,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ID,
+		&i.IsDefault,
+		&i.FailedPinAttempts,
+		&i.LockedUntil,
+		&i.IsLocked,
+		&i.KeyVersion,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getWalletsByUserID = `-- name: GetWalletsByUserID :many
+SELECT user_id, name, public_key, mnemonic, created_at, updated_at, id, is_default, failed_pin_attempts, locked_until, is_locked, key_version, deleted_at FROM wallets WHERE user_id = $1 AND deleted_at IS NULL ORDER BY is_default DESC, created_at ASC
+`
+
+func (q *Queries) GetWalletsByUserID(ctx context.Context, userID string) ([]Wallet, error) {
+	rows, err := q.query(ctx, q.getWalletsByUserIDStmt, getWalletsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Wallet
+	for rows.Next() {
+		var i Wallet
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Name,
+			&i.PublicKey,
+			&i.Mnemonic,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ID,
+			&i.IsDefault,
+			&i.FailedPinAttempts,
+			&i.LockedUntil,
+			&i.IsLocked,
+			&i.KeyVersion,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWalletsForKeyRotation = `-- name: GetWalletsForKeyRotation :many
+SELECT user_id, name, public_key, mnemonic, created_at, updated_at, id, is_default, failed_pin_attempts, locked_until, is_locked, key_version, deleted_at FROM wallets WHERE id > $1 AND (key_version IS NULL OR key_version <> $2) ORDER BY id LIMIT $3
+`
+
+type GetWalletsForKeyRotationParams struct {
+	ID         uuid.UUID      `json:"id"`
+	KeyVersion sql.NullString `json:"key_version"`
+	Limit      int32          `json:"limit"`
+}
+
+func (q *Queries) GetWalletsForKeyRotation(ctx context.Context, arg GetWalletsForKeyRotationParams) ([]Wallet, error) {
+	rows, err := q.query(ctx, q.getWalletsForKeyRotationStmt, getWalletsForKeyRotation, arg.ID, arg.KeyVersion, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Wallet
+	for rows.Next() {
+		var i Wallet
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Name,
+			&i.PublicKey,
+			&i.Mnemonic,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ID,
+			&i.IsDefault,
+			&i.FailedPinAttempts,
+			&i.LockedUntil,
+			&i.IsLocked,
+			&i.KeyVersion,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementFailedPINAttempts = `-- name: IncrementFailedPINAttempts :one
+UPDATE wallets SET failed_pin_attempts = failed_pin_attempts + 1 WHERE id = $1 RETURNING failed_pin_attempts
+`
+
+func (q *Queries) IncrementFailedPINAttempts(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.queryRow(ctx, q.incrementFailedPINAttemptsStmt, incrementFailedPINAttempts, id)
+	var failed_pin_attempts int32
+	err := row.Scan(&failed_pin_attempts)
+	return failed_pin_attempts, err
+}
+
+const lockWallet = `-- name: LockWallet :exec
+UPDATE wallets SET locked_until = GREATEST(locked_until, $2), is_locked = is_locked OR $3 WHERE id = $1
+`
+
+type LockWalletParams struct {
+	ID          uuid.UUID    `json:"id"`
+	LockedUntil sql.NullTime `json:"locked_until"`
+	IsLocked    bool         `json:"is_locked"`
+}
+
+func (q *Queries) LockWallet(ctx context.Context, arg LockWalletParams) error {
+	_, err := q.exec(ctx, q.lockWalletStmt, lockWallet, arg.ID, arg.LockedUntil, arg.IsLocked)
+	return err
+}
+
+const purgeDeletedWallets = `-- name: PurgeDeletedWallets :execrows
+DELETE FROM wallets WHERE deleted_at IS NOT NULL AND deleted_at < $1
+`
+
+func (q *Queries) PurgeDeletedWallets(ctx context.Context, deletedAt sql.NullTime) (int64, error) {
+	result, err := q.exec(ctx, q.purgeDeletedWalletsStmt, purgeDeletedWallets, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const resetFailedPINAttempts = `-- name: ResetFailedPINAttempts :exec
+UPDATE wallets SET failed_pin_attempts = 0, locked_until = NULL WHERE id = $1 AND NOT is_locked
+`
+
+func (q *Queries) ResetFailedPINAttempts(ctx context.Context, id uuid.UUID) error {
+	_, err := q.exec(ctx, q.resetFailedPINAttemptsStmt, resetFailedPINAttempts, id)
+	return err
+}
+
+const restoreWallet = `-- name: RestoreWallet :one
+UPDATE wallets SET deleted_at = NULL, is_default = $2 WHERE id = $1 RETURNING user_id, name, public_key, mnemonic, created_at, updated_at, id, is_default, failed_pin_attempts, locked_until, is_locked, key_version, deleted_at
+`
+
+type RestoreWalletParams struct {
+	ID        uuid.UUID `json:"id"`
+	IsDefault bool      `json:"is_default"`
+}
+
+func (q *Queries) RestoreWallet(ctx context.Context, arg RestoreWalletParams) (Wallet, error) {
+	row := q.queryRow(ctx, q.restoreWalletStmt, restoreWallet, arg.ID, arg.IsDefault)
+	var i Wallet
+	err := row.Scan(
+		&i.UserID,
+		&i.Name,
+		&i.PublicKey,
+		&i.Mnemonic,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ID,
+		&i.IsDefault,
+		&i.FailedPinAttempts,
+		&i.LockedUntil,
+		&i.IsLocked,
+		&i.KeyVersion,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
+const rotateWalletMnemonic = `-- name: RotateWalletMnemonic :execrows
+UPDATE wallets SET mnemonic = $1, key_version = $2 WHERE id = $3 AND mnemonic = $4
+`
+
+type RotateWalletMnemonicParams struct {
+	NewMnemonic string         `json:"new_mnemonic"`
+	KeyVersion  sql.NullString `json:"key_version"`
+	ID          uuid.UUID      `json:"id"`
+	OldMnemonic string         `json:"old_mnemonic"`
+}
+
+func (q *Queries) RotateWalletMnemonic(ctx context.Context, arg RotateWalletMnemonicParams) (int64, error) {
+	result, err := q.exec(ctx, q.rotateWalletMnemonicStmt, rotateWalletMnemonic,
+		arg.NewMnemonic,
+		arg.KeyVersion,
+		arg.ID,
+		arg.OldMnemonic,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setDefaultWallet = `-- name: SetDefaultWallet :exec
+UPDATE wallets SET is_default = true WHERE id = $1
+`
+
+func (q *Queries) SetDefaultWallet(ctx context.Context, id uuid.UUID) error {
+	_, err := q.exec(ctx, q.setDefaultWalletStmt, setDefaultWallet, id)
+	return err
+}
+
+const softDeleteWallet = `-- name: SoftDeleteWallet :exec
+UPDATE wallets SET deleted_at = $2, is_default = false WHERE id = $1
+`
+
+type SoftDeleteWalletParams struct {
+	ID        uuid.UUID    `json:"id"`
+	DeletedAt sql.NullTime `json:"deleted_at"`
+}
+
+func (q *Queries) SoftDeleteWallet(ctx context.Context, arg SoftDeleteWalletParams) error {
+	_, err := q.exec(ctx, q.softDeleteWalletStmt, softDeleteWallet, arg.ID, arg.DeletedAt)
+	return err
+}
+
 const updateWallet = `-- name: UpdateWallet :one
-UPDATE wallets SET name = $2, mnemonic = $3 WHERE user_id = $1 RETURNING user_id, name, public_key, mnemonic, created_at, updated_at
+UPDATE wallets SET name = $2, mnemonic = $3, key_version = $4 WHERE id = $1 RETURNING user_id, name, public_key, mnemonic, created_at, updated_at, id, is_default, failed_pin_attempts, locked_until, is_locked, key_version, deleted_at
 `
 
 type UpdateWalletParams struct {
-	UserID   string `json:"user_id"`
-	Name     string `json:"name"`
-	Mnemonic string `json:"mnemonic"`
+	ID         uuid.UUID      `json:"id"`
+	Name       string         `json:"name"`
+	Mnemonic   string         `json:"mnemonic"`
+	KeyVersion sql.NullString `json:"key_version"`
 }
 
 func (q *Queries) UpdateWallet(ctx context.Context, arg UpdateWalletParams) (Wallet, error) {
-	row := q.queryRow(ctx, q.updateWalletStmt, updateWallet, arg.UserID, arg.Name, arg.Mnemonic)
+	row := q.queryRow(ctx, q.updateWalletStmt, updateWallet,
+		arg.ID,
+		arg.Name,
+		arg.Mnemonic,
+		arg.KeyVersion,
+	)
 	var i Wallet
 	err := row.Scan(
 		&i.UserID,
@@ -104,6 +491,13 @@ func (q *Queries) UpdateWallet(ctx context.Context, arg UpdateWalletParams) (Wal
 		&i.Mnemonic,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ID,
+		&i.IsDefault,
+		&i.FailedPinAttempts,
+		&i.LockedUntil,
+		&i.IsLocked,
+		&i.KeyVersion,
+		&i.DeletedAt,
 	)
 	return i, err
 }
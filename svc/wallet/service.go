@@ -5,82 +5,252 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/dmitrymomot/solana-wallets/internal/events"
+	"github.com/dmitrymomot/solana-wallets/internal/signingpolicy"
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
 	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
 	"github.com/dmitrymomot/solana-wallets/internal/utils"
 	wallet_repository "github.com/dmitrymomot/solana-wallets/svc/wallet/repository"
+	"github.com/dmitrymomot/solana/client"
+	"github.com/dmitrymomot/solana/token_metadata"
+	"github.com/google/uuid"
+	"github.com/portto/solana-go-sdk/rpc"
 	"github.com/portto/solana-go-sdk/types"
 )
 
 type (
 	// Service interface
 	Service interface {
-		// Generate new wallet
-		GenerateWallet(ctx context.Context) (Wallet, error)
-		// Store wallet
-		StoreWallet(ctx context.Context, uid, pin, mnemonic, name string) error
-		// Get wallet by user id
-		GetWallet(ctx context.Context, uid string) (Wallet, error)
-		// Delete wallet by user id
-		DeleteWallet(ctx context.Context, uid string, pin string) error
+		// Generate new wallet with the given number of mnemonic words and BIP39 wordlist language,
+		// defaults are 12 words and english.
+		// The optional passphrase is the BIP39 "25th word" used to derive the accounts.
+		GenerateWallet(ctx context.Context, words int, language, passphrase string) (Wallet, error)
+		// Store wallet.
+		// The optional passphrase is encrypted together with the mnemonic.
+		StoreWallet(ctx context.Context, uid, pin, mnemonic, passphrase, name string) (Wallet, error)
+		// Import wallet from the base58 encoded private key or the solana-keygen keypair file content.
+		// Such wallets have the only account and can't derive additional ones.
+		ImportWallet(ctx context.Context, uid, pin, privateKey, name string) (Wallet, error)
+		// Import wallet from the keystore file encrypted with the export password
+		ImportKeystore(ctx context.Context, uid, pin, keystore, password, name string) (Wallet, error)
+		// List all wallets of the user
+		ListWallets(ctx context.Context, uid string) ([]Wallet, error)
+		// Get wallet by user id and wallet id.
+		// If wallet id is empty, returns the default user's wallet.
+		GetWallet(ctx context.Context, uid, walletID string) (Wallet, error)
+		// Delete wallet by user id and wallet id.
+		// The wallet is kept pending deletion for the grace period and can be restored in the meantime.
+		DeleteWallet(ctx context.Context, uid, walletID, pin string) error
+		// List wallets pending deletion which can still be restored
+		ListDeletedWallets(ctx context.Context, uid string) ([]Wallet, error)
+		// Restore wallet pending deletion
+		RestoreWallet(ctx context.Context, uid, walletID, pin string) (Wallet, error)
+		// Permanently delete wallets whose grace period is over, returns the number of purged wallets
+		PurgeDeletedWallets(ctx context.Context) (int64, error)
+		// List audit log entries of the sensitive wallet operations, newest first.
+		// Empty uid lists the entries of all users, empty walletID - of all user's wallets.
+		ListAuditLog(ctx context.Context, uid, walletID string, limit, offset int) ([]AuditLogEntry, error)
 		// Update wallet name
-		UpdateWalletName(ctx context.Context, uid string, pin string, name string) error
+		UpdateWalletName(ctx context.Context, uid, walletID, pin, name string) error
 		// Change wallet pin
-		ChangeWalletPin(ctx context.Context, uid string, pin string, newPin string) error
-		// Export wallet
-		ExportWallet(ctx context.Context, uid string, pin string) (Wallet, error)
+		ChangeWalletPin(ctx context.Context, uid, walletID, pin, newPin string) error
+		// Export wallet in the given format: plain (default), keypair or keystore.
+		// The keystore format requires the export password.
+		ExportWallet(ctx context.Context, uid, walletID, pin, format, password string) (Wallet, error)
+		// Derive a new account with the given index from the wallet mnemonic.
+		// If index is 0, the next free index is used.
+		AddAccount(ctx context.Context, uid, walletID, pin string, index int, name string) (Account, error)
+		// List all accounts derived from the wallet mnemonic
+		ListAccounts(ctx context.Context, uid, walletID string) ([]Account, error)
 		// Sign transaction and return signed transaction as base64 string
-		SignTransaction(ctx context.Context, uid string, pin string, base64Tx string) (string, error)
+		SignTransaction(ctx context.Context, uid, walletID string, accountIndex int, pin, base64Tx string) (string, error)
 		// Sign message and return signed message as base64 string
-		SignMessage(ctx context.Context, uid string, pin string, base64Msg string) (msg, signature string, err error)
-		// Sign and send transaction, return transaction signature
-		SignAndSendTransaction(ctx context.Context, uid string, pin string, base64Tx string) (string, error)
+		SignMessage(ctx context.Context, uid, walletID string, accountIndex int, pin, base64Msg string) (msg, signature string, err error)
+		// Sign and send transaction, return transaction signature.
+		// The transaction is simulated before sending unless disabled, the simulation result is nil then.
+		SignAndSendTransaction(ctx context.Context, uid, walletID string, accountIndex int, pin, base64Tx string, opts SendOptions) (string, *solanatx.Simulation, error)
+		// Decode the transaction to show what will be signed, before the PIN is entered
+		PreviewTransaction(ctx context.Context, base64Tx string) (solanatx.Transaction, error)
+		// Transfer SOL from the wallet account to the destination address,
+		// return transaction signature and the resolved compute budget.
+		// The amount is a decimal string in SOL, the memo is optional.
+		TransferSOL(ctx context.Context, uid, walletID string, accountIndex int, pin, destination, amount, memo string, opts TransferOptions) (string, ComputeBudget, error)
+		// Transfer SPL tokens from the wallet account to the destination wallet,
+		// return transaction signature and the resolved compute budget.
+		// The amount is a decimal string in the token units, the destination token account is created if missing.
+		TransferToken(ctx context.Context, uid, walletID string, accountIndex int, pin, mint, destination, amount, memo string, opts TransferOptions) (string, ComputeBudget, error)
+		// Transfer the NFT or pNFT from the wallet account to the destination wallet,
+		// return transaction signature and the resolved compute budget
+		TransferNFT(ctx context.Context, uid, walletID string, accountIndex int, pin, mint, destination, memo string, opts TransferOptions) (string, ComputeBudget, error)
+		// Create a durable nonce account owned by the wallet account and funded from it,
+		// return the nonce account and the creation transaction signature
+		CreateNonceAccount(ctx context.Context, uid, walletID string, accountIndex int, pin string) (NonceAccount, string, error)
+		// List the open durable nonce accounts of the user's wallets.
+		// Empty walletID lists the nonce accounts of all user's wallets.
+		ListNonceAccounts(ctx context.Context, uid, walletID string) ([]NonceAccount, error)
+		// Get the open durable nonce account of the user's wallet by its address
+		GetNonceAccount(ctx context.Context, uid, address string) (NonceAccount, error)
+		// Close the durable nonce account withdrawing its lamports to the wallet account,
+		// return transaction signature
+		CloseNonceAccount(ctx context.Context, uid, address, pin string) (string, error)
+		// Simulate the transaction on behalf of the wallet account without signing it
+		SimulateTransaction(ctx context.Context, uid, walletID string, accountIndex int, base64Tx string) (solanatx.Simulation, error)
+		// List the transactions sent from the user's wallets, newest first.
+		// Empty walletID lists the transactions of all user's wallets, empty status - in any status.
+		ListTransactions(ctx context.Context, uid, walletID, status string, limit, offset int) ([]Transaction, error)
+		// Get the transaction sent from the user's wallet by its signature
+		GetTransaction(ctx context.Context, uid, signature string) (Transaction, error)
+		// Update the statuses of the sent transactions which are not settled yet,
+		// returns the number of updated transactions
+		UpdateTransactionStatuses(ctx context.Context) (int, error)
+		// Get the wallet signing policy, the effective one with the defaults applied and today's outflows
+		GetSigningPolicy(ctx context.Context, uid, walletID string) (SigningPolicy, error)
+		// Replace the wallet signing policy, nil fields fall back to the default policy
+		UpdateSigningPolicy(ctx context.Context, uid, walletID, pin string, policy signingpolicy.Policy) (SigningPolicy, error)
 	}
 
 	// service struct
 	service struct {
-		repo   walletRepository
-		wallet solanaWallet
-		solana solanaClient
+		repo                walletRepository
+		db                  *sql.DB
+		wallet              solanaWallet
+		solana              solanaClient
+		lockout             PINLockoutPolicy
+		deletionGracePeriod time.Duration
+		log                 logger
+		events              events.Emitter
+		defaultPolicy       signingpolicy.Policy
+		// simulate transactions before sending by default
+		simulateBeforeSending bool
+		txWatchBatchSize      int
+		// priority fee estimation of the transactions built by the server
+		priorityFeePercentile int
+		maxComputeUnitPrice   uint64
+		// limits the concurrent keystore decryptions, each one may take up to 256 MiB
+		keystoreImports chan struct{}
+	}
+
+	// txRepository is implemented by the repositories managing their own transactions, e.g. in-memory ones
+	txRepository interface {
+		InTx(ctx context.Context, fn func(repo walletRepository) error) error
 	}
 
 	walletRepository interface {
+		CountWalletsByUserID(ctx context.Context, userID string) (int64, error)
 		CreateWallet(ctx context.Context, arg wallet_repository.CreateWalletParams) (wallet_repository.Wallet, error)
-		DeleteWallet(ctx context.Context, userID string) error
-		GetWallet(ctx context.Context, userID string) (wallet_repository.Wallet, error)
+		SoftDeleteWallet(ctx context.Context, arg wallet_repository.SoftDeleteWalletParams) error
+		GetDeletedWallet(ctx context.Context, arg wallet_repository.GetDeletedWalletParams) (wallet_repository.Wallet, error)
+		GetDeletedWalletsByUserID(ctx context.Context, userID string) ([]wallet_repository.Wallet, error)
+		RestoreWallet(ctx context.Context, arg wallet_repository.RestoreWalletParams) (wallet_repository.Wallet, error)
+		PurgeDeletedWallets(ctx context.Context, deletedAt sql.NullTime) (int64, error)
+		SetDefaultWallet(ctx context.Context, id uuid.UUID) error
+		GetDefaultWallet(ctx context.Context, userID string) (wallet_repository.Wallet, error)
+		GetWallet(ctx context.Context, arg wallet_repository.GetWalletParams) (wallet_repository.Wallet, error)
+		GetWalletsByUserID(ctx context.Context, userID string) ([]wallet_repository.Wallet, error)
 		GetWalletByPublicKey(ctx context.Context, publicKey string) (wallet_repository.Wallet, error)
+		GetWalletForUpdate(ctx context.Context, id uuid.UUID) (wallet_repository.Wallet, error)
 		UpdateWallet(ctx context.Context, arg wallet_repository.UpdateWalletParams) (wallet_repository.Wallet, error)
+		CreateWalletAccount(ctx context.Context, arg wallet_repository.CreateWalletAccountParams) (wallet_repository.WalletAccount, error)
+		GetWalletAccount(ctx context.Context, arg wallet_repository.GetWalletAccountParams) (wallet_repository.WalletAccount, error)
+		GetWalletAccounts(ctx context.Context, walletID uuid.UUID) ([]wallet_repository.WalletAccount, error)
+		GetLastWalletAccountIndex(ctx context.Context, walletID uuid.UUID) (int32, error)
+		IncrementFailedPINAttempts(ctx context.Context, id uuid.UUID) (int32, error)
+		LockWallet(ctx context.Context, arg wallet_repository.LockWalletParams) error
+		ResetFailedPINAttempts(ctx context.Context, id uuid.UUID) error
+		CreateAuditLogEntry(ctx context.Context, arg wallet_repository.CreateAuditLogEntryParams) error
+		GetAuditLog(ctx context.Context, arg wallet_repository.GetAuditLogParams) ([]wallet_repository.WalletAuditLog, error)
+		GetSigningPolicy(ctx context.Context, walletID uuid.UUID) (wallet_repository.WalletSigningPolicy, error)
+		UpsertSigningPolicy(ctx context.Context, arg wallet_repository.UpsertSigningPolicyParams) (wallet_repository.WalletSigningPolicy, error)
+		CreateWalletOutflow(ctx context.Context, arg wallet_repository.CreateWalletOutflowParams) error
+		GetWalletOutflowsSince(ctx context.Context, arg wallet_repository.GetWalletOutflowsSinceParams) ([]wallet_repository.GetWalletOutflowsSinceRow, error)
+		CreateWalletTransaction(ctx context.Context, arg wallet_repository.CreateWalletTransactionParams) (wallet_repository.WalletTransaction, error)
+		GetWalletTransaction(ctx context.Context, arg wallet_repository.GetWalletTransactionParams) (wallet_repository.WalletTransaction, error)
+		GetWalletTransactions(ctx context.Context, arg wallet_repository.GetWalletTransactionsParams) ([]wallet_repository.WalletTransaction, error)
+		GetUnsettledWalletTransactions(ctx context.Context, limit int32) ([]wallet_repository.WalletTransaction, error)
+		UpdateWalletTransactionStatus(ctx context.Context, arg wallet_repository.UpdateWalletTransactionStatusParams) error
+		CreateWalletNonceAccount(ctx context.Context, arg wallet_repository.CreateWalletNonceAccountParams) (wallet_repository.WalletNonceAccount, error)
+		GetWalletNonceAccount(ctx context.Context, arg wallet_repository.GetWalletNonceAccountParams) (wallet_repository.WalletNonceAccount, error)
+		GetWalletNonceAccounts(ctx context.Context, arg wallet_repository.GetWalletNonceAccountsParams) ([]wallet_repository.WalletNonceAccount, error)
+		CloseWalletNonceAccount(ctx context.Context, id uuid.UUID) error
 	}
 
 	solanaWallet interface {
-		EnctyptMnemonic(mnemonic, pin string) (string, error)
-		DecryptMnemonic(encrypted, pin string) (string, error)
+		EnctyptMnemonic(ctx context.Context, mnemonic, pin string, ad solanawallet.AssociatedData) (string, error)
+		DecryptMnemonic(ctx context.Context, encrypted, pin string, ad solanawallet.AssociatedData) (string, error)
+		NeedsReencryption(encrypted string) bool
+		KeyVersion(encrypted string) string
 	}
 
 	solanaClient interface {
 		SignTransaction(ctx context.Context, wallet types.Account, txSource string) (string, error)
 		SendTransaction(ctx context.Context, txSource string, i ...uint8) (string, error)
+		NewTransaction(ctx context.Context, params client.NewTransactionParams) (string, error)
+		NewDurableTransaction(ctx context.Context, params client.NewDurableTransactionParams) (string, error)
+		GetTransactionFee(ctx context.Context, txSource string) (uint64, error)
+		GetSOLBalance(ctx context.Context, base58Addr string) (uint64, error)
+		GetMinimumBalanceForRentExemption(ctx context.Context, size uint64) (uint64, error)
+		GetMintDecimals(ctx context.Context, base58MintAddr string) (uint8, error)
+		GetTokenAccountMint(ctx context.Context, base58Addr string) (string, error)
+		GetTokenMetadata(ctx context.Context, base58MintAddr string) (*token_metadata.Metadata, error)
+		GetAccountStates(ctx context.Context, addresses []string) ([]*solanatx.AccountState, error)
+		SimulateTransaction(ctx context.Context, base64Tx string, addresses []string) (solanatx.Simulation, []*solanatx.AccountState, error)
+		GetSignatureStatuses(ctx context.Context, signatures []string) ([]*rpc.SignatureStatus, error)
+		IsBlockhashValid(ctx context.Context, blockhash string) (bool, error)
+		GetRecentPrioritizationFees(ctx context.Context, addresses []string) ([]uint64, error)
 	}
 )
 
 // NewService is a factory function,
-// returns a new instance of the Service interface implementation
-func NewService(repo walletRepository, wallet solanaWallet, solana solanaClient) Service {
-	return &service{
-		repo:   repo,
-		wallet: wallet,
-		solana: solana,
+// returns a new instance of the Service interface implementation.
+// The emitter is optional, nil disables the events.
+func NewService(repo walletRepository, wallet solanaWallet, solana solanaClient, emitter events.Emitter, opts ...Option) Service {
+	s := &service{
+		repo:                  repo,
+		wallet:                wallet,
+		solana:                solana,
+		events:                emitter,
+		lockout:               DefaultPINLockoutPolicy,
+		deletionGracePeriod:   DefaultDeletionGracePeriod,
+		simulateBeforeSending: true,
+		txWatchBatchSize:      DefaultTransactionWatchBatchSize,
+		priorityFeePercentile: DefaultPriorityFeePercentile,
+		maxComputeUnitPrice:   DefaultMaxComputeUnitPrice,
+		keystoreImports:       make(chan struct{}, DefaultMaxConcurrentKeystoreImports),
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 // Generate new wallet
-func (s *service) GenerateWallet(ctx context.Context) (Wallet, error) {
-	mnemonic, err := solanawallet.NewMnemonic(solanawallet.MnemonicLength12)
+func (s *service) GenerateWallet(ctx context.Context, words int, language, passphrase string) (Wallet, error) {
+	if words == 0 {
+		words = 12
+	}
+	length, err := solanawallet.MnemonicLengthFromWords(words)
+	if err != nil {
+		return Wallet{}, fmt.Errorf("%w: %s", ErrInvalidParameter, err)
+	}
+
+	lang := solanawallet.LanguageEnglish
+	if language != "" {
+		lang = solanawallet.Language(language)
+	}
+	if err := lang.Validate(); err != nil {
+		return Wallet{}, fmt.Errorf("%w: %s", ErrInvalidParameter, err)
+	}
+
+	mnemonic, err := solanawallet.NewMnemonicWithLanguage(length, lang)
 	if err != nil {
 		return Wallet{}, fmt.Errorf("failed to generate mnemonic: %w", err)
 	}
 
-	acc, err := solanawallet.DeriveAccountFromMnemonicBip44(mnemonic)
+	acc, err := solanawallet.DeriveAccountFromMnemonicBip44WithPassphrase(mnemonic, passphrase, 0)
 	if err != nil {
 		return Wallet{}, fmt.Errorf("failed to derive account from mnemonic: %w", err)
 	}
@@ -88,89 +258,358 @@ func (s *service) GenerateWallet(ctx context.Context) (Wallet, error) {
 	return Wallet{
 		Name:       "Wallet",
 		Mnemonic:   mnemonic,
+		Passphrase: passphrase,
 		PublicKey:  acc.PublicKey.ToBase58(),
 		PrivateKey: utils.BytesToBase58(acc.PrivateKey),
 	}, nil
 }
 
 // Store wallet
-func (s *service) StoreWallet(ctx context.Context, uid, pin, mnemonic, name string) error {
-	acc, err := solanawallet.DeriveAccountFromMnemonicBip44(mnemonic)
+func (s *service) StoreWallet(ctx context.Context, uid, pin, mnemonic, passphrase, name string) (_ Wallet, err error) {
+	a := newAuditRecord(uid, AuditActionStore)
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	if _, err := solanawallet.ValidateMnemonic(mnemonic); err != nil {
+		return Wallet{}, ErrInvalidMnemonic
+	}
+
+	return s.createWallet(ctx, a, events.WalletSourceMnemonic, uid, pin, name, solanawallet.Secret{
+		Mnemonic:   mnemonic,
+		Passphrase: passphrase,
+	})
+}
+
+// Import wallet from the base58 encoded private key or the solana-keygen keypair file content
+func (s *service) ImportWallet(ctx context.Context, uid, pin, privateKey, name string) (_ Wallet, err error) {
+	a := newAuditRecord(uid, AuditActionImport)
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	acc, err := solanawallet.ParsePrivateKey(privateKey)
+	if err != nil {
+		return Wallet{}, fmt.Errorf("%w: %s", ErrInvalidPrivateKey, err)
+	}
+
+	return s.createWallet(ctx, a, events.WalletSourcePrivateKey, uid, pin, name, solanawallet.Secret{
+		PrivateKey: solanawallet.AccountToBase58(acc),
+	})
+}
+
+// Import wallet from the keystore file encrypted with the export password
+func (s *service) ImportKeystore(ctx context.Context, uid, pin, keystore, password, name string) (_ Wallet, err error) {
+	a := newAuditRecord(uid, AuditActionImport)
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	ks, err := solanawallet.ParseKeystore([]byte(keystore))
+	if err != nil {
+		return Wallet{}, fmt.Errorf("%w: %s", ErrInvalidKeystore, err)
+	}
+
+	secret, err := s.decryptKeystore(ks, password)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	if secret.HasMnemonic() {
+		if _, err := solanawallet.ValidateMnemonic(secret.Mnemonic); err != nil {
+			return Wallet{}, ErrInvalidMnemonic
+		}
+	}
+
+	return s.createWallet(ctx, a, events.WalletSourceKeystore, uid, pin, name, secret)
+}
+
+// decryptKeystore decrypts the keystore file unless too many of them are being decrypted at once,
+// since the scrypt key derivation is memory-hard
+func (s *service) decryptKeystore(ks solanawallet.Keystore, password string) (solanawallet.Secret, error) {
+	select {
+	case s.keystoreImports <- struct{}{}:
+		defer func() { <-s.keystoreImports }()
+	default:
+		return solanawallet.Secret{}, ErrTooManyImports
+	}
+
+	secret, err := solanawallet.DecryptKeystore(ks, password)
+	if err != nil {
+		return solanawallet.Secret{}, fmt.Errorf("%w: %s", ErrInvalidKeystore, err)
+	}
+	return secret, nil
+}
+
+// create wallet with the given secret encrypted under the pin,
+// source is one of the events.WalletSource* values
+func (s *service) createWallet(ctx context.Context, audit *auditRecord, source, uid, pin, name string, secret solanawallet.Secret) (Wallet, error) {
+	audit.details["source"] = source
+
+	acc, err := secret.DeriveAccount(0)
+	if err != nil {
+		return Wallet{}, fmt.Errorf("failed to derive wallet account: %w", err)
+	}
+
+	plaintext, err := secret.Encode()
 	if err != nil {
-		return fmt.Errorf("failed to derive account from mnemonic: %w", err)
+		return Wallet{}, err
 	}
 
 	if name == "" {
 		name = "Wallet"
 	}
 
-	encrypted, err := s.wallet.EnctyptMnemonic(mnemonic, pin)
-	if err != nil {
-		return fmt.Errorf("failed to encrypt mnemonic: %w", err)
+	// wallet id is generated in advance to bind the encrypted mnemonic to the record
+	id := uuid.New()
+	audit.walletID = id
+	publicKey := acc.PublicKey.ToBase58()
+
+	if _, err := s.repo.GetWalletByPublicKey(ctx, publicKey); err == nil {
+		return Wallet{}, ErrAlreadyExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return Wallet{}, fmt.Errorf("failed to get wallet by public key: %w", err)
 	}
 
-	if _, err := s.repo.CreateWallet(ctx, wallet_repository.CreateWalletParams{
+	encrypted, err := s.wallet.EnctyptMnemonic(ctx, plaintext, pin, solanawallet.AssociatedData{
 		UserID:    uid,
-		Name:      name,
-		PublicKey: acc.PublicKey.ToBase58(),
-		Mnemonic:  encrypted,
+		WalletID:  id.String(),
+		PublicKey: publicKey,
+	})
+	if err != nil {
+		return Wallet{}, fmt.Errorf("failed to encrypt mnemonic: %w", err)
+	}
+
+	// the first stored wallet becomes the default one
+	count, err := s.repo.CountWalletsByUserID(ctx, uid)
+	if err != nil {
+		return Wallet{}, fmt.Errorf("failed to count user wallets: %w", err)
+	}
+
+	var (
+		w       wallet_repository.Wallet
+		payload events.WalletCreatedPayload
+	)
+	if err := s.inTx(ctx, func(repo walletRepository, tx *sql.Tx) error {
+		w, err = repo.CreateWallet(ctx, wallet_repository.CreateWalletParams{
+			ID:         id,
+			UserID:     uid,
+			Name:       name,
+			PublicKey:  publicKey,
+			Mnemonic:   encrypted,
+			IsDefault:  count == 0,
+			KeyVersion: s.keyVersion(encrypted),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create wallet: %w", err)
+		}
+
+		payload = events.WalletCreatedPayload{
+			UserID:     uid,
+			WalletID:   w.ID.String(),
+			PublicKey:  w.PublicKey,
+			IsDefault:  w.IsDefault,
+			Source:     source,
+			OccurredAt: time.Now().UTC(),
+		}
+		return s.emitTx(ctx, tx, events.WalletCreated, payload)
 	}); err != nil {
-		return fmt.Errorf("failed to create wallet: %w", err)
+		return Wallet{}, err
 	}
+	s.emit(events.WalletCreated, payload)
 
-	return nil
+	return castWallet(w), nil
 }
 
-// Get wallet by user id
-func (s *service) GetWallet(ctx context.Context, uid string) (Wallet, error) {
-	w, err := s.repo.GetWallet(ctx, uid)
+// List all wallets of the user
+func (s *service) ListWallets(ctx context.Context, uid string) ([]Wallet, error) {
+	wallets, err := s.repo.GetWalletsByUserID(ctx, uid)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return Wallet{}, ErrNotFound
-		}
-		return Wallet{}, fmt.Errorf("failed to get wallet: %w", err)
+		return nil, fmt.Errorf("failed to get wallets list: %w", err)
 	}
 
-	return Wallet{Name: w.Name, PublicKey: w.PublicKey}, nil
+	result := make([]Wallet, 0, len(wallets))
+	for _, w := range wallets {
+		result = append(result, castWallet(w))
+	}
+
+	return result, nil
 }
 
-// Delete wallet by user id
-func (s *service) DeleteWallet(ctx context.Context, uid string, pin string) error {
-	w, err := s.repo.GetWallet(ctx, uid)
+// Get wallet by user id and wallet id.
+// If wallet id is empty, returns the default user's wallet.
+func (s *service) GetWallet(ctx context.Context, uid, walletID string) (Wallet, error) {
+	w, err := s.getWallet(ctx, uid, walletID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		return Wallet{}, err
+	}
+
+	return castWallet(w), nil
+}
+
+// Delete wallet by user id and wallet id.
+// The wallet is kept pending deletion for the grace period and can be restored in the meantime.
+func (s *service) DeleteWallet(ctx context.Context, uid, walletID, pin string) (err error) {
+	a := newAuditRecord(uid, AuditActionDelete)
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	w, err := s.getWallet(ctx, uid, walletID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
 			return nil
 		}
-		return fmt.Errorf("failed to get wallet: %w", err)
+		return err
 	}
+	a.walletID = w.ID
 
-	mnemonic, err := s.wallet.DecryptMnemonic(w.Mnemonic, pin)
-	if err != nil || mnemonic == "" {
-		return ErrInvalidPIN
+	if _, err := s.decryptSecret(ctx, &w, pin); err != nil {
+		return err
 	}
 
-	if err := s.repo.DeleteWallet(ctx, uid); err != nil {
-		if !errors.Is(err, sql.ErrNoRows) {
+	deletedAt := time.Now().UTC()
+	payload := events.WalletDeletedPayload{
+		UserID:     uid,
+		WalletID:   w.ID.String(),
+		PublicKey:  w.PublicKey,
+		PurgeAt:    deletedAt.Add(s.deletionGracePeriod),
+		OccurredAt: deletedAt,
+	}
+	if err := s.inTx(ctx, func(repo walletRepository, tx *sql.Tx) error {
+		if err := repo.SoftDeleteWallet(ctx, wallet_repository.SoftDeleteWalletParams{
+			ID:        w.ID,
+			DeletedAt: sql.NullTime{Time: deletedAt, Valid: true},
+		}); err != nil {
 			return fmt.Errorf("failed to delete wallet: %w", err)
 		}
+
+		// the oldest remaining wallet becomes the default one
+		if w.IsDefault {
+			next, err := repo.GetDefaultWallet(ctx, uid)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("failed to get default wallet: %w", err)
+			}
+			if err == nil {
+				if err := repo.SetDefaultWallet(ctx, next.ID); err != nil {
+					return fmt.Errorf("failed to set default wallet: %w", err)
+				}
+			}
+		}
+
+		return s.emitTx(ctx, tx, events.WalletDeleted, payload)
+	}); err != nil {
+		return err
 	}
+	s.emit(events.WalletDeleted, payload)
 
 	return nil
 }
 
-// Update wallet name
-func (s *service) UpdateWalletName(ctx context.Context, uid, pin, name string) error {
-	w, err := s.repo.GetWallet(ctx, uid)
+// List wallets pending deletion which can still be restored
+func (s *service) ListDeletedWallets(ctx context.Context, uid string) ([]Wallet, error) {
+	wallets, err := s.repo.GetDeletedWalletsByUserID(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted wallets list: %w", err)
+	}
+
+	result := make([]Wallet, 0, len(wallets))
+	for _, w := range wallets {
+		// expired wallets are waiting for the purge job
+		if s.deletionExpired(w) {
+			continue
+		}
+		result = append(result, s.castDeletedWallet(w))
+	}
+
+	return result, nil
+}
+
+// Restore wallet pending deletion
+func (s *service) RestoreWallet(ctx context.Context, uid, walletID, pin string) (_ Wallet, err error) {
+	a := newAuditRecord(uid, AuditActionRestore)
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	id, err := uuid.Parse(walletID)
+	if err != nil {
+		return Wallet{}, ErrInvalidParameter
+	}
+	a.walletID = id
+
+	w, err := s.repo.GetDeletedWallet(ctx, wallet_repository.GetDeletedWalletParams{
+		ID:     id,
+		UserID: uid,
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
+			return Wallet{}, ErrNotFound
+		}
+		return Wallet{}, fmt.Errorf("failed to get deleted wallet: %w", err)
+	}
+	if s.deletionExpired(w) {
+		return Wallet{}, ErrNotFound
+	}
+
+	if _, err := s.decryptSecret(ctx, &w, pin); err != nil {
+		return Wallet{}, err
+	}
+
+	// the restored wallet becomes the default one if the user has no other wallets
+	count, err := s.repo.CountWalletsByUserID(ctx, uid)
+	if err != nil {
+		return Wallet{}, fmt.Errorf("failed to count user wallets: %w", err)
+	}
+
+	var (
+		restored wallet_repository.Wallet
+		payload  events.WalletRestoredPayload
+	)
+	if err := s.inTx(ctx, func(repo walletRepository, tx *sql.Tx) error {
+		restored, err = repo.RestoreWallet(ctx, wallet_repository.RestoreWalletParams{
+			ID:        w.ID,
+			IsDefault: count == 0,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore wallet: %w", err)
+		}
+
+		payload = events.WalletRestoredPayload{
+			UserID:     uid,
+			WalletID:   restored.ID.String(),
+			PublicKey:  restored.PublicKey,
+			OccurredAt: time.Now().UTC(),
+		}
+		return s.emitTx(ctx, tx, events.WalletRestored, payload)
+	}); err != nil {
+		return Wallet{}, err
+	}
+	s.emit(events.WalletRestored, payload)
+
+	return castWallet(restored), nil
+}
+
+// Permanently delete wallets whose grace period is over, returns the number of purged wallets
+func (s *service) PurgeDeletedWallets(ctx context.Context) (int64, error) {
+	purged, err := s.repo.PurgeDeletedWallets(ctx, sql.NullTime{
+		Time:  time.Now().UTC().Add(-s.deletionGracePeriod),
+		Valid: true,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted wallets: %w", err)
+	}
+
+	return purged, nil
+}
+
+// Update wallet name
+func (s *service) UpdateWalletName(ctx context.Context, uid, walletID, pin, name string) (err error) {
+	a := newAuditRecord(uid, AuditActionRename)
+	a.details["name"] = name
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	w, err := s.getWallet(ctx, uid, walletID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
 			return nil
 		}
-		return fmt.Errorf("failed to get wallet: %w", err)
+		return err
 	}
+	a.walletID = w.ID
 
-	mnemonic, err := s.wallet.DecryptMnemonic(w.Mnemonic, pin)
-	if err != nil || mnemonic == "" {
-		return ErrInvalidPIN
+	if _, err := s.decryptSecret(ctx, &w, pin); err != nil {
+		return err
 	}
 
 	if name == "" {
@@ -178,9 +617,10 @@ func (s *service) UpdateWalletName(ctx context.Context, uid, pin, name string) e
 	}
 
 	if _, err := s.repo.UpdateWallet(ctx, wallet_repository.UpdateWalletParams{
-		UserID:   uid,
-		Name:     name,
-		Mnemonic: w.Mnemonic,
+		ID:         w.ID,
+		Name:       name,
+		Mnemonic:   w.Mnemonic,
+		KeyVersion: w.KeyVersion,
 	}); err != nil {
 		return fmt.Errorf("failed to update wallet: %w", err)
 	}
@@ -189,7 +629,10 @@ func (s *service) UpdateWalletName(ctx context.Context, uid, pin, name string) e
 }
 
 // Change wallet pin
-func (s *service) ChangeWalletPin(ctx context.Context, uid string, pin string, newPin string) error {
+func (s *service) ChangeWalletPin(ctx context.Context, uid, walletID, pin, newPin string) (err error) {
+	a := newAuditRecord(uid, AuditActionChangePIN)
+	defer func() { s.writeAudit(ctx, a, err) }()
+
 	if pin == newPin {
 		return fmt.Errorf("new pin must be different from the old one")
 	}
@@ -197,66 +640,208 @@ func (s *service) ChangeWalletPin(ctx context.Context, uid string, pin string, n
 		return fmt.Errorf("new pin is required and must be at least 4 characters long")
 	}
 
-	w, err := s.repo.GetWallet(ctx, uid)
+	w, err := s.getWallet(ctx, uid, walletID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return nil
 		}
-		return fmt.Errorf("failed to get wallet: %w", err)
+		return err
 	}
+	a.walletID = w.ID
 
-	mnemonic, err := s.wallet.DecryptMnemonic(w.Mnemonic, pin)
-	if err != nil || mnemonic == "" {
-		return ErrInvalidPIN
+	secret, err := s.decryptSecret(ctx, &w, pin)
+	if err != nil {
+		return err
 	}
 
-	encrypted, err := s.wallet.EnctyptMnemonic(mnemonic, newPin)
+	plaintext, err := secret.Encode()
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := s.wallet.EnctyptMnemonic(ctx, plaintext, newPin, associatedData(w))
 	if err != nil {
 		return fmt.Errorf("failed to encrypt mnemonic: %w", err)
 	}
 
-	if _, err := s.repo.UpdateWallet(ctx, wallet_repository.UpdateWalletParams{
-		UserID:   uid,
-		Name:     w.Name,
-		Mnemonic: encrypted,
+	payload := events.PinChangedPayload{
+		UserID:     uid,
+		WalletID:   w.ID.String(),
+		OccurredAt: time.Now().UTC(),
+	}
+	if err := s.inTx(ctx, func(repo walletRepository, tx *sql.Tx) error {
+		if _, err := repo.UpdateWallet(ctx, wallet_repository.UpdateWalletParams{
+			ID:         w.ID,
+			Name:       w.Name,
+			Mnemonic:   encrypted,
+			KeyVersion: s.keyVersion(encrypted),
+		}); err != nil {
+			return fmt.Errorf("failed to update wallet: %w", err)
+		}
+
+		return s.emitTx(ctx, tx, events.PinChanged, payload)
 	}); err != nil {
-		return fmt.Errorf("failed to update wallet: %w", err)
+		return err
 	}
+	s.emit(events.PinChanged, payload)
 
 	return nil
 }
 
-// Export wallet
-func (s *service) ExportWallet(ctx context.Context, uid string, pin string) (Wallet, error) {
-	w, err := s.repo.GetWallet(ctx, uid)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return Wallet{}, ErrNotFound
+// Export wallet in the given format: plain (default), keypair or keystore.
+// Only the plain format exposes the mnemonic and the base58 private key.
+func (s *service) ExportWallet(ctx context.Context, uid, walletID, pin, format, password string) (_ Wallet, err error) {
+	a := newAuditRecord(uid, AuditActionExport)
+	if format == "" {
+		format = ExportFormatPlain
+	}
+	a.details["format"] = format
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	switch format {
+	case ExportFormatPlain, ExportFormatKeypair:
+	case ExportFormatKeystore:
+		if password == "" {
+			return Wallet{}, fmt.Errorf("%w: export password is required", ErrInvalidParameter)
 		}
-		return Wallet{}, fmt.Errorf("failed to get wallet: %w", err)
+	default:
+		return Wallet{}, fmt.Errorf("%w: unsupported export format %q", ErrInvalidParameter, format)
 	}
 
-	mnemonic, err := s.wallet.DecryptMnemonic(w.Mnemonic, pin)
-	if err != nil || mnemonic == "" {
-		return Wallet{}, ErrInvalidPIN
+	w, err := s.getWallet(ctx, uid, walletID)
+	if err != nil {
+		return Wallet{}, err
 	}
+	a.walletID = w.ID
 
-	acc, err := solanawallet.DeriveAccountFromMnemonicBip44(mnemonic)
+	secret, err := s.decryptSecret(ctx, &w, pin)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	acc, err := secret.DeriveAccount(0)
 	if err != nil {
 		return Wallet{}, fmt.Errorf("failed to derive account from mnemonic: %w", err)
 	}
 
-	return Wallet{
-		Name:       w.Name,
-		PublicKey:  acc.PublicKey.ToBase58(),
-		PrivateKey: utils.BytesToBase58(acc.PrivateKey),
-		Mnemonic:   mnemonic,
-	}, nil
+	result := Wallet{
+		ID:        w.ID.String(),
+		Name:      w.Name,
+		IsDefault: w.IsDefault,
+		PublicKey: acc.PublicKey.ToBase58(),
+	}
+
+	switch format {
+	case ExportFormatKeypair:
+		result.Keypair = solanawallet.AccountToKeygenJSON(acc)
+	case ExportFormatKeystore:
+		ks, err := solanawallet.EncryptKeystore(secret, password)
+		if err != nil {
+			return Wallet{}, fmt.Errorf("failed to encrypt keystore: %w", err)
+		}
+		result.Keystore = &ks
+	default:
+		result.PrivateKey = utils.BytesToBase58(acc.PrivateKey)
+		result.Mnemonic = secret.Mnemonic
+		result.Passphrase = secret.Passphrase
+	}
+
+	s.publish(ctx, events.ExportPerformed, events.ExportPerformedPayload{
+		UserID:     uid,
+		WalletID:   w.ID.String(),
+		Format:     format,
+		OccurredAt: time.Now().UTC(),
+	})
+
+	return result, nil
+}
+
+// Derive a new account with the given index from the wallet mnemonic.
+// If index is 0, the next free index is used.
+func (s *service) AddAccount(ctx context.Context, uid, walletID, pin string, index int, name string) (Account, error) {
+	if index < 0 {
+		return Account{}, ErrInvalidParameter
+	}
+
+	w, err := s.getWallet(ctx, uid, walletID)
+	if err != nil {
+		return Account{}, err
+	}
+
+	secret, err := s.decryptSecret(ctx, &w, pin)
+	if err != nil {
+		return Account{}, err
+	}
+	if !secret.HasMnemonic() {
+		return Account{}, ErrNoMnemonic
+	}
+
+	if index == 0 {
+		last, err := s.repo.GetLastWalletAccountIndex(ctx, w.ID)
+		if err != nil {
+			return Account{}, fmt.Errorf("failed to get last account index: %w", err)
+		}
+		index = int(last) + 1
+	} else if _, err := s.repo.GetWalletAccount(ctx, wallet_repository.GetWalletAccountParams{
+		WalletID:     w.ID,
+		AccountIndex: int32(index),
+	}); err == nil {
+		return Account{}, ErrAlreadyExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return Account{}, fmt.Errorf("failed to get wallet account: %w", err)
+	}
+
+	acc, err := secret.DeriveAccount(index)
+	if err != nil {
+		return Account{}, fmt.Errorf("failed to derive account from mnemonic: %w", err)
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("Account %d", index+1)
+	}
+
+	a, err := s.repo.CreateWalletAccount(ctx, wallet_repository.CreateWalletAccountParams{
+		WalletID:     w.ID,
+		AccountIndex: int32(index),
+		Name:         name,
+		PublicKey:    acc.PublicKey.ToBase58(),
+	})
+	if err != nil {
+		return Account{}, fmt.Errorf("failed to create wallet account: %w", err)
+	}
+
+	return castAccount(a), nil
+}
+
+// List all accounts derived from the wallet mnemonic.
+// The first one is always the primary wallet account with index 0.
+func (s *service) ListAccounts(ctx context.Context, uid, walletID string) ([]Account, error) {
+	w, err := s.getWallet(ctx, uid, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.repo.GetWalletAccounts(ctx, w.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet accounts: %w", err)
+	}
+
+	result := make([]Account, 0, len(accounts)+1)
+	result = append(result, Account{Index: 0, Name: w.Name, PublicKey: w.PublicKey})
+	for _, a := range accounts {
+		result = append(result, castAccount(a))
+	}
+
+	return result, nil
 }
 
 // Sign message and return signed message as base64 string
-func (s *service) SignMessage(ctx context.Context, uid string, pin string, base64Msg string) (msg, signature string, err error) {
-	acc, err := s.getAccount(ctx, uid, pin)
+func (s *service) SignMessage(ctx context.Context, uid, walletID string, accountIndex int, pin, base64Msg string) (msg, signature string, err error) {
+	a := newAuditRecord(uid, AuditActionSignMessage)
+	a.details["account_index"] = accountIndex
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	acc, err := s.getAccount(ctx, a, uid, walletID, accountIndex, pin)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to sign transaction: %w", err)
 	}
@@ -266,58 +851,546 @@ func (s *service) SignMessage(ctx context.Context, uid string, pin string, base6
 		return "", "", fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
-	return base64Msg, utils.BytesToBase64(acc.Sign(decodedMsg)), nil
+	signature = utils.BytesToBase64(acc.Sign(decodedMsg))
+
+	s.publish(ctx, events.MessageSigned, events.MessageSignedPayload{
+		UserID:       uid,
+		WalletID:     a.walletID.String(),
+		AccountIndex: accountIndex,
+		PublicKey:    acc.PublicKey.ToBase58(),
+		OccurredAt:   time.Now().UTC(),
+	})
+
+	return base64Msg, signature, nil
 }
 
 // Sign transaction and return signed transaction as base64 string
-func (s *service) SignTransaction(ctx context.Context, uid string, pin string, base64Tx string) (string, error) {
-	acc, err := s.getAccount(ctx, uid, pin)
-	if err != nil {
-		return "", fmt.Errorf("failed to sign transaction: %w", err)
-	}
+func (s *service) SignTransaction(ctx context.Context, uid, walletID string, accountIndex int, pin, base64Tx string) (_ string, err error) {
+	a := newAuditRecord(uid, AuditActionSignTransaction)
+	a.details["account_index"] = accountIndex
+	defer func() { s.writeAudit(ctx, a, err) }()
 
-	signedTx, err := s.solana.SignTransaction(ctx, acc, base64Tx)
+	signedTx, publicKey, err := s.signTransaction(ctx, a, uid, walletID, accountIndex, pin, base64Tx)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign transaction: %w", err)
+		return "", err
 	}
 
+	s.publish(ctx, events.TransactionSigned, events.TransactionSignedPayload{
+		UserID:       uid,
+		WalletID:     a.walletID.String(),
+		AccountIndex: accountIndex,
+		PublicKey:    publicKey,
+		OccurredAt:   time.Now().UTC(),
+	})
+
 	return signedTx, nil
 }
 
-// Sign and send transaction, return transaction signature
-func (s *service) SignAndSendTransaction(ctx context.Context, uid string, pin string, base64Tx string) (string, error) {
-	signedTx, err := s.SignTransaction(ctx, uid, pin, base64Tx)
+// Sign and send transaction, return transaction signature.
+// The signed transaction is simulated before sending unless disabled,
+// a failed simulation refuses sending unless the caller ignores the simulation error.
+// Only the TransactionSent event is fired, it implies the transaction has been signed.
+func (s *service) SignAndSendTransaction(ctx context.Context, uid, walletID string, accountIndex int, pin, base64Tx string, opts SendOptions) (_ string, _ *solanatx.Simulation, err error) {
+	a := newAuditRecord(uid, AuditActionSendTransaction)
+	a.details["account_index"] = accountIndex
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	return s.signAndSendTransaction(ctx, a, uid, walletID, accountIndex, pin, base64Tx, opts)
+}
+
+// sign, simulate and send the transaction, then record it for the confirmation tracking.
+// Returns the transaction signature and the simulation result, if the transaction has been simulated.
+func (s *service) signAndSendTransaction(ctx context.Context, a *auditRecord, uid, walletID string, accountIndex int, pin, base64Tx string, opts SendOptions) (string, *solanatx.Simulation, error) {
+	signedTx, publicKey, err := s.signTransaction(ctx, a, uid, walletID, accountIndex, pin, base64Tx)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	sim, err := s.simulateBeforeSend(ctx, a, publicKey, signedTx, opts)
+	if err != nil {
+		return "", nil, err
 	}
 
 	txSignature, err := s.solana.SendTransaction(ctx, signedTx, 2)
 	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %w", err)
+		return "", nil, fmt.Errorf("failed to send transaction: %w", err)
 	}
+	a.details["signature"] = txSignature
+
+	payload := events.TransactionSentPayload{
+		UserID:       uid,
+		WalletID:     a.walletID.String(),
+		AccountIndex: accountIndex,
+		PublicKey:    publicKey,
+		Signature:    txSignature,
+		OccurredAt:   time.Now().UTC(),
+	}
+	if err := s.inTx(ctx, func(repo walletRepository, tx *sql.Tx) error {
+		if err := recordTransaction(ctx, repo, a, accountIndex, txSignature, signedTx); err != nil {
+			return err
+		}
+		return s.emitTx(ctx, tx, events.TransactionSent, payload)
+	}); err != nil && s.log != nil {
+		// the transaction is sent already, the request must not fail
+		s.log.Log(
+			"msg", "failed to record sent wallet transaction",
+			"err", err,
+			"wallet_id", a.walletID.String(),
+			"signature", txSignature,
+		)
+	}
+	s.emit(events.TransactionSent, payload)
+
+	return txSignature, sim, nil
+}
 
-	return txSignature, nil
+// Decode the transaction to show what will be signed, before the PIN is entered.
+// Token transfer amounts are resolved with the mint decimals;
+// if a mint can't be resolved, the amount is left in base units.
+func (s *service) PreviewTransaction(ctx context.Context, base64Tx string) (solanatx.Transaction, error) {
+	return s.decodeTransaction(ctx, base64Tx)
 }
 
-// get decoded wallet account
-func (s *service) getAccount(ctx context.Context, uid string, pin string) (types.Account, error) {
-	w, err := s.repo.GetWallet(ctx, uid)
+// List audit log entries of the sensitive wallet operations, newest first.
+// Empty uid lists the entries of all users, empty walletID - of all user's wallets.
+func (s *service) ListAuditLog(ctx context.Context, uid, walletID string, limit, offset int) ([]AuditLogEntry, error) {
+	if limit <= 0 {
+		limit = DefaultAuditLogLimit
+	}
+	if limit > MaxAuditLogLimit || offset < 0 {
+		return nil, ErrInvalidParameter
+	}
+
+	params := wallet_repository.GetAuditLogParams{
+		UserID: uid,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	}
+	if walletID != "" {
+		id, err := uuid.Parse(walletID)
+		if err != nil {
+			return nil, ErrInvalidParameter
+		}
+		params.WalletID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	entries, err := s.repo.GetAuditLog(ctx, params)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return types.Account{}, ErrNotFound
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+
+	result := make([]AuditLogEntry, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, castAuditLogEntry(e))
+	}
+
+	return result, nil
+}
+
+// decode the transaction and resolve the mints and decimals of the token transfers.
+// The accounts of the v0 transactions loaded from the address lookup tables are resolved as well,
+// so the signing policy checks the actual destinations.
+// Transfers which can't be resolved are left with the amount in base units.
+func (s *service) decodeTransaction(ctx context.Context, base64Tx string) (solanatx.Transaction, error) {
+	tx, err := solanatx.Decode(base64Tx)
+	if err != nil {
+		return solanatx.Transaction{}, fmt.Errorf("%w: %s", ErrInvalidTransaction, err)
+	}
+	if len(tx.AddressTableLookups) > 0 {
+		tables, err := s.lookupTables(ctx, tx.AddressTableLookups)
+		if err != nil {
+			return solanatx.Transaction{}, err
+		}
+		if tx, err = solanatx.DecodeWithLookupTables(base64Tx, tables); err != nil {
+			return solanatx.Transaction{}, fmt.Errorf("%w: %s", ErrInvalidTransaction, err)
+		}
+	}
+
+	for i := range tx.Instructions {
+		ins := &tx.Instructions[i]
+		if ins.Transfer == nil || ins.Transfer.IsNative {
+			continue
+		}
+
+		if ins.Transfer.Mint == "" {
+			mint, err := s.solana.GetTokenAccountMint(ctx, ins.Transfer.Source)
+			if err != nil {
+				continue // leave the amount in base units
+			}
+			ins.Transfer.Mint = mint
 		}
-		return types.Account{}, fmt.Errorf("failed to get wallet: %w", err)
+
+		decimals := ins.Transfer.Amount.Decimals
+		if !ins.Transfer.Resolved() {
+			if decimals, err = s.solana.GetMintDecimals(ctx, ins.Transfer.Mint); err != nil {
+				continue // leave the amount in base units
+			}
+		}
+
+		var symbol string
+		if md, err := s.solana.GetTokenMetadata(ctx, ins.Transfer.Mint); err == nil && md.Data != nil {
+			symbol = md.Data.Symbol
+		}
+
+		ins.SetDecimals(decimals, symbol)
+	}
+
+	return tx, nil
+}
+
+// load the address lookup tables referenced by the v0 transaction.
+// Returns ErrInvalidTransaction if a table doesn't exist, the transaction can't be executed then.
+func (s *service) lookupTables(ctx context.Context, lookups []solanatx.AddressTableLookup) (solanatx.LookupTables, error) {
+	if len(lookups) == 0 {
+		return nil, nil
 	}
 
-	mnemonic, err := s.wallet.DecryptMnemonic(w.Mnemonic, pin)
-	if err != nil || mnemonic == "" {
-		return types.Account{}, ErrInvalidPIN
+	addresses := make([]string, 0, len(lookups))
+	for _, l := range lookups {
+		addresses = append(addresses, l.Account)
+	}
+	states, err := s.getAccountStates(ctx, addresses...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get address lookup tables: %w", err)
 	}
 
-	acc, err := solanawallet.DeriveAccountFromMnemonicBip44(mnemonic)
+	tables := make(solanatx.LookupTables, len(lookups))
+	for i, addr := range addresses {
+		table, ok := solanatx.ParseLookupTable(states[i])
+		if !ok {
+			return nil, fmt.Errorf("%w: address lookup table %s not found", ErrInvalidTransaction, addr)
+		}
+		tables[addr] = table
+	}
+
+	return tables, nil
+}
+
+// sign transaction with the decoded wallet account,
+// returns the signed transaction and the signer public key
+func (s *service) signTransaction(ctx context.Context, audit *auditRecord, uid, walletID string, accountIndex int, pin, base64Tx string) (string, string, error) {
+	acc, err := s.getAccount(ctx, audit, uid, walletID, accountIndex, pin)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	if err := s.enforceSigningPolicy(ctx, audit, acc.PublicKey.ToBase58(), base64Tx); err != nil {
+		return "", "", err
+	}
+
+	signedTx, err := s.solana.SignTransaction(ctx, acc, base64Tx)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	return signedTx, acc.PublicKey.ToBase58(), nil
+}
+
+// get decoded wallet account with the given index
+func (s *service) getAccount(ctx context.Context, audit *auditRecord, uid, walletID string, accountIndex int, pin string) (types.Account, error) {
+	if accountIndex < 0 {
+		return types.Account{}, ErrInvalidParameter
+	}
+
+	w, err := s.getWallet(ctx, uid, walletID)
+	if err != nil {
+		return types.Account{}, err
+	}
+	audit.walletID = w.ID
+
+	publicKey, err := s.accountPublicKey(ctx, w, accountIndex)
+	if err != nil {
+		return types.Account{}, err
+	}
+
+	secret, err := s.decryptSecret(ctx, &w, pin)
+	if err != nil {
+		return types.Account{}, err
+	}
+
+	acc, err := secret.DeriveAccount(accountIndex)
 	if err != nil {
 		return types.Account{}, fmt.Errorf("failed to derive account from mnemonic: %w", err)
 	}
+	if acc.PublicKey.ToBase58() != publicKey {
+		return types.Account{}, fmt.Errorf("derived account does not match the stored public key")
+	}
 
 	return acc, nil
 }
+
+// get the public key of the wallet account with the given index.
+// Only the primary account and the explicitly added ones can be used.
+func (s *service) accountPublicKey(ctx context.Context, w wallet_repository.Wallet, accountIndex int) (string, error) {
+	if accountIndex == 0 {
+		return w.PublicKey, nil
+	}
+
+	a, err := s.repo.GetWalletAccount(ctx, wallet_repository.GetWalletAccountParams{
+		WalletID:     w.ID,
+		AccountIndex: int32(accountIndex),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to get wallet account: %w", err)
+	}
+
+	return a.PublicKey, nil
+}
+
+// get wallet by user id and wallet id,
+// falls back to the default user's wallet if wallet id is empty
+func (s *service) getWallet(ctx context.Context, uid, walletID string) (wallet_repository.Wallet, error) {
+	var (
+		w   wallet_repository.Wallet
+		err error
+	)
+
+	if walletID == "" {
+		w, err = s.repo.GetDefaultWallet(ctx, uid)
+	} else {
+		id, perr := uuid.Parse(walletID)
+		if perr != nil {
+			return wallet_repository.Wallet{}, ErrInvalidParameter
+		}
+		w, err = s.repo.GetWallet(ctx, wallet_repository.GetWalletParams{
+			ID:     id,
+			UserID: uid,
+		})
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return wallet_repository.Wallet{}, ErrNotFound
+		}
+		return wallet_repository.Wallet{}, fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	return w, nil
+}
+
+// decrypt wallet secret (mnemonic and optional passphrase) with the given pin.
+// Secrets encrypted with the legacy or outdated key derivation params, or not bound
+// to the wallet record with associated data, are transparently re-encrypted,
+// so w is updated in place.
+// Failed attempts are counted per wallet and lock it according to the lockout policy,
+// only authentication failures count, key provider and format errors are returned as is.
+// The lockout check, the decryption and the counter update run under the wallet row lock,
+// so concurrent requests can't get more guesses than the lockout policy allows.
+func (s *service) decryptSecret(ctx context.Context, w *wallet_repository.Wallet, pin string) (solanawallet.Secret, error) {
+	var (
+		secret  solanawallet.Secret
+		failure error // the failed attempt is committed, then reported to the caller
+	)
+	if err := s.inTx(ctx, func(repo walletRepository, _ *sql.Tx) error {
+		locked, err := repo.GetWalletForUpdate(ctx, w.ID)
+		if err != nil {
+			return fmt.Errorf("failed to lock wallet: %w", err)
+		}
+		*w = locked
+
+		if w.IsLocked {
+			return ErrWalletLocked
+		}
+		if w.LockedUntil.Valid && time.Now().UTC().Before(w.LockedUntil.Time) {
+			return fmt.Errorf("%w: try again after %s", ErrTooManyAttempts, w.LockedUntil.Time.Format(time.RFC3339))
+		}
+
+		plaintext, err := s.wallet.DecryptMnemonic(ctx, w.Mnemonic, pin, associatedData(*w))
+		if err != nil {
+			if errors.Is(err, solanawallet.ErrAuthenticationFailed) {
+				failure, err = s.registerFailedPINAttempt(ctx, repo, *w)
+				return err
+			}
+			return fmt.Errorf("failed to decrypt wallet secret: %w", err)
+		}
+		if plaintext == "" {
+			return errors.New("decrypted wallet secret is empty")
+		}
+
+		if secret, err = solanawallet.DecodeSecret(plaintext); err != nil {
+			return err
+		}
+
+		if w.FailedPinAttempts > 0 || w.LockedUntil.Valid {
+			if err := repo.ResetFailedPINAttempts(ctx, w.ID); err != nil {
+				return fmt.Errorf("failed to reset failed pin attempts: %w", err)
+			}
+		}
+
+		if s.wallet.NeedsReencryption(w.Mnemonic) {
+			encrypted, err := s.wallet.EnctyptMnemonic(ctx, plaintext, pin, associatedData(*w))
+			if err != nil {
+				return fmt.Errorf("failed to re-encrypt mnemonic: %w", err)
+			}
+
+			updated, err := repo.UpdateWallet(ctx, wallet_repository.UpdateWalletParams{
+				ID:         w.ID,
+				Name:       w.Name,
+				Mnemonic:   encrypted,
+				KeyVersion: s.keyVersion(encrypted),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update wallet: %w", err)
+			}
+			*w = updated
+		}
+
+		return nil
+	}); err != nil {
+		return solanawallet.Secret{}, err
+	}
+	if failure != nil {
+		return solanawallet.Secret{}, failure
+	}
+
+	return secret, nil
+}
+
+// registerFailedPINAttempt increments the failed attempts counter of the wallet locked for update
+// and locks it temporarily or permanently according to the lockout policy.
+// Returns the failure to be reported to the caller, or the error if the attempt can't be stored.
+func (s *service) registerFailedPINAttempt(ctx context.Context, repo walletRepository, w wallet_repository.Wallet) (failure, err error) {
+	failed, err := repo.IncrementFailedPINAttempts(ctx, w.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register failed pin attempt: %w", err)
+	}
+
+	if int(failed) >= s.lockout.MaxAttempts {
+		if err := repo.LockWallet(ctx, wallet_repository.LockWalletParams{
+			ID:       w.ID,
+			IsLocked: true,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to lock wallet: %w", err)
+		}
+		return ErrWalletLocked, nil
+	}
+
+	if delay := s.lockout.lockDuration(int(failed)); delay > 0 {
+		lockedUntil := time.Now().UTC().Add(delay)
+		if err := repo.LockWallet(ctx, wallet_repository.LockWalletParams{
+			ID:          w.ID,
+			LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true},
+		}); err != nil {
+			return nil, fmt.Errorf("failed to lock wallet: %w", err)
+		}
+		return fmt.Errorf("%w: try again after %s", ErrTooManyAttempts, lockedUntil.Format(time.RFC3339)), nil
+	}
+
+	return ErrInvalidPIN, nil
+}
+
+// inTx runs fn in a database transaction with the repository bound to it.
+// The transaction is committed if fn returns nil and rolled back otherwise.
+// Without the database set by WithDB, fn runs in the transaction of the repository
+// if it manages them itself, or on the service repository outside of a transaction.
+func (s *service) inTx(ctx context.Context, fn func(repo walletRepository, tx *sql.Tx) error) error {
+	if s.db == nil {
+		if r, ok := s.repo.(txRepository); ok {
+			return r.InTx(ctx, func(repo walletRepository) error { return fn(repo, nil) })
+		}
+		return fn(s.repo, nil)
+	}
+
+	q, ok := s.repo.(*wallet_repository.Queries)
+	if !ok {
+		return errors.New("repository does not support transactions")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	if err := fn(q.WithTx(tx), tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// emit fires the event if the emitter is set.
+// The transactional listeners are called by emitTx within the change transaction,
+// emit is called after the commit.
+func (s *service) emit(name events.EventName, payload interface{}) {
+	if s.events == nil {
+		return
+	}
+	s.events.Emit(name, payload)
+}
+
+// emitTx calls the transactional listeners of the event within the transaction of the wallet change,
+// e.g. the webhooks outbox, so the event is stored together with the change
+func (s *service) emitTx(ctx context.Context, tx *sql.Tx, name events.EventName, payload interface{}) error {
+	if s.events == nil {
+		return nil
+	}
+	if err := s.events.EmitTx(ctx, tx, name, payload); err != nil {
+		return fmt.Errorf("failed to emit %s event: %w", name, err)
+	}
+	return nil
+}
+
+// publish fires the event of the operation which doesn't change the wallet records,
+// e.g. the export or signing. The operation is done already, so the failures are logged only.
+func (s *service) publish(ctx context.Context, name events.EventName, payload interface{}) {
+	if err := s.emitTx(ctx, nil, name, payload); err != nil && s.log != nil {
+		s.log.Log("msg", "failed to emit event", "err", err, "event", string(name))
+	}
+	s.emit(name, payload)
+}
+
+// keyVersion returns the id of the key encryption key wrapping the mnemonic data key,
+// to be stored alongside the encrypted mnemonic
+func (s *service) keyVersion(encrypted string) sql.NullString {
+	v := s.wallet.KeyVersion(encrypted)
+	return sql.NullString{String: v, Valid: v != ""}
+}
+
+// associated data binds the encrypted mnemonic to the wallet record
+func associatedData(w wallet_repository.Wallet) solanawallet.AssociatedData {
+	return solanawallet.AssociatedData{
+		UserID:    w.UserID,
+		WalletID:  w.ID.String(),
+		PublicKey: w.PublicKey,
+	}
+}
+
+// cast repository wallet model to the public wallet representation
+func castWallet(w wallet_repository.Wallet) Wallet {
+	return Wallet{
+		ID:        w.ID.String(),
+		Name:      w.Name,
+		IsDefault: w.IsDefault,
+		PublicKey: w.PublicKey,
+	}
+}
+
+// deletionExpired reports whether the grace period of the wallet pending deletion is over
+func (s *service) deletionExpired(w wallet_repository.Wallet) bool {
+	return w.DeletedAt.Valid && !time.Now().UTC().Before(w.DeletedAt.Time.Add(s.deletionGracePeriod))
+}
+
+// cast repository wallet model pending deletion to the public wallet representation
+func (s *service) castDeletedWallet(w wallet_repository.Wallet) Wallet {
+	result := castWallet(w)
+	if w.DeletedAt.Valid {
+		result.DeletedAt = utils.Pointer(w.DeletedAt.Time)
+		result.PurgeAt = utils.Pointer(w.DeletedAt.Time.Add(s.deletionGracePeriod))
+	}
+	return result
+}
+
+// cast repository wallet account model to the public account representation
+func castAccount(a wallet_repository.WalletAccount) Account {
+	return Account{
+		Index:     int(a.AccountIndex),
+		Name:      a.Name,
+		PublicKey: a.PublicKey,
+	}
+}
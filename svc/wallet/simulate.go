@@ -0,0 +1,118 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+)
+
+// SimulationError is returned when the transaction simulation fails before sending,
+// Simulation contains the program logs and the transaction error.
+type SimulationError struct {
+	Simulation solanatx.Simulation
+}
+
+// Error implements the error interface
+func (e *SimulationError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrSimulationFailed, e.Simulation.Err)
+}
+
+// Unwrap returns ErrSimulationFailed, so the error can be checked with errors.Is
+func (e *SimulationError) Unwrap() error {
+	return ErrSimulationFailed
+}
+
+// Simulate the transaction on behalf of the wallet account without signing it.
+// Returns the program logs, consumed compute units, the transaction error if any,
+// and the SOL and token balance changes of the account.
+func (s *service) SimulateTransaction(ctx context.Context, uid, walletID string, accountIndex int, base64Tx string) (solanatx.Simulation, error) {
+	if accountIndex < 0 {
+		return solanatx.Simulation{}, ErrInvalidParameter
+	}
+
+	w, err := s.getWallet(ctx, uid, walletID)
+	if err != nil {
+		return solanatx.Simulation{}, err
+	}
+
+	publicKey, err := s.accountPublicKey(ctx, w, accountIndex)
+	if err != nil {
+		return solanatx.Simulation{}, err
+	}
+
+	return s.simulateTransaction(ctx, publicKey, base64Tx)
+}
+
+// simulate the transaction and collect the balance changes of the given account.
+// Only the writable accounts of the transaction are checked for the balance changes,
+// including the ones loaded from the address lookup tables of the v0 transactions.
+func (s *service) simulateTransaction(ctx context.Context, publicKey, base64Tx string) (solanatx.Simulation, error) {
+	tx, err := solanatx.Decode(base64Tx)
+	if err != nil {
+		return solanatx.Simulation{}, fmt.Errorf("%w: %s", ErrInvalidTransaction, err)
+	}
+	tables, err := s.lookupTables(ctx, tx.AddressTableLookups)
+	if err != nil {
+		return solanatx.Simulation{}, err
+	}
+
+	addresses, err := solanatx.WritableAccounts(base64Tx, tables)
+	if err != nil {
+		return solanatx.Simulation{}, fmt.Errorf("%w: %s", ErrInvalidTransaction, err)
+	}
+
+	pre, err := s.solana.GetAccountStates(ctx, addresses)
+	if err != nil {
+		return solanatx.Simulation{}, fmt.Errorf("failed to simulate transaction: %w", err)
+	}
+
+	sim, post, err := s.solana.SimulateTransaction(ctx, base64Tx, addresses)
+	if err != nil {
+		return solanatx.Simulation{}, err
+	}
+
+	sim.BalanceChanges = solanatx.BalanceChanges(publicKey, addresses, pre, post)
+	for i := range sim.BalanceChanges {
+		change := &sim.BalanceChanges[i]
+		if change.IsNative {
+			continue
+		}
+		if decimals, err := s.solana.GetMintDecimals(ctx, change.Mint); err == nil {
+			change.SetDecimals(decimals) // leave the amounts in base units otherwise
+		}
+	}
+
+	return sim, nil
+}
+
+// simulate the signed transaction before sending it.
+// Returns SimulationError if the transaction fails, unless the error is ignored.
+func (s *service) simulateBeforeSend(ctx context.Context, audit *auditRecord, publicKey, signedTx string, opts SendOptions) (*solanatx.Simulation, error) {
+	simulate := s.simulateBeforeSending
+	if opts.Simulate != nil {
+		simulate = *opts.Simulate
+	}
+	if !simulate {
+		return nil, nil
+	}
+
+	sim, err := s.simulateTransaction(ctx, publicKey, signedTx)
+	if err != nil {
+		if !opts.IgnoreSimulationError {
+			return nil, err
+		}
+		audit.details["simulation"] = "ignored"
+		return nil, nil
+	}
+	audit.details["units_consumed"] = sim.UnitsConsumed
+
+	if !sim.Success {
+		if !opts.IgnoreSimulationError {
+			return nil, &SimulationError{Simulation: sim}
+		}
+		audit.details["simulation"] = "ignored"
+	}
+
+	return &sim, nil
+}
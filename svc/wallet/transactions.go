@@ -0,0 +1,303 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+	wallet_repository "github.com/dmitrymomot/solana-wallets/svc/wallet/repository"
+	"github.com/google/uuid"
+	"github.com/portto/solana-go-sdk/rpc"
+)
+
+// Sent transaction statuses.
+// Pending, processed and confirmed transactions are polled by the confirmation watcher,
+// finalized, failed and expired ones are settled.
+const (
+	TransactionStatusPending   = "pending"   // sent, but not seen by the cluster yet
+	TransactionStatusProcessed = "processed" // included in a block
+	TransactionStatusConfirmed = "confirmed" // voted by the supermajority of the cluster
+	TransactionStatusFinalized = "finalized" // the block is rooted
+	TransactionStatusFailed    = "failed"    // included in a block, but the execution failed
+	TransactionStatusExpired   = "expired"   // the recent blockhash expired before the transaction was included
+)
+
+// DefaultTransactionWatchBatchSize is the number of transactions checked by the watcher at once,
+// if no custom size is provided
+const DefaultTransactionWatchBatchSize = 100
+
+// Max number of signatures accepted by the getSignatureStatuses RPC method
+const maxSignatureStatuses = 256
+
+// Transactions page size limits
+const (
+	DefaultTransactionsLimit = 50
+	MaxTransactionsLimit     = 100
+)
+
+// List the transactions sent from the user's wallets, newest first.
+// Empty walletID lists the transactions of all user's wallets, empty status - in any status.
+func (s *service) ListTransactions(ctx context.Context, uid, walletID, status string, limit, offset int) ([]Transaction, error) {
+	if limit <= 0 {
+		limit = DefaultTransactionsLimit
+	}
+	if limit > MaxTransactionsLimit || offset < 0 {
+		return nil, ErrInvalidParameter
+	}
+	if status != "" && !isTransactionStatus(status) {
+		return nil, ErrInvalidParameter
+	}
+
+	params := wallet_repository.GetWalletTransactionsParams{
+		UserID: uid,
+		Status: status,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	}
+	if walletID != "" {
+		id, err := uuid.Parse(walletID)
+		if err != nil {
+			return nil, ErrInvalidParameter
+		}
+		params.WalletID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	txs, err := s.repo.GetWalletTransactions(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet transactions: %w", err)
+	}
+
+	result := make([]Transaction, 0, len(txs))
+	for _, tx := range txs {
+		result = append(result, castTransaction(tx))
+	}
+
+	return result, nil
+}
+
+// Get the transaction sent from the user's wallet by its signature
+func (s *service) GetTransaction(ctx context.Context, uid, signature string) (Transaction, error) {
+	tx, err := s.repo.GetWalletTransaction(ctx, wallet_repository.GetWalletTransactionParams{
+		UserID:    uid,
+		Signature: signature,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Transaction{}, ErrNotFound
+		}
+		return Transaction{}, fmt.Errorf("failed to get wallet transaction: %w", err)
+	}
+
+	return castTransaction(tx), nil
+}
+
+// Update the statuses of the sent transactions which are not settled yet,
+// returns the number of updated transactions.
+// Pending and processed transactions which are unknown to the cluster
+// and whose recent blockhash has expired are marked as expired.
+// Durable transactions don't expire with the blockhash, they are expired
+// once their nonce account is advanced by another transaction or closed.
+func (s *service) UpdateTransactionStatuses(ctx context.Context) (int, error) {
+	txs, err := s.repo.GetUnsettledWalletTransactions(ctx, int32(s.txWatchBatchSize))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get unsettled transactions: %w", err)
+	}
+	if len(txs) == 0 {
+		return 0, nil
+	}
+
+	// The blockhashes and nonces are checked before the statuses: if a blockhash has already expired
+	// and the transaction is still unknown afterwards, it can't be included anymore.
+	expired, err := s.expiredBlockhashes(ctx, txs)
+	if err != nil {
+		return 0, err
+	}
+
+	signatures := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		signatures = append(signatures, tx.Signature)
+	}
+	statuses, err := s.solana.GetSignatureStatuses(ctx, signatures)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get transaction statuses: %w", err)
+	}
+
+	var updated int
+	for i, tx := range txs {
+		var status *rpc.SignatureStatus
+		if i < len(statuses) {
+			status = statuses[i]
+		}
+
+		params, changed := transactionStatusUpdate(tx, status, expired[expiryKey(tx)])
+		if !changed {
+			continue
+		}
+		if err := s.repo.UpdateWalletTransactionStatus(ctx, params); err != nil {
+			return updated, fmt.Errorf("failed to update transaction status: %w", err)
+		}
+		updated++
+	}
+
+	return updated, nil
+}
+
+// expiredBlockhashes checks whether the blockhashes of the unsettled transactions have expired.
+// Returns the expiration flags by the expiry key of the transaction.
+// The nonce of the durable transaction expires once the nonce account holds another nonce or is closed.
+func (s *service) expiredBlockhashes(ctx context.Context, txs []wallet_repository.WalletTransaction) (map[string]bool, error) {
+	expired := make(map[string]bool)
+	var nonceAccounts []string
+	for _, tx := range txs {
+		if tx.Status == TransactionStatusConfirmed {
+			continue // confirmed transactions are not rolled back
+		}
+		if _, ok := expired[expiryKey(tx)]; ok {
+			continue
+		}
+		if tx.NonceAccount.Valid {
+			expired[expiryKey(tx)] = false
+			nonceAccounts = append(nonceAccounts, tx.NonceAccount.String)
+			continue
+		}
+		valid, err := s.solana.IsBlockhashValid(ctx, tx.RecentBlockhash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check transaction blockhash: %w", err)
+		}
+		expired[expiryKey(tx)] = !valid
+	}
+
+	if len(nonceAccounts) == 0 {
+		return expired, nil
+	}
+
+	states, err := s.getAccountStates(ctx, nonceAccounts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check transaction nonce: %w", err)
+	}
+	nonces := make(map[string]string, len(nonceAccounts))
+	for i, addr := range nonceAccounts {
+		if acc, ok := solanatx.ParseNonceAccount(states[i]); ok {
+			nonces[addr] = acc.Nonce
+		}
+	}
+	for _, tx := range txs {
+		if tx.NonceAccount.Valid && tx.Status != TransactionStatusConfirmed {
+			expired[expiryKey(tx)] = nonces[tx.NonceAccount.String] != tx.RecentBlockhash
+		}
+	}
+
+	return expired, nil
+}
+
+// expiryKey returns the key the transaction expires by:
+// the recent blockhash, or the nonce account and the nonce for the durable transactions
+func expiryKey(tx wallet_repository.WalletTransaction) string {
+	if tx.NonceAccount.Valid {
+		return tx.NonceAccount.String + ":" + tx.RecentBlockhash
+	}
+	return tx.RecentBlockhash
+}
+
+// record the sent transaction to be tracked by the confirmation watcher
+func recordTransaction(ctx context.Context, repo walletRepository, audit *auditRecord, accountIndex int, signature, signedTx string) error {
+	tx, err := solanatx.Decode(signedTx)
+	if err != nil {
+		return err
+	}
+
+	nonceAccount, durable := tx.DurableNonceAccount()
+	if _, err := repo.CreateWalletTransaction(ctx, wallet_repository.CreateWalletTransactionParams{
+		UserID:          audit.userID,
+		WalletID:        uuid.NullUUID{UUID: audit.walletID, Valid: true},
+		AccountIndex:    int32(accountIndex),
+		Signature:       signature,
+		RecentBlockhash: tx.RecentBlockhash,
+		NonceAccount:    sql.NullString{String: nonceAccount, Valid: durable},
+	}); err != nil {
+		return fmt.Errorf("failed to store wallet transaction: %w", err)
+	}
+
+	return nil
+}
+
+// transactionStatusUpdate returns the transaction status update params
+// and whether the status has changed
+func transactionStatusUpdate(tx wallet_repository.WalletTransaction, status *rpc.SignatureStatus, blockhashExpired bool) (wallet_repository.UpdateWalletTransactionStatusParams, bool) {
+	params := wallet_repository.UpdateWalletTransactionStatusParams{
+		ID:     tx.ID,
+		Status: tx.Status,
+		Error:  tx.Error,
+		Slot:   tx.Slot,
+	}
+
+	switch {
+	case status == nil:
+		// a processed transaction may be unknown if its block was skipped
+		if tx.Status == TransactionStatusConfirmed || !blockhashExpired {
+			return params, false
+		}
+		params.Status = TransactionStatusExpired
+	case status.Err != nil:
+		params.Status = TransactionStatusFailed
+		params.Slot = sql.NullInt64{Int64: int64(status.Slot), Valid: true}
+		if details, err := json.Marshal(status.Err); err == nil {
+			params.Error = nullString(string(details))
+		}
+	default:
+		params.Status = TransactionStatusProcessed
+		if status.ConfirmationStatus != nil {
+			switch *status.ConfirmationStatus {
+			case rpc.CommitmentConfirmed:
+				params.Status = TransactionStatusConfirmed
+			case rpc.CommitmentFinalized:
+				params.Status = TransactionStatusFinalized
+			}
+		}
+		params.Slot = sql.NullInt64{Int64: int64(status.Slot), Valid: true}
+	}
+
+	return params, params.Status != tx.Status || params.Slot != tx.Slot
+}
+
+// isTransactionStatus reports whether the status is one of the known transaction statuses
+func isTransactionStatus(status string) bool {
+	switch status {
+	case TransactionStatusPending,
+		TransactionStatusProcessed,
+		TransactionStatusConfirmed,
+		TransactionStatusFinalized,
+		TransactionStatusFailed,
+		TransactionStatusExpired:
+		return true
+	}
+	return false
+}
+
+// cast repository transaction model to the public transaction representation
+func castTransaction(tx wallet_repository.WalletTransaction) Transaction {
+	result := Transaction{
+		ID:           tx.ID.String(),
+		AccountIndex: int(tx.AccountIndex),
+		Signature:    tx.Signature,
+		Status:       tx.Status,
+		Error:        json.RawMessage(tx.Error.String),
+		CreatedAt:    tx.CreatedAt,
+	}
+	if tx.WalletID.Valid {
+		result.WalletID = tx.WalletID.UUID.String()
+	}
+	if !tx.Error.Valid {
+		result.Error = nil
+	}
+	if tx.Slot.Valid {
+		result.Slot = uint64(tx.Slot.Int64)
+	}
+	if tx.UpdatedAt.Valid {
+		result.UpdatedAt = &tx.UpdatedAt.Time
+	}
+	return result
+}
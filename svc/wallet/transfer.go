@@ -0,0 +1,422 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dmitrymomot/solana-wallets/internal/signingpolicy"
+	"github.com/dmitrymomot/solana-wallets/internal/solanatx"
+	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+	"github.com/dmitrymomot/solana-wallets/internal/utils"
+	"github.com/dmitrymomot/solana/client"
+	solanatypes "github.com/dmitrymomot/solana/types"
+	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/associated_token_account"
+	"github.com/portto/solana-go-sdk/program/memo"
+	"github.com/portto/solana-go-sdk/program/system"
+	"github.com/portto/solana-go-sdk/program/token"
+	"github.com/portto/solana-go-sdk/types"
+)
+
+// MaxMemoLength is the max length of the transfer memo in bytes
+const MaxMemoLength = 256
+
+// Transfer SOL from the wallet account to the destination address.
+// The amount is a decimal string in SOL, e.g. "1.5".
+// The transaction is built with a fresh blockhash, checked against the account balance,
+// then signed, simulated and sent as any other transaction sent from the wallet.
+// Returns the transaction signature and the resolved compute budget.
+func (s *service) TransferSOL(ctx context.Context, uid, walletID string, accountIndex int, pin, destination, amount, memoText string, opts TransferOptions) (_ string, _ ComputeBudget, err error) {
+	a := newAuditRecord(uid, AuditActionTransfer)
+	a.details["account_index"] = accountIndex
+	a.details["mint"] = signingpolicy.NativeMint
+	a.details["destination"] = destination
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	if accountIndex < 0 || len(memoText) > MaxMemoLength {
+		return "", ComputeBudget{}, ErrInvalidParameter
+	}
+
+	lamports, err := utils.ParseAmount(amount, solanatypes.SPLTokenDefaultDecimals)
+	if err != nil {
+		return "", ComputeBudget{}, fmt.Errorf("%w: %s", ErrInvalidAmount, err)
+	}
+	a.details["amount"] = lamports
+
+	w, err := s.getWallet(ctx, uid, walletID)
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+	a.walletID = w.ID
+
+	source, err := s.accountPublicKey(ctx, w, accountIndex)
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+	if err := validateDestination(source, destination); err != nil {
+		return "", ComputeBudget{}, err
+	}
+
+	from := common.PublicKeyFromString(source)
+	instructions := []types.Instruction{
+		system.Transfer(system.TransferParam{
+			From:   from,
+			To:     common.PublicKeyFromString(destination),
+			Amount: lamports,
+		}),
+	}
+
+	tx, budget, err := s.buildTransfer(ctx, a, from, instructions, memoText, opts)
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+
+	if err := s.checkSOLTransfer(ctx, source, destination, lamports, tx); err != nil {
+		return "", ComputeBudget{}, err
+	}
+
+	txSignature, _, err := s.signAndSendTransaction(ctx, a, uid, walletID, accountIndex, pin, tx, SendOptions{})
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+
+	return txSignature, budget, nil
+}
+
+// Transfer SPL tokens from the wallet account to the destination wallet.
+// The amount is a decimal string in the token units, e.g. "1.5", the decimals are resolved from the mint.
+// The destination is the owner wallet address, its associated token account is created
+// in the same transaction if it does not exist yet, paid by the source account.
+// Both SPL Token and Token-2022 mints are supported.
+// Returns the transaction signature and the resolved compute budget.
+func (s *service) TransferToken(ctx context.Context, uid, walletID string, accountIndex int, pin, mint, destination, amount, memoText string, opts TransferOptions) (_ string, _ ComputeBudget, err error) {
+	a := newAuditRecord(uid, AuditActionTransfer)
+	a.details["account_index"] = accountIndex
+	a.details["mint"] = mint
+	a.details["destination"] = destination
+	defer func() { s.writeAudit(ctx, a, err) }()
+
+	if accountIndex < 0 || len(memoText) > MaxMemoLength {
+		return "", ComputeBudget{}, ErrInvalidParameter
+	}
+	if err := solanawallet.ValidateSolanaWalletAddr(mint); err != nil {
+		return "", ComputeBudget{}, fmt.Errorf("%w: invalid mint address", ErrInvalidParameter)
+	}
+
+	w, err := s.getWallet(ctx, uid, walletID)
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+	a.walletID = w.ID
+
+	source, err := s.accountPublicKey(ctx, w, accountIndex)
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+	if err := validateDestination(source, destination); err != nil {
+		return "", ComputeBudget{}, err
+	}
+
+	mintStates, err := s.getAccountStates(ctx, mint)
+	if err != nil {
+		return "", ComputeBudget{}, fmt.Errorf("failed to get mint account: %w", err)
+	}
+	decimals, ok := solanatx.ParseMintDecimals(mintStates[0])
+	if !ok {
+		return "", ComputeBudget{}, fmt.Errorf("%w: %s is not a token mint", ErrInvalidParameter, mint)
+	}
+
+	tokenAmount, err := utils.ParseAmount(amount, decimals)
+	if err != nil {
+		return "", ComputeBudget{}, fmt.Errorf("%w: %s", ErrInvalidAmount, err)
+	}
+	a.details["amount"] = tokenAmount
+
+	t, err := s.newTokenTransfer(ctx, source, destination, mint, mintStates[0].Owner)
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+	if t.balance < tokenAmount {
+		return "", ComputeBudget{}, fmt.Errorf("%w: balance %s, required %s",
+			ErrInsufficientFunds,
+			utils.AmountToString(t.balance, decimals),
+			utils.AmountToString(tokenAmount, decimals),
+		)
+	}
+
+	var newAccounts []uint64
+	if t.createDestination {
+		// Token-2022 accounts with extensions may require slightly more rent,
+		// which is caught by the simulation before sending
+		newAccounts = append(newAccounts, solanatx.TokenAccountSize)
+	}
+
+	return s.sendTransfer(ctx, a, uid, walletID, accountIndex, pin, t.from, t.instructions(tokenAmount, decimals), memoText, opts, newAccounts)
+}
+
+// tokenTransfer is a token transfer between the associated token accounts of the source and destination wallets
+type tokenTransfer struct {
+	from, to            common.PublicKey // wallets
+	mint, tokenProgram  common.PublicKey
+	source, destination common.PublicKey // associated token accounts
+	balance             uint64           // source token account balance, zero if it doesn't exist
+	createDestination   bool             // the destination token account doesn't exist yet
+}
+
+// newTokenTransfer derives the associated token accounts of the source and destination wallets
+// and loads their state
+func (s *service) newTokenTransfer(ctx context.Context, from, to, mint, tokenProgram string) (tokenTransfer, error) {
+	t := tokenTransfer{
+		from:         common.PublicKeyFromString(from),
+		to:           common.PublicKeyFromString(to),
+		mint:         common.PublicKeyFromString(mint),
+		tokenProgram: common.PublicKeyFromString(tokenProgram),
+	}
+
+	var err error
+	if t.source, err = solanawallet.DeriveTokenAccountWithProgram(t.from, t.mint, t.tokenProgram); err != nil {
+		return tokenTransfer{}, fmt.Errorf("failed to derive source token account: %w", err)
+	}
+	if t.destination, err = solanawallet.DeriveTokenAccountWithProgram(t.to, t.mint, t.tokenProgram); err != nil {
+		return tokenTransfer{}, fmt.Errorf("failed to derive destination token account: %w", err)
+	}
+
+	states, err := s.getAccountStates(ctx, t.source.ToBase58(), t.destination.ToBase58())
+	if err != nil {
+		return tokenTransfer{}, fmt.Errorf("failed to get token accounts: %w", err)
+	}
+	if acc, ok := solanatx.ParseTokenAccount(states[0]); ok {
+		t.balance = acc.Amount
+	}
+	t.createDestination = states[1] == nil
+
+	return t, nil
+}
+
+// instructions returns the token program instructions of the transfer,
+// creating the destination token account first if needed
+func (t tokenTransfer) instructions(amount uint64, decimals uint8) []types.Instruction {
+	var instructions []types.Instruction
+	if t.createDestination {
+		instructions = append(instructions, createTokenAccountIdempotent(t.from, t.to, t.mint, t.destination, t.tokenProgram))
+	}
+
+	transfer := token.TransferChecked(token.TransferCheckedParam{
+		From:     t.source,
+		To:       t.destination,
+		Mint:     t.mint,
+		Auth:     t.from,
+		Amount:   amount,
+		Decimals: decimals,
+	})
+	transfer.ProgramID = t.tokenProgram
+
+	return append(instructions, transfer)
+}
+
+// sendTransfer builds the transfer transaction, checks the source balance covers the fee
+// and the rent of the accounts to be created, then signs, simulates and sends it
+// as any other transaction sent from the wallet.
+// newAccounts are the data sizes of the accounts created by the transaction.
+func (s *service) sendTransfer(ctx context.Context, a *auditRecord, uid, walletID string, accountIndex int, pin string, from common.PublicKey, instructions []types.Instruction, memoText string, opts TransferOptions, newAccounts []uint64) (string, ComputeBudget, error) {
+	tx, budget, err := s.buildTransfer(ctx, a, from, instructions, memoText, opts)
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+
+	if err := s.checkTransferFee(ctx, from.ToBase58(), tx, newAccounts); err != nil {
+		return "", ComputeBudget{}, err
+	}
+
+	txSignature, _, err := s.signAndSendTransaction(ctx, a, uid, walletID, accountIndex, pin, tx, SendOptions{})
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+
+	return txSignature, budget, nil
+}
+
+// buildTransfer builds the transfer transaction paid by the source account
+// with the optional memo and the compute budget instructions.
+// If the nonce account is set, the transaction is durable: it advances the nonce
+// and uses the stored nonce instead of a recent blockhash.
+// Returns the transaction and the resolved compute budget.
+func (s *service) buildTransfer(ctx context.Context, a *auditRecord, from common.PublicKey, instructions []types.Instruction, memoText string, opts TransferOptions) (string, ComputeBudget, error) {
+	if memoText != "" {
+		instructions = append(instructions, memo.BuildMemo(memo.BuildMemoParam{
+			SignerPubkeys: []common.PublicKey{from},
+			Memo:          []byte(memoText),
+		}))
+	}
+
+	// the advance nonce instruction is prepended by the client,
+	// but it's counted by the default compute unit limit as well
+	budgetInstructions := instructions
+	var nonceAccount common.PublicKey
+	if opts.NonceAccount != "" {
+		acc, err := s.getNonceAccount(ctx, a.userID, opts.NonceAccount)
+		if err != nil {
+			return "", ComputeBudget{}, err
+		}
+		if acc.Authority != from.ToBase58() {
+			return "", ComputeBudget{}, fmt.Errorf("%w: nonce account %s is not owned by the wallet account", ErrInvalidParameter, opts.NonceAccount)
+		}
+		a.details["nonce_account"] = acc.Address
+
+		nonceAccount = common.PublicKeyFromString(acc.Address)
+		budgetInstructions = append([]types.Instruction{system.AdvanceNonceAccount(system.AdvanceNonceAccountParam{
+			Nonce: nonceAccount,
+			Auth:  from,
+		})}, instructions...)
+	}
+
+	budget, err := s.resolveComputeBudget(ctx, opts.ComputeBudget, budgetInstructions)
+	if err != nil {
+		return "", ComputeBudget{}, err
+	}
+	if budget.UnitLimit > 0 {
+		a.details["compute_unit_limit"] = budget.UnitLimit
+	}
+	if budget.UnitPrice > 0 {
+		a.details["compute_unit_price"] = budget.UnitPrice
+	}
+	instructions = append(solanatx.ComputeBudgetInstructions(budget.UnitLimit, budget.UnitPrice), instructions...)
+
+	var tx string
+	if opts.NonceAccount != "" {
+		tx, err = s.solana.NewDurableTransaction(ctx, client.NewDurableTransactionParams{
+			FeePayer:     &from,
+			NonceAuth:    from,
+			DurableNonce: nonceAccount,
+			Instructions: instructions,
+		})
+	} else {
+		tx, err = s.solana.NewTransaction(ctx, client.NewTransactionParams{
+			FeePayer:     from,
+			Instructions: instructions,
+		})
+	}
+	if err != nil {
+		return "", ComputeBudget{}, fmt.Errorf("failed to build transfer transaction: %w", err)
+	}
+
+	return tx, budget, nil
+}
+
+// checkSOLTransfer checks that the source balance covers the amount and the transaction fee,
+// and that neither the source nor the destination are left below the rent exempt minimum
+func (s *service) checkSOLTransfer(ctx context.Context, source, destination string, lamports uint64, tx string) error {
+	fee, err := s.solana.GetTransactionFee(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction fee: %w", err)
+	}
+
+	balance, err := s.solana.GetSOLBalance(ctx, source)
+	if err != nil {
+		return fmt.Errorf("failed to get balance: %w", err)
+	}
+	if balance < lamports || balance-lamports < fee {
+		return fmt.Errorf("%w: balance %s SOL, required %s SOL including the fee",
+			ErrInsufficientFunds,
+			utils.AmountToString(balance, solanatypes.SPLTokenDefaultDecimals),
+			utils.AmountToString(lamports+fee, solanatypes.SPLTokenDefaultDecimals),
+		)
+	}
+
+	rentExempt, err := s.solana.GetMinimumBalanceForRentExemption(ctx, 0)
+	if err != nil {
+		return fmt.Errorf("failed to get rent exempt minimum: %w", err)
+	}
+	if rest := balance - lamports - fee; rest > 0 && rest < rentExempt {
+		return fmt.Errorf("%w: the remaining balance must be either zero or at least %s SOL to stay rent exempt",
+			ErrInsufficientFunds,
+			utils.AmountToString(rentExempt, solanatypes.SPLTokenDefaultDecimals),
+		)
+	}
+
+	states, err := s.solana.GetAccountStates(ctx, []string{destination})
+	if err != nil {
+		return fmt.Errorf("failed to get destination account: %w", err)
+	}
+	if (len(states) == 0 || states[0] == nil) && lamports < rentExempt {
+		return fmt.Errorf("%w: the destination account does not exist, the amount must be at least %s SOL to create it",
+			ErrInvalidAmount,
+			utils.AmountToString(rentExempt, solanatypes.SPLTokenDefaultDecimals),
+		)
+	}
+
+	return nil
+}
+
+// checkTransferFee checks that the source SOL balance covers the transaction fee
+// and the rent of the accounts created by the transaction, given by their data sizes
+func (s *service) checkTransferFee(ctx context.Context, source, tx string, newAccounts []uint64) error {
+	required, err := s.solana.GetTransactionFee(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction fee: %w", err)
+	}
+
+	for _, size := range newAccounts {
+		rent, err := s.solana.GetMinimumBalanceForRentExemption(ctx, size)
+		if err != nil {
+			return fmt.Errorf("failed to get rent exempt minimum: %w", err)
+		}
+		required += rent
+	}
+
+	balance, err := s.solana.GetSOLBalance(ctx, source)
+	if err != nil {
+		return fmt.Errorf("failed to get balance: %w", err)
+	}
+	if balance < required {
+		return fmt.Errorf("%w: balance %s SOL, required %s SOL for the fee and the rent of the new accounts",
+			ErrInsufficientFunds,
+			utils.AmountToString(balance, solanatypes.SPLTokenDefaultDecimals),
+			utils.AmountToString(required, solanatypes.SPLTokenDefaultDecimals),
+		)
+	}
+
+	return nil
+}
+
+// getAccountStates returns the states of the given accounts in the same order,
+// nil state means the account does not exist
+func (s *service) getAccountStates(ctx context.Context, addresses ...string) ([]*solanatx.AccountState, error) {
+	states, err := s.solana.GetAccountStates(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+	if len(states) != len(addresses) {
+		return nil, fmt.Errorf("unexpected number of accounts: %d, expected %d", len(states), len(addresses))
+	}
+	return states, nil
+}
+
+// validateDestination checks the destination is a valid wallet address other than the source
+func validateDestination(source, destination string) error {
+	if err := solanawallet.ValidateSolanaWalletAddr(destination); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDestination, err)
+	}
+	if destination == source {
+		return fmt.Errorf("%w: can't transfer to the same account", ErrInvalidDestination)
+	}
+	return nil
+}
+
+// createTokenAccountIdempotent builds the associated token account creation instruction
+// which doesn't fail if the account already exists, paid by the funder.
+// Unlike associated_token_account.CreateAssociatedTokenAccount, it supports the Token-2022 program.
+func createTokenAccountIdempotent(funder, owner, mint, tokenAccount, tokenProgram common.PublicKey) types.Instruction {
+	return types.Instruction{
+		ProgramID: common.SPLAssociatedTokenAccountProgramID,
+		Accounts: []types.AccountMeta{
+			{PubKey: funder, IsSigner: true, IsWritable: true},
+			{PubKey: tokenAccount, IsSigner: false, IsWritable: true},
+			{PubKey: owner, IsSigner: false, IsWritable: false},
+			{PubKey: mint, IsSigner: false, IsWritable: false},
+			{PubKey: common.SystemProgramID, IsSigner: false, IsWritable: false},
+			{PubKey: tokenProgram, IsSigner: false, IsWritable: false},
+		},
+		Data: []byte{byte(associated_token_account.InstructionCreateIdempotent)},
+	}
+}
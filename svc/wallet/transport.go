@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/dmitrymomot/solana-wallets/internal/httpencoder"
+	"github.com/dmitrymomot/solana-wallets/internal/signingpolicy"
 	"github.com/go-chi/chi/v5"
 	jwtkit "github.com/go-kit/kit/auth/jwt"
 	"github.com/go-kit/kit/transport"
@@ -27,12 +29,19 @@ func MakeHTTPHandler(e Endpoints, log logger) http.Handler {
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorHandler(transport.NewLogErrorHandler(log)),
 		httptransport.ServerErrorEncoder(httpencoder.EncodeError(log, codeAndMessageFrom)),
-		httptransport.ServerBefore(jwtkit.HTTPToContext()),
+		httptransport.ServerBefore(jwtkit.HTTPToContext(), clientIPToContext),
 	}
 
 	r.Get("/generate", httptransport.NewServer(
 		e.GenerateWallet,
-		decodeEmptyRequest,
+		decodeGenerateWalletRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Post("/generate", httptransport.NewServer(
+		e.GenerateWallet,
+		decodeGenerateWalletRequest,
 		httpencoder.EncodeResponse,
 		options...,
 	).ServeHTTP)
@@ -44,6 +53,27 @@ func MakeHTTPHandler(e Endpoints, log logger) http.Handler {
 		options...,
 	).ServeHTTP)
 
+	r.Post("/import", httptransport.NewServer(
+		e.ImportWallet,
+		decodeImportWalletRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Post("/import/keystore", httptransport.NewServer(
+		e.ImportKeystore,
+		decodeImportKeystoreRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Get("/list", httptransport.NewServer(
+		e.ListWallets,
+		decodeEmptyRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
 	r.Get("/public/{id}", httptransport.NewServer(
 		e.GetWallet,
 		decodeGetWalletRequest,
@@ -51,6 +81,13 @@ func MakeHTTPHandler(e Endpoints, log logger) http.Handler {
 		options...,
 	).ServeHTTP)
 
+	r.Get("/public/{id}/{wallet_id}", httptransport.NewServer(
+		e.GetWallet,
+		decodeGetWalletRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
 	r.Post("/delete", httptransport.NewServer(
 		e.DeleteWallet,
 		decodeDeleteWalletRequest,
@@ -58,6 +95,20 @@ func MakeHTTPHandler(e Endpoints, log logger) http.Handler {
 		options...,
 	).ServeHTTP)
 
+	r.Get("/deleted", httptransport.NewServer(
+		e.ListDeletedWallets,
+		decodeEmptyRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Post("/restore", httptransport.NewServer(
+		e.RestoreWallet,
+		decodeRestoreWalletRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
 	r.Patch("/update/name", httptransport.NewServer(
 		e.UpdateWalletName,
 		decodeUpdateWalletNameRequest,
@@ -79,6 +130,20 @@ func MakeHTTPHandler(e Endpoints, log logger) http.Handler {
 		options...,
 	).ServeHTTP)
 
+	r.Get("/accounts", httptransport.NewServer(
+		e.ListAccounts,
+		decodeListAccountsRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Post("/accounts/add", httptransport.NewServer(
+		e.AddAccount,
+		decodeAddAccountRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
 	r.Post("/transaction/sign", httptransport.NewServer(
 		e.SignTransaction,
 		decodeSignTransactionRequest,
@@ -86,6 +151,83 @@ func MakeHTTPHandler(e Endpoints, log logger) http.Handler {
 		options...,
 	).ServeHTTP)
 
+	r.Post("/transaction/preview", httptransport.NewServer(
+		e.PreviewTransaction,
+		decodePreviewTransactionRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Post("/transaction/simulate", httptransport.NewServer(
+		e.SimulateTransaction,
+		decodeSimulateTransactionRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Post("/transfer/sol", httptransport.NewServer(
+		e.TransferSOL,
+		decodeTransferSOLRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Post("/transfer/token", httptransport.NewServer(
+		e.TransferToken,
+		decodeTransferTokenRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Post("/transfer/nft", httptransport.NewServer(
+		e.TransferNFT,
+		decodeTransferNFTRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Get("/transactions", httptransport.NewServer(
+		e.ListTransactions,
+		decodeListTransactionsRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Get("/transactions/{signature}", httptransport.NewServer(
+		e.GetTransaction,
+		decodeGetTransactionRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Post("/nonce/create", httptransport.NewServer(
+		e.CreateNonceAccount,
+		decodeCreateNonceAccountRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Get("/nonce", httptransport.NewServer(
+		e.ListNonceAccounts,
+		decodeListNonceAccountsRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Get("/nonce/{address}", httptransport.NewServer(
+		e.GetNonceAccount,
+		decodeGetNonceAccountRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Post("/nonce/close", httptransport.NewServer(
+		e.CloseNonceAccount,
+		decodeCloseNonceAccountRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
 	r.Post("/message/sign", httptransport.NewServer(
 		e.SignMessage,
 		decodeSignMessageRequest,
@@ -100,23 +242,89 @@ func MakeHTTPHandler(e Endpoints, log logger) http.Handler {
 		options...,
 	).ServeHTTP)
 
+	r.Get("/policy", httptransport.NewServer(
+		e.GetSigningPolicy,
+		decodeGetSigningPolicyRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Put("/policy", httptransport.NewServer(
+		e.UpdateSigningPolicy,
+		decodeUpdateSigningPolicyRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Get("/audit", httptransport.NewServer(
+		e.ListAuditLog,
+		decodeListAuditLogRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Get("/admin/audit", httptransport.NewServer(
+		e.AdminListAuditLog,
+		decodeAdminListAuditLogRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
 	return r
 }
 
 // returns http error code by error type
 func codeAndMessageFrom(err error) (int, interface{}) {
-	if errors.Is(err, ErrInvalidParameter) || errors.Is(err, ErrInvalidPIN) {
+	if errors.Is(err, ErrInvalidParameter) ||
+		errors.Is(err, ErrInvalidPIN) ||
+		errors.Is(err, ErrInvalidMnemonic) ||
+		errors.Is(err, ErrInvalidPrivateKey) ||
+		errors.Is(err, ErrInvalidKeystore) ||
+		errors.Is(err, ErrInvalidTransaction) ||
+		errors.Is(err, ErrInvalidDestination) ||
+		errors.Is(err, ErrInvalidAmount) ||
+		errors.Is(err, ErrNoMnemonic) {
 		return http.StatusBadRequest, err.Error()
 	}
 	if errors.Is(err, ErrNotFound) {
 		return http.StatusNotFound, err.Error()
 	}
+	var violation *signingpolicy.Violation
+	if errors.As(err, &violation) {
+		return http.StatusForbidden, httpencoder.ErrorResponse{
+			Code:    http.StatusForbidden,
+			Err:     signingpolicy.ErrViolation.Error(),
+			Message: violation.Message,
+			Details: violation,
+		}
+	}
+	var simulation *SimulationError
+	if errors.As(err, &simulation) {
+		return http.StatusUnprocessableEntity, httpencoder.ErrorResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Err:     ErrSimulationFailed.Error(),
+			Message: simulation.Error(),
+			Details: simulation.Simulation,
+		}
+	}
+	if errors.Is(err, ErrInsufficientFunds) {
+		return http.StatusUnprocessableEntity, err.Error()
+	}
 	if errors.Is(err, ErrForbidden) {
 		return http.StatusForbidden, err.Error()
 	}
 	if errors.Is(err, ErrUnauthorized) {
 		return http.StatusUnauthorized, err.Error()
 	}
+	if errors.Is(err, ErrAlreadyExists) {
+		return http.StatusConflict, err.Error()
+	}
+	if errors.Is(err, ErrTooManyAttempts) || errors.Is(err, ErrTooManyImports) {
+		return http.StatusTooManyRequests, err.Error()
+	}
+	if errors.Is(err, ErrWalletLocked) {
+		return http.StatusLocked, err.Error()
+	}
 
 	return httpencoder.CodeAndMessageFrom(err)
 }
@@ -125,6 +333,20 @@ func decodeEmptyRequest(ctx context.Context, r *http.Request) (interface{}, erro
 	return nil, nil
 }
 
+// GET request generates a wallet with the default options,
+// POST request accepts the options in the JSON body
+func decodeGenerateWalletRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req GenerateWalletRequest
+	if r.Method == http.MethodGet {
+		return req, nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
 func decodeStoreWalletRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	var req StoreWalletRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -134,13 +356,34 @@ func decodeStoreWalletRequest(ctx context.Context, r *http.Request) (interface{}
 	return req, nil
 }
 
+func decodeImportWalletRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req ImportWalletRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
+func decodeImportKeystoreRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req ImportKeystoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
 func decodeGetWalletRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	id := chi.URLParam(r, "id")
 	if id == "" {
 		return nil, ErrInvalidParameter
 	}
 
-	return id, nil
+	return GetWalletRequest{
+		UserID:   id,
+		WalletID: chi.URLParam(r, "wallet_id"),
+	}, nil
 }
 
 func decodeDeleteWalletRequest(ctx context.Context, r *http.Request) (interface{}, error) {
@@ -152,6 +395,15 @@ func decodeDeleteWalletRequest(ctx context.Context, r *http.Request) (interface{
 	return req, nil
 }
 
+func decodeRestoreWalletRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req RestoreWalletRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
 func decodeUpdateWalletNameRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	var req UpdateWalletNameRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -179,6 +431,110 @@ func decodeExportWalletRequest(ctx context.Context, r *http.Request) (interface{
 	return req, nil
 }
 
+func decodeListAccountsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return ListAccountsRequest{
+		WalletID: r.URL.Query().Get("wallet_id"),
+	}, nil
+}
+
+func decodeAddAccountRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req AddAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
+func decodePreviewTransactionRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req PreviewTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
+func decodeSimulateTransactionRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req SimulateTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
+func decodeTransferSOLRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req TransferSOLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
+func decodeTransferTokenRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req TransferTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
+func decodeTransferNFTRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req TransferNFTRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
+func decodeListTransactionsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	limit, offset, err := decodePagination(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ListTransactionsRequest{
+		WalletID: r.URL.Query().Get("wallet_id"),
+		Status:   r.URL.Query().Get("status"),
+		Limit:    limit,
+		Offset:   offset,
+	}, nil
+}
+
+func decodeGetTransactionRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return GetTransactionRequest{Signature: chi.URLParam(r, "signature")}, nil
+}
+
+func decodeCreateNonceAccountRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req CreateNonceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
+func decodeListNonceAccountsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return ListNonceAccountsRequest{WalletID: r.URL.Query().Get("wallet_id")}, nil
+}
+
+func decodeGetNonceAccountRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return GetNonceAccountRequest{Address: chi.URLParam(r, "address")}, nil
+}
+
+func decodeCloseNonceAccountRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req CloseNonceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
 func decodeSignTransactionRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	var req SignTransactionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -205,3 +561,60 @@ func decodeSignAndSendTransactionRequest(ctx context.Context, r *http.Request) (
 
 	return req, nil
 }
+
+func decodeGetSigningPolicyRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return GetSigningPolicyRequest{WalletID: r.URL.Query().Get("wallet_id")}, nil
+}
+
+func decodeUpdateSigningPolicyRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req UpdateSigningPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
+func decodeListAuditLogRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	limit, offset, err := decodePagination(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ListAuditLogRequest{
+		WalletID: r.URL.Query().Get("wallet_id"),
+		Limit:    limit,
+		Offset:   offset,
+	}, nil
+}
+
+func decodeAdminListAuditLogRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	limit, offset, err := decodePagination(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return AdminListAuditLogRequest{
+		UserID:   r.URL.Query().Get("user_id"),
+		WalletID: r.URL.Query().Get("wallet_id"),
+		Limit:    limit,
+		Offset:   offset,
+	}, nil
+}
+
+// decodePagination returns the optional limit and offset query params
+func decodePagination(r *http.Request) (limit, offset int, err error) {
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			return 0, 0, fmt.Errorf("%w: limit must be a number", ErrInvalidParameter)
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil {
+			return 0, 0, fmt.Errorf("%w: offset must be a number", ErrInvalidParameter)
+		}
+	}
+
+	return limit, offset, nil
+}
@@ -1,9 +1,119 @@
 package wallet
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/dmitrymomot/solana-wallets/internal/signingpolicy"
+	"github.com/dmitrymomot/solana-wallets/internal/solanawallet"
+)
+
+// Wallet export formats
+const (
+	ExportFormatPlain    = "plain"    // mnemonic, passphrase and base58 private key
+	ExportFormatKeypair  = "keypair"  // solana-keygen keypair file content
+	ExportFormatKeystore = "keystore" // keystore file encrypted with the export password
+)
+
 // Wallet struct is a representation of wallet entity.
 type Wallet struct {
-	Name       string `json:"name"`
-	PublicKey  string `json:"public_key"`
-	PrivateKey string `json:"private_key,omitempty"`
-	Mnemonic   string `json:"mnemonic,omitempty"`
+	ID         string                 `json:"id,omitempty"`
+	Name       string                 `json:"name"`
+	IsDefault  bool                   `json:"is_default"`
+	PublicKey  string                 `json:"public_key"`
+	PrivateKey string                 `json:"private_key,omitempty"`
+	Mnemonic   string                 `json:"mnemonic,omitempty"`
+	Passphrase string                 `json:"passphrase,omitempty"`
+	Keypair    json.RawMessage        `json:"keypair,omitempty"`
+	Keystore   *solanawallet.Keystore `json:"keystore,omitempty"`
+	DeletedAt  *time.Time             `json:"deleted_at,omitempty"`
+	PurgeAt    *time.Time             `json:"purge_at,omitempty"` // the wallet can be restored until then
+}
+
+// Account struct is a representation of an account derived from the wallet mnemonic
+// with the BIP44 path m/44'/501'/{index}'/0'.
+type Account struct {
+	Index     int    `json:"index"`
+	Name      string `json:"name"`
+	PublicKey string `json:"public_key"`
+}
+
+// AuditLogEntry struct is a representation of a sensitive wallet operation record
+type AuditLogEntry struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"user_id"`
+	WalletID  string          `json:"wallet_id,omitempty"`
+	Action    string          `json:"action"`
+	Outcome   string          `json:"outcome"`
+	Error     string          `json:"error,omitempty"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	IP        string          `json:"ip,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// SigningPolicy struct is a representation of the wallet signing policy.
+// Policy is the wallet's own policy, Effective is the one with the defaults applied.
+// SpentToday is the amount sent from the wallet during the last 24 hours by mint, in base units.
+type SigningPolicy struct {
+	WalletID   string               `json:"wallet_id"`
+	Policy     signingpolicy.Policy `json:"policy"`
+	Effective  signingpolicy.Policy `json:"effective"`
+	SpentToday map[string]uint64    `json:"spent_today"`
+}
+
+// SendOptions struct defines the transaction simulation before sending.
+// Simulate overrides the service default, nil keeps it.
+// IgnoreSimulationError sends the transaction even if the simulation fails.
+type SendOptions struct {
+	Simulate              *bool
+	IgnoreSimulationError bool
+}
+
+// ComputeBudget struct defines the compute budget of the transactions built by the server.
+// Zero UnitLimit keeps the cluster default limit, zero UnitPrice sends the transaction without the priority fee.
+// AutoPrice estimates the unit price from the recent prioritization fees of the accounts involved.
+// PriorityFee is the resulting prioritization fee, set by the service.
+type ComputeBudget struct {
+	UnitLimit   uint32 `json:"unit_limit,omitempty"`
+	UnitPrice   uint64 `json:"unit_price,omitempty"` // micro-lamports per compute unit
+	AutoPrice   bool   `json:"auto_price,omitempty"`
+	PriorityFee uint64 `json:"priority_fee,omitempty"` // lamports
+}
+
+// TransferOptions struct defines how the transfer transaction is built by the server.
+// NonceAccount is the optional durable nonce account of the wallet account,
+// its nonce is used instead of a recent blockhash, so the transaction doesn't expire
+// until the nonce is advanced.
+type TransferOptions struct {
+	ComputeBudget ComputeBudget
+	NonceAccount  string
+}
+
+// Transaction struct is a representation of the transaction sent from the wallet.
+// Error is the transaction error as returned by the RPC node, set for the failed transactions only.
+type Transaction struct {
+	ID           string          `json:"id"`
+	WalletID     string          `json:"wallet_id,omitempty"` // empty if the wallet is purged
+	AccountIndex int             `json:"account_index"`
+	Signature    string          `json:"signature"`
+	Status       string          `json:"status"`
+	Error        json.RawMessage `json:"error,omitempty"`
+	Slot         uint64          `json:"slot,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    *time.Time      `json:"updated_at,omitempty"`
+}
+
+// NonceAccount struct is a representation of the durable nonce account owned by the wallet account.
+// Authority, Nonce, LamportsPerSignature and Lamports are the on-chain state,
+// they are empty if the account is not initialized or doesn't exist anymore.
+type NonceAccount struct {
+	Address              string    `json:"address"`
+	WalletID             string    `json:"wallet_id,omitempty"` // empty if the wallet is purged
+	AccountIndex         int       `json:"account_index"`
+	Authority            string    `json:"authority,omitempty"`
+	Nonce                string    `json:"nonce,omitempty"`
+	LamportsPerSignature uint64    `json:"lamports_per_signature,omitempty"`
+	Lamports             uint64    `json:"lamports"`
+	CreatedAt            time.Time `json:"created_at"`
 }
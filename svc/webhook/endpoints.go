@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dmitrymomot/oauth2-server/lib/middleware"
+	"github.com/dmitrymomot/solana-wallets/internal/validator"
+	"github.com/go-kit/kit/endpoint"
+)
+
+// AdminScope is the access token scope required to manage webhooks
+const AdminScope = "wallets:admin"
+
+type (
+	// Endpoints collection of webhook Service
+	Endpoints struct {
+		CreateSubscription endpoint.Endpoint
+		ListSubscriptions  endpoint.Endpoint
+		DeleteSubscription endpoint.Endpoint
+		ListDeliveries     endpoint.Endpoint
+		Redeliver          endpoint.Endpoint
+	}
+)
+
+// Init endpoints
+func MakeEndpoints(s Service, m ...endpoint.Middleware) Endpoints {
+	e := Endpoints{
+		CreateSubscription: MakeCreateSubscriptionEndpoint(s),
+		ListSubscriptions:  MakeListSubscriptionsEndpoint(s),
+		DeleteSubscription: MakeDeleteSubscriptionEndpoint(s),
+		ListDeliveries:     MakeListDeliveriesEndpoint(s),
+		Redeliver:          MakeRedeliverEndpoint(s),
+	}
+
+	// setup middlewares for each endpoints
+	if len(m) > 0 {
+		for _, mdw := range m {
+			e.CreateSubscription = mdw(e.CreateSubscription)
+			e.ListSubscriptions = mdw(e.ListSubscriptions)
+			e.DeleteSubscription = mdw(e.DeleteSubscription)
+			e.ListDeliveries = mdw(e.ListDeliveries)
+			e.Redeliver = mdw(e.Redeliver)
+		}
+	}
+
+	return e
+}
+
+// CreateSubscriptionRequest is a request for CreateSubscription method
+type CreateSubscriptionRequest struct {
+	URL    string   `json:"url" validate:"required|fullUrl" label:"URL"`
+	Events []string `json:"events" label:"Events"`
+	Secret string   `json:"secret" validate:"minLen:16|maxLen:256" label:"Secret"`
+}
+
+// MakeCreateSubscriptionEndpoint returns an endpoint function for the CreateSubscription method.
+func MakeCreateSubscriptionEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if err := checkAdmin(ctx); err != nil {
+			return nil, err
+		}
+
+		req, ok := request.(CreateSubscriptionRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.CreateSubscription(ctx, req.URL, req.Events, req.Secret)
+	}
+}
+
+// MakeListSubscriptionsEndpoint returns an endpoint function for the ListSubscriptions method.
+func MakeListSubscriptionsEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if err := checkAdmin(ctx); err != nil {
+			return nil, err
+		}
+
+		return s.ListSubscriptions(ctx)
+	}
+}
+
+// DeleteSubscriptionRequest is a request for DeleteSubscription method
+type DeleteSubscriptionRequest struct {
+	ID string `json:"id" validate:"required|uuid" label:"Subscription ID"`
+}
+
+// MakeDeleteSubscriptionEndpoint returns an endpoint function for the DeleteSubscription method.
+func MakeDeleteSubscriptionEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if err := checkAdmin(ctx); err != nil {
+			return nil, err
+		}
+
+		req, ok := request.(DeleteSubscriptionRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		if err := s.DeleteSubscription(ctx, req.ID); err != nil {
+			return nil, err
+		}
+
+		return true, nil
+	}
+}
+
+// ListDeliveriesRequest is a request for ListDeliveries method
+type ListDeliveriesRequest struct {
+	Status         string `json:"status" validate:"enum:pending,delivered,dead" label:"Status"`
+	SubscriptionID string `json:"subscription_id" validate:"uuid" label:"Subscription ID"`
+	Limit          int    `json:"limit" validate:"min:0|max:100" label:"Limit"`
+	Offset         int    `json:"offset" validate:"min:0" label:"Offset"`
+}
+
+// MakeListDeliveriesEndpoint returns an endpoint function for the ListDeliveries method.
+func MakeListDeliveriesEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if err := checkAdmin(ctx); err != nil {
+			return nil, err
+		}
+
+		req, ok := request.(ListDeliveriesRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.ListDeliveries(ctx, req.Status, req.SubscriptionID, req.Limit, req.Offset)
+	}
+}
+
+// RedeliverRequest is a request for Redeliver method
+type RedeliverRequest struct {
+	ID string `json:"id" validate:"required|uuid" label:"Delivery ID"`
+}
+
+// MakeRedeliverEndpoint returns an endpoint function for the Redeliver method.
+func MakeRedeliverEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if err := checkAdmin(ctx); err != nil {
+			return nil, err
+		}
+
+		req, ok := request.(RedeliverRequest)
+		if !ok {
+			return nil, ErrInvalidParameter
+		}
+		if v := validator.ValidateStruct(req); v != nil {
+			return nil, validator.NewValidationError(v)
+		}
+
+		return s.Redeliver(ctx, req.ID)
+	}
+}
+
+// checkAdmin returns an error if the access token from the context has no AdminScope
+func checkAdmin(ctx context.Context) error {
+	info, ok := middleware.GetTokenInfoFromContext(ctx)
+	if !ok || info == nil {
+		return ErrUnauthorized
+	}
+
+	for _, s := range strings.Fields(info.Scope) {
+		if s == AdminScope {
+			return nil
+		}
+	}
+
+	return ErrForbidden
+}
@@ -0,0 +1,12 @@
+package webhook
+
+import "errors"
+
+// Predefined package errors
+var (
+	ErrInvalidParameter = errors.New("invalid parameter")
+	ErrNotFound         = errors.New("not found")
+	ErrForbidden        = errors.New("forbidden")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrUnknownEvent     = errors.New("unknown event")
+)
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"net/http"
+	"time"
+)
+
+type (
+	// Option is a function that configures the service
+	Option func(*service)
+
+	// RetryPolicy defines how failed deliveries are retried.
+	// Every next attempt is delayed exponentially, starting from BaseDelay and capped by MaxDelay.
+	// Once the number of attempts reaches MaxAttempts, the delivery goes to the dead-letter state.
+	RetryPolicy struct {
+		BaseDelay   time.Duration
+		MaxDelay    time.Duration
+		MaxAttempts int
+	}
+)
+
+// DefaultRetryPolicy is used if no custom policy is provided
+var DefaultRetryPolicy = RetryPolicy{
+	BaseDelay:   30 * time.Second,
+	MaxDelay:    6 * time.Hour,
+	MaxAttempts: 10,
+}
+
+// Dispatcher defaults
+const (
+	DefaultBatchSize = 50
+	DefaultTimeout   = 10 * time.Second
+)
+
+// WithRetryPolicy sets the policy for failed deliveries.
+// Zero values fall back to the default policy ones.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(s *service) {
+		if p.BaseDelay > 0 {
+			s.retry.BaseDelay = p.BaseDelay
+		}
+		if p.MaxDelay > 0 {
+			s.retry.MaxDelay = p.MaxDelay
+		}
+		if p.MaxAttempts > 0 {
+			s.retry.MaxAttempts = p.MaxAttempts
+		}
+	}
+}
+
+// WithBatchSize sets the max number of deliveries sent per dispatch run
+func WithBatchSize(n int) Option {
+	return func(s *service) {
+		if n > 0 {
+			s.batchSize = n
+		}
+	}
+}
+
+// WithTimeout sets the webhook request timeout
+func WithTimeout(d time.Duration) Option {
+	return func(s *service) {
+		if d > 0 {
+			s.client = &http.Client{Timeout: d}
+		}
+	}
+}
+
+// retryDelay returns the delay before the next attempt after the given number of failed attempts
+func (p RetryPolicy) retryDelay(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,51 @@
+-- +migrate Up
+-- +migrate StatementBegin
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE
+OR REPLACE FUNCTION webhooks_update_updated_at_column() RETURNS TRIGGER AS $$
+BEGIN NEW .updated_at = NOW();
+RETURN NEW;
+END;
+$$ LANGUAGE 'plpgsql';
+
+-- empty events list means all events
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    url VARCHAR NOT NULL,
+    events VARCHAR[] NOT NULL DEFAULT '{}',
+    secret VARCHAR NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NULL
+);
+CREATE TRIGGER update_webhook_subscriptions_modtime BEFORE
+UPDATE ON webhook_subscriptions FOR EACH ROW EXECUTE PROCEDURE webhooks_update_updated_at_column();
+
+-- outbox of the webhook deliveries, one row per event and subscription;
+-- status is one of: pending, delivered, dead
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event VARCHAR NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_status_code INTEGER DEFAULT NULL,
+    last_error VARCHAR DEFAULT NULL,
+    delivered_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NULL
+);
+CREATE INDEX webhook_outbox_pending ON webhook_outbox (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_outbox_subscription_id ON webhook_outbox (subscription_id);
+CREATE TRIGGER update_webhook_outbox_modtime BEFORE
+UPDATE ON webhook_outbox FOR EACH ROW EXECUTE PROCEDURE webhooks_update_updated_at_column();
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS update_webhook_outbox_modtime ON webhook_outbox;
+DROP TABLE IF EXISTS webhook_outbox;
+DROP TRIGGER IF EXISTS update_webhook_subscriptions_modtime ON webhook_subscriptions;
+DROP TABLE IF EXISTS webhook_subscriptions;
+DROP FUNCTION IF EXISTS webhooks_update_updated_at_column();
@@ -0,0 +1,34 @@
+-- name: EnqueueEvent :execrows
+INSERT INTO webhook_outbox (subscription_id, event, payload)
+SELECT id, @event::VARCHAR, @payload::JSONB FROM webhook_subscriptions
+WHERE is_active AND (cardinality(events) = 0 OR @event::VARCHAR = ANY(events));
+
+-- name: ClaimDueDeliveries :many
+UPDATE webhook_outbox SET next_attempt_at = @lease_until
+WHERE id IN (
+    SELECT id FROM webhook_outbox
+    WHERE status = 'pending' AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at ASC
+    LIMIT @limit_val
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkDeliveryDelivered :exec
+UPDATE webhook_outbox SET status = 'delivered', attempts = attempts + 1, last_status_code = $2, last_error = NULL, delivered_at = NOW() WHERE id = $1;
+
+-- name: MarkDeliveryFailed :exec
+UPDATE webhook_outbox SET status = $2, attempts = attempts + 1, next_attempt_at = $3, last_status_code = $4, last_error = $5 WHERE id = $1;
+
+-- name: GetDelivery :one
+SELECT * FROM webhook_outbox WHERE id = $1;
+
+-- name: GetDeliveries :many
+SELECT * FROM webhook_outbox
+WHERE (@status::VARCHAR = '' OR status = @status)
+AND (sqlc.narg(subscription_id)::UUID IS NULL OR subscription_id = sqlc.narg(subscription_id))
+ORDER BY created_at DESC
+LIMIT @limit_val OFFSET @offset_val;
+
+-- name: RedeliverDelivery :one
+UPDATE webhook_outbox SET status = 'pending', attempts = 0, next_attempt_at = NOW(), last_error = NULL WHERE id = $1 RETURNING *;
@@ -0,0 +1,11 @@
+-- name: CreateSubscription :one
+INSERT INTO webhook_subscriptions (url, events, secret) VALUES ($1, $2, $3) RETURNING *;
+
+-- name: GetSubscription :one
+SELECT * FROM webhook_subscriptions WHERE id = $1;
+
+-- name: GetSubscriptions :many
+SELECT * FROM webhook_subscriptions ORDER BY created_at ASC;
+
+-- name: DeleteSubscription :exec
+DELETE FROM webhook_subscriptions WHERE id = $1;
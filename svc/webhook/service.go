@@ -0,0 +1,397 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dmitrymomot/solana-wallets/internal/events"
+	"github.com/dmitrymomot/solana-wallets/internal/utils"
+	webhook_repository "github.com/dmitrymomot/solana-wallets/svc/webhook/repository"
+	"github.com/google/uuid"
+)
+
+type (
+	// Service interface
+	Service interface {
+		// Create webhook subscription for the given wallet events, empty list means all events.
+		// If secret is empty, a random one is generated.
+		CreateSubscription(ctx context.Context, url string, eventNames []string, secret string) (Subscription, error)
+		// List all webhook subscriptions
+		ListSubscriptions(ctx context.Context) ([]Subscription, error)
+		// Delete webhook subscription with all its deliveries
+		DeleteSubscription(ctx context.Context, id string) error
+		// List deliveries, newest first, optionally filtered by status and subscription id
+		ListDeliveries(ctx context.Context, status, subscriptionID string, limit, offset int) ([]Delivery, error)
+		// Schedule the delivery to be sent again as soon as possible, with a fresh retry budget
+		Redeliver(ctx context.Context, id string) (Delivery, error)
+		// EnqueueEvent is the events.TxListener which puts the event to the outbox
+		// for every active subscription interested in it, within the transaction of the event change
+		EnqueueEvent(ctx context.Context, tx *sql.Tx, name events.EventName, payload interface{}) error
+		// Send the due deliveries from the outbox, returns the number of sent ones
+		DispatchDueDeliveries(ctx context.Context) (int, error)
+	}
+
+	// service struct
+	service struct {
+		repo      webhookRepository
+		client    *http.Client
+		retry     RetryPolicy
+		batchSize int
+	}
+
+	webhookRepository interface {
+		CreateSubscription(ctx context.Context, arg webhook_repository.CreateSubscriptionParams) (webhook_repository.WebhookSubscription, error)
+		GetSubscription(ctx context.Context, id uuid.UUID) (webhook_repository.WebhookSubscription, error)
+		GetSubscriptions(ctx context.Context) ([]webhook_repository.WebhookSubscription, error)
+		DeleteSubscription(ctx context.Context, id uuid.UUID) error
+		EnqueueEvent(ctx context.Context, arg webhook_repository.EnqueueEventParams) (int64, error)
+		ClaimDueDeliveries(ctx context.Context, arg webhook_repository.ClaimDueDeliveriesParams) ([]webhook_repository.WebhookOutbox, error)
+		MarkDeliveryDelivered(ctx context.Context, arg webhook_repository.MarkDeliveryDeliveredParams) error
+		MarkDeliveryFailed(ctx context.Context, arg webhook_repository.MarkDeliveryFailedParams) error
+		GetDeliveries(ctx context.Context, arg webhook_repository.GetDeliveriesParams) ([]webhook_repository.WebhookOutbox, error)
+		RedeliverDelivery(ctx context.Context, id uuid.UUID) (webhook_repository.WebhookOutbox, error)
+	}
+)
+
+// Limits
+const (
+	maxDeliveriesLimit = 100
+	maxErrorLength     = 1000
+	secretLength       = 32
+)
+
+// NewService is a factory function,
+// returns a new instance of the Service interface implementation
+func NewService(repo webhookRepository, opts ...Option) Service {
+	s := &service{
+		repo:      repo,
+		client:    &http.Client{Timeout: DefaultTimeout},
+		retry:     DefaultRetryPolicy,
+		batchSize: DefaultBatchSize,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Create webhook subscription for the given wallet events, empty list means all events.
+// If secret is empty, a random one is generated.
+func (s *service) CreateSubscription(ctx context.Context, url string, eventNames []string, secret string) (Subscription, error) {
+	for _, name := range eventNames {
+		if !isWalletEvent(name) {
+			return Subscription{}, fmt.Errorf("%w: %s", ErrUnknownEvent, name)
+		}
+	}
+	if eventNames == nil {
+		eventNames = []string{}
+	}
+
+	if secret == "" {
+		b := make([]byte, secretLength)
+		if _, err := io.ReadFull(rand.Reader, b); err != nil {
+			return Subscription{}, fmt.Errorf("failed to generate secret: %w", err)
+		}
+		secret = hex.EncodeToString(b)
+	}
+
+	sub, err := s.repo.CreateSubscription(ctx, webhook_repository.CreateSubscriptionParams{
+		URL:    url,
+		Events: eventNames,
+		Secret: secret,
+	})
+	if err != nil {
+		return Subscription{}, fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	result := castSubscription(sub)
+	result.Secret = sub.Secret
+
+	return result, nil
+}
+
+// List all webhook subscriptions
+func (s *service) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	subs, err := s.repo.GetSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+
+	result := make([]Subscription, 0, len(subs))
+	for _, sub := range subs {
+		result = append(result, castSubscription(sub))
+	}
+
+	return result, nil
+}
+
+// Delete webhook subscription with all its deliveries
+func (s *service) DeleteSubscription(ctx context.Context, id string) error {
+	subID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrInvalidParameter
+	}
+
+	if err := s.repo.DeleteSubscription(ctx, subID); err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+
+	return nil
+}
+
+// List deliveries, newest first, optionally filtered by status and subscription id
+func (s *service) ListDeliveries(ctx context.Context, status, subscriptionID string, limit, offset int) ([]Delivery, error) {
+	if limit <= 0 {
+		limit = maxDeliveriesLimit
+	}
+	if limit > maxDeliveriesLimit || offset < 0 {
+		return nil, ErrInvalidParameter
+	}
+
+	params := webhook_repository.GetDeliveriesParams{
+		Status: status,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	}
+	if subscriptionID != "" {
+		id, err := uuid.Parse(subscriptionID)
+		if err != nil {
+			return nil, ErrInvalidParameter
+		}
+		params.SubscriptionID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	deliveries, err := s.repo.GetDeliveries(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deliveries: %w", err)
+	}
+
+	result := make([]Delivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		result = append(result, castDelivery(d))
+	}
+
+	return result, nil
+}
+
+// Schedule the delivery to be sent again as soon as possible, with a fresh retry budget
+func (s *service) Redeliver(ctx context.Context, id string) (Delivery, error) {
+	deliveryID, err := uuid.Parse(id)
+	if err != nil {
+		return Delivery{}, ErrInvalidParameter
+	}
+
+	d, err := s.repo.RedeliverDelivery(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Delivery{}, ErrNotFound
+		}
+		return Delivery{}, fmt.Errorf("failed to redeliver: %w", err)
+	}
+
+	return castDelivery(d), nil
+}
+
+// EnqueueEvent is the events.TxListener which puts the event to the outbox
+// for every active subscription interested in it.
+// The outbox rows are inserted within the given transaction of the event change,
+// so they are stored if and only if the change is committed. Nil tx inserts them right away.
+func (s *service) EnqueueEvent(ctx context.Context, tx *sql.Tx, name events.EventName, payload interface{}) error {
+	body, err := json.Marshal(Event{
+		ID:        uuid.New().String(),
+		Event:     string(name),
+		CreatedAt: time.Now().UTC(),
+		Data:      payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	repo := s.repo
+	if tx != nil {
+		q, ok := s.repo.(*webhook_repository.Queries)
+		if !ok {
+			return errors.New("repository does not support transactions")
+		}
+		repo = q.WithTx(tx)
+	}
+
+	if _, err := repo.EnqueueEvent(ctx, webhook_repository.EnqueueEventParams{
+		Event:   string(name),
+		Payload: body,
+	}); err != nil {
+		return fmt.Errorf("failed to enqueue webhook event: %w", err)
+	}
+
+	return nil
+}
+
+// Send the due deliveries from the outbox, returns the number of sent ones.
+// The claimed deliveries are sent one by one, so the batch is leased for the request timeout
+// of every delivery plus one more as a margin. They are picked up again if the process dies while sending.
+func (s *service) DispatchDueDeliveries(ctx context.Context) (int, error) {
+	deliveries, err := s.repo.ClaimDueDeliveries(ctx, webhook_repository.ClaimDueDeliveriesParams{
+		LeaseUntil: time.Now().UTC().Add(s.leaseDuration()),
+		Limit:      int32(s.batchSize),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim due deliveries: %w", err)
+	}
+
+	subs := make(map[uuid.UUID]webhook_repository.WebhookSubscription)
+	sent := 0
+	for _, d := range deliveries {
+		sub, ok := subs[d.SubscriptionID]
+		if !ok {
+			sub, err = s.repo.GetSubscription(ctx, d.SubscriptionID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return sent, fmt.Errorf("failed to get subscription: %w", err)
+			}
+			subs[d.SubscriptionID] = sub
+		}
+
+		var statusCode int
+		if sub.IsActive {
+			statusCode, err = s.send(ctx, sub, d)
+		} else {
+			err = errors.New("subscription is inactive")
+		}
+
+		if err == nil {
+			if err := s.repo.MarkDeliveryDelivered(ctx, webhook_repository.MarkDeliveryDeliveredParams{
+				ID:             d.ID,
+				LastStatusCode: sql.NullInt32{Int32: int32(statusCode), Valid: true},
+			}); err != nil {
+				return sent, fmt.Errorf("failed to mark delivery as delivered: %w", err)
+			}
+			sent++
+			continue
+		}
+
+		if err := s.markFailed(ctx, d, sub.IsActive, statusCode, err); err != nil {
+			return sent, err
+		}
+	}
+
+	return sent, nil
+}
+
+// leaseDuration returns how long the claimed batch is leased for,
+// long enough to send all its deliveries sequentially
+func (s *service) leaseDuration() time.Duration {
+	return time.Duration(s.batchSize+1) * s.client.Timeout
+}
+
+// send posts the delivery payload to the subscription url, returns the response status code.
+// Any non-2xx response is a failure.
+func (s *service) send(ctx context.Context, sub webhook_repository.WebhookSubscription, d webhook_repository.WebhookOutbox) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDeliveryID, d.ID.String())
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // nolint:errcheck
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// markFailed schedules the next attempt of the failed delivery,
+// or moves it to the dead-letter state if the retry budget is spent or retrying makes no sense
+func (s *service) markFailed(ctx context.Context, d webhook_repository.WebhookOutbox, retryable bool, statusCode int, deliveryErr error) error {
+	attempts := int(d.Attempts) + 1
+	status := DeliveryStatusPending
+	if !retryable || attempts >= s.retry.MaxAttempts {
+		status = DeliveryStatusDead
+	}
+
+	if err := s.repo.MarkDeliveryFailed(ctx, webhook_repository.MarkDeliveryFailedParams{
+		ID:             d.ID,
+		Status:         status,
+		NextAttemptAt:  time.Now().UTC().Add(s.retry.retryDelay(attempts)),
+		LastStatusCode: sql.NullInt32{Int32: int32(statusCode), Valid: statusCode > 0},
+		LastError:      sql.NullString{String: truncate(deliveryErr.Error(), maxErrorLength), Valid: true},
+	}); err != nil {
+		return fmt.Errorf("failed to mark delivery as failed: %w", err)
+	}
+
+	return nil
+}
+
+// truncate cuts the string to the given max length in bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// isWalletEvent reports whether the name is one of the wallet service events
+func isWalletEvent(name string) bool {
+	for _, e := range events.WalletEvents {
+		if string(e) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// cast repository subscription model to the public subscription representation, without the secret
+func castSubscription(sub webhook_repository.WebhookSubscription) Subscription {
+	return Subscription{
+		ID:        sub.ID.String(),
+		URL:       sub.URL,
+		Events:    sub.Events,
+		IsActive:  sub.IsActive,
+		CreatedAt: sub.CreatedAt,
+	}
+}
+
+// cast repository outbox model to the public delivery representation
+func castDelivery(d webhook_repository.WebhookOutbox) Delivery {
+	result := Delivery{
+		ID:             d.ID.String(),
+		SubscriptionID: d.SubscriptionID.String(),
+		Event:          d.Event,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       int(d.Attempts),
+		LastStatusCode: int(d.LastStatusCode.Int32),
+		LastError:      d.LastError.String,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Status == DeliveryStatusPending {
+		result.NextAttemptAt = utils.Pointer(d.NextAttemptAt)
+	}
+	if d.DeliveredAt.Valid {
+		result.DeliveredAt = utils.Pointer(d.DeliveredAt.Time)
+	}
+	return result
+}
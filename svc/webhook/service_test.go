@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	webhook_repository "github.com/dmitrymomot/solana-wallets/svc/webhook/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepository records the delivery updates, the other repository methods are not implemented
+type fakeRepository struct {
+	webhookRepository
+	claimed []webhook_repository.ClaimDueDeliveriesParams
+	failed  []webhook_repository.MarkDeliveryFailedParams
+}
+
+func (r *fakeRepository) ClaimDueDeliveries(ctx context.Context, arg webhook_repository.ClaimDueDeliveriesParams) ([]webhook_repository.WebhookOutbox, error) {
+	r.claimed = append(r.claimed, arg)
+	return nil, nil
+}
+
+func (r *fakeRepository) MarkDeliveryFailed(ctx context.Context, arg webhook_repository.MarkDeliveryFailedParams) error {
+	r.failed = append(r.failed, arg)
+	return nil
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := RetryPolicy{
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    5 * time.Minute,
+		MaxAttempts: 10,
+	}
+
+	tests := []struct {
+		attempts int
+		delay    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{5, 5 * time.Minute}, // capped
+		{50, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.delay, p.retryDelay(tt.attempts), "attempts: %d", tt.attempts)
+	}
+
+	t.Run("base delay above the cap", func(t *testing.T) {
+		p := RetryPolicy{BaseDelay: time.Hour, MaxDelay: time.Minute}
+		require.Equal(t, time.Minute, p.retryDelay(1))
+	})
+}
+
+func TestMarkFailed(t *testing.T) {
+	repo := &fakeRepository{}
+	s := NewService(repo, WithRetryPolicy(RetryPolicy{
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Minute,
+		MaxAttempts: 3,
+	})).(*service)
+	d := webhook_repository.WebhookOutbox{ID: uuid.New(), Status: DeliveryStatusPending}
+	deliveryErr := errors.New("unexpected response status: 500 Internal Server Error")
+
+	// attempts 1 and 2 are retried, the 3rd one spends the retry budget
+	for attempts, status := range []string{DeliveryStatusPending, DeliveryStatusPending, DeliveryStatusDead} {
+		d.Attempts = int32(attempts)
+		require.NoError(t, s.markFailed(context.Background(), d, true, 500, deliveryErr))
+
+		update := repo.failed[len(repo.failed)-1]
+		require.Equal(t, d.ID, update.ID)
+		require.Equal(t, status, update.Status, "attempt %d", attempts+1)
+		require.Equal(t, int32(500), update.LastStatusCode.Int32)
+		require.Equal(t, deliveryErr.Error(), update.LastError.String)
+	}
+
+	t.Run("not retryable", func(t *testing.T) {
+		d.Attempts = 0
+		require.NoError(t, s.markFailed(context.Background(), d, false, 0, errors.New("subscription is inactive")))
+
+		update := repo.failed[len(repo.failed)-1]
+		require.Equal(t, DeliveryStatusDead, update.Status)
+		require.False(t, update.LastStatusCode.Valid)
+	})
+}
+
+func TestDispatchLease(t *testing.T) {
+	repo := &fakeRepository{}
+	s := NewService(repo, WithBatchSize(20), WithTimeout(5*time.Second))
+
+	start := time.Now().UTC()
+	_, err := s.DispatchDueDeliveries(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, repo.claimed, 1)
+	require.Equal(t, int32(20), repo.claimed[0].Limit)
+	// every delivery of the batch may take the full timeout
+	require.False(t, repo.claimed[0].LeaseUntil.Before(start.Add(20*5*time.Second)))
+}
@@ -0,0 +1,30 @@
+package webhook_test
+
+import (
+	"testing"
+
+	"github.com/dmitrymomot/solana-wallets/svc/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := "secret"
+	timestamp := int64(1683000000)
+	body := []byte(`{"event":"wallet.created"}`)
+
+	signature := webhook.Sign(secret, timestamp, body)
+	require.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+	require.True(t, webhook.Verify(secret, timestamp, body, signature))
+
+	t.Run("tampered body", func(t *testing.T) {
+		require.False(t, webhook.Verify(secret, timestamp, []byte(`{"event":"wallet.deleted"}`), signature))
+	})
+
+	t.Run("wrong timestamp", func(t *testing.T) {
+		require.False(t, webhook.Verify(secret, timestamp+1, body, signature))
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		require.False(t, webhook.Verify("another secret", timestamp, body, signature))
+	})
+}
@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dmitrymomot/solana-wallets/internal/httpencoder"
+	"github.com/go-chi/chi/v5"
+	jwtkit "github.com/go-kit/kit/auth/jwt"
+	"github.com/go-kit/kit/transport"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/pkg/errors"
+)
+
+type (
+	logger interface {
+		Log(keyvals ...interface{}) error
+	}
+)
+
+// MakeHTTPHandler ...
+func MakeHTTPHandler(e Endpoints, log logger) http.Handler {
+	r := chi.NewRouter()
+
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorHandler(transport.NewLogErrorHandler(log)),
+		httptransport.ServerErrorEncoder(httpencoder.EncodeError(log, codeAndMessageFrom)),
+		httptransport.ServerBefore(jwtkit.HTTPToContext()),
+	}
+
+	r.Post("/subscriptions", httptransport.NewServer(
+		e.CreateSubscription,
+		decodeCreateSubscriptionRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Get("/subscriptions", httptransport.NewServer(
+		e.ListSubscriptions,
+		decodeEmptyRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Delete("/subscriptions/{id}", httptransport.NewServer(
+		e.DeleteSubscription,
+		decodeDeleteSubscriptionRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Get("/deliveries", httptransport.NewServer(
+		e.ListDeliveries,
+		decodeListDeliveriesRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	r.Post("/deliveries/{id}/redeliver", httptransport.NewServer(
+		e.Redeliver,
+		decodeRedeliverRequest,
+		httpencoder.EncodeResponse,
+		options...,
+	).ServeHTTP)
+
+	return r
+}
+
+// returns http error code by error type
+func codeAndMessageFrom(err error) (int, interface{}) {
+	if errors.Is(err, ErrInvalidParameter) ||
+		errors.Is(err, ErrUnknownEvent) {
+		return http.StatusBadRequest, err.Error()
+	}
+	if errors.Is(err, ErrNotFound) {
+		return http.StatusNotFound, err.Error()
+	}
+	if errors.Is(err, ErrForbidden) {
+		return http.StatusForbidden, err.Error()
+	}
+	if errors.Is(err, ErrUnauthorized) {
+		return http.StatusUnauthorized, err.Error()
+	}
+
+	return httpencoder.CodeAndMessageFrom(err)
+}
+
+func decodeEmptyRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return nil, nil
+}
+
+func decodeCreateSubscriptionRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req CreateSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	return req, nil
+}
+
+func decodeDeleteSubscriptionRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return DeleteSubscriptionRequest{ID: chi.URLParam(r, "id")}, nil
+}
+
+func decodeListDeliveriesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	req := ListDeliveriesRequest{
+		Status:         q.Get("status"),
+		SubscriptionID: q.Get("subscription_id"),
+	}
+
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if req.Limit, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("%w: limit must be a number", ErrInvalidParameter)
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if req.Offset, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("%w: offset must be a number", ErrInvalidParameter)
+		}
+	}
+
+	return req, nil
+}
+
+func decodeRedeliverRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return RedeliverRequest{ID: chi.URLParam(r, "id")}, nil
+}